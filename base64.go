@@ -0,0 +1,38 @@
+package jsonrepair
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+)
+
+// regexBase64 matches text consisting of base64 characters of the standard or URL-safe
+// alphabet only, with optional padding.
+var regexBase64 = regexp.MustCompile(`^[A-Za-z0-9+/_-]+={0,2}$`)
+
+// decodeBase64 decodes text which is base64 encoded as a whole, like eyJhIjoxfQ==, when the
+// decoded text looks like JSON: it starts with an opening brace or bracket. Line breaks, like
+// in MIME encoded text, are ignored. Both the standard and the URL-safe alphabet are accepted,
+// with or without padding. It returns whether the text was decoded.
+func decodeBase64(text string) (string, bool) {
+	encoded := strings.Join(strings.Fields(text), "")
+	if !regexBase64.MatchString(encoded) {
+		return text, false
+	}
+
+	encoded = strings.TrimRight(encoded, "=")
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(encoded, "-_") {
+		encoding = base64.RawURLEncoding
+	}
+	decoded, err := encoding.DecodeString(encoded)
+	if err != nil {
+		return text, false
+	}
+
+	trimmed := strings.TrimLeft(string(decoded), " \t\n\r")
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return text, false
+	}
+	return string(decoded), true
+}
@@ -0,0 +1,48 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		decoded  bool
+	}{
+		{"object", "eyJhIjoxfQ==", `{"a":1}`, true},
+		{"without padding", "eyJhIjoxfQ", `{"a":1}`, true},
+		{"standard alphabet", "eyJxIjoiPz8/Pj4+In0=", `{"q":"???>>>"}`, true},
+		{"url-safe alphabet", "eyJxIjoiPz8_Pj4-In0", `{"q":"???>>>"}`, true},
+		{"line breaks", "  eyJxIjoi\nPz8/Pj4+\r\nIn0=\n", `{"q":"???>>>"}`, true},
+		{"truncated array", "WzEsMg==", "[1,2", true},
+		{"not json", "aGVsbG8=", "aGVsbG8=", false},
+		{"word", "hello", "hello", false},
+		{"json", `{"a":1}`, `{"a":1}`, false},
+		{"invalid length", "eyJhIjoxfQ=x", "eyJhIjoxfQ=x", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, decoded := decodeBase64(test.text)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.decoded, decoded)
+		})
+	}
+}
+
+func TestBase64Decoding(t *testing.T) {
+	var report Report
+	result, err := JSONRepair("e2E6MSwgYjonPyd9", WithBase64Decoding(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1, "b":"?"}`, result)
+	assert.True(t, report.Base64Decoded)
+
+	result, err = JSONRepair("eyJhIjoxfQ==")
+	require.NoError(t, err)
+	assert.Equal(t, `"eyJhIjoxfQ=="`, result)
+}
@@ -0,0 +1,180 @@
+package jsonrepair
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// charsetWindows1252 is the name reported for input transcoded from Windows-1252.
+const charsetWindows1252 = "windows-1252"
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 to their Unicode code points.
+// The remaining bytes 0xA0-0xFF are equal to their Latin-1 code points.
+var windows1252 = [32]rune{
+	0x20ac, 0x81, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021, // 0x80-0x87
+	0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0x8d, 0x017d, 0x8f, // 0x88-0x8f
+	0x90, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014, // 0x90-0x97
+	0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0x9d, 0x017e, 0x0178, // 0x98-0x9f
+}
+
+// byteOrderMarks are the byte order marks by the name of their encoding. The UTF-32LE mark
+// starts with the UTF-16LE mark, so it must be checked first.
+var byteOrderMarks = []struct {
+	encoding string
+	mark     string
+}{
+	{"utf-8", "\xef\xbb\xbf"},
+	{"utf-32le", "\xff\xfe\x00\x00"},
+	{"utf-32be", "\x00\x00\xfe\xff"},
+	{"utf-16le", "\xff\xfe"},
+	{"utf-16be", "\xfe\xff"},
+}
+
+// splitByteOrderMark removes a UTF-8, UTF-16 or UTF-32 byte order mark from the start of the
+// text, and returns the name of its encoding, or an empty string when there is none.
+func splitByteOrderMark(text string) (string, string) {
+	for _, bom := range byteOrderMarks {
+		if strings.HasPrefix(text, bom.mark) {
+			return text[len(bom.mark):], bom.encoding
+		}
+	}
+	return text, ""
+}
+
+// unicodeEncodings are the encodings which can be set with WithEncoding.
+var unicodeEncodings = []string{"utf-8", "utf-16le", "utf-16be", "utf-32le", "utf-32be"}
+
+// detectUnicodeEncoding detects UTF-16 and UTF-32 text without a byte order mark by the zero
+// bytes of its first character, which is ASCII in a JSON document, like RFC 4627 describes.
+// It returns an empty string for other text.
+func detectUnicodeEncoding(text string) string {
+	if len(text) < 4 {
+		return ""
+	}
+	switch {
+	case text[0] == 0 && text[1] == 0 && text[2] == 0 && text[3] != 0:
+		return "utf-32be"
+	case text[0] != 0 && text[1] == 0 && text[2] == 0 && text[3] == 0:
+		return "utf-32le"
+	case text[0] == 0 && text[1] != 0 && text[2] == 0 && text[3] != 0:
+		return "utf-16be"
+	case text[0] != 0 && text[1] == 0 && text[2] != 0 && text[3] == 0:
+		return "utf-16le"
+	}
+	return ""
+}
+
+// transcodeUnicode transcodes UTF-16 or UTF-32 text in the given encoding to UTF-8. Code units
+// which are not valid, like a lone surrogate or an incomplete unit at the end, are replaced
+// with U+FFFD. Text in another encoding is returned as is.
+func transcodeUnicode(text, encoding string) string {
+	var order binary.ByteOrder = binary.LittleEndian
+	if strings.HasSuffix(encoding, "be") {
+		order = binary.BigEndian
+	}
+
+	var output strings.Builder
+	data := []byte(text)
+	switch encoding {
+	case "utf-16le", "utf-16be":
+		units := make([]uint16, 0, len(data)/2)
+		for ; len(data) >= 2; data = data[2:] {
+			units = append(units, order.Uint16(data))
+		}
+		for _, char := range utf16.Decode(units) {
+			output.WriteRune(char)
+		}
+	case "utf-32le", "utf-32be":
+		for ; len(data) >= 4; data = data[4:] {
+			// WriteRune writes U+FFFD for code points which are not valid
+			output.WriteRune(rune(order.Uint32(data)))
+		}
+	default:
+		return text
+	}
+	if len(data) > 0 {
+		output.WriteRune(utf8.RuneError)
+	}
+	return output.String()
+}
+
+// detectCharset transcodes text which is not valid UTF-8 from Windows-1252 (a superset
+// of Latin-1) to UTF-8. Valid UTF-8 sequences are kept as is, so mixed input where only
+// a few characters like smart quotes are Windows-1252 encoded is repaired too.
+// It returns the name of the detected charset, or an empty string when the text is valid UTF-8.
+func detectCharset(text string) (string, string) {
+	if utf8.ValidString(text) {
+		return text, ""
+	}
+
+	var output strings.Builder
+	output.Grow(len(text))
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		if r == utf8.RuneError && size <= 1 {
+			output.WriteRune(decodeWindows1252(text[0]))
+			text = text[1:]
+			continue
+		}
+		output.WriteString(text[:size])
+		text = text[size:]
+	}
+	return output.String(), charsetWindows1252
+}
+
+// decodeWindows1252 returns the Unicode code point of a Windows-1252 byte.
+func decodeWindows1252(b byte) rune {
+	if b >= 0x80 && b <= 0x9f {
+		return windows1252[b-0x80]
+	}
+	return rune(b)
+}
+
+// InvalidUTF8Policy defines how bytes of the input which are not valid UTF-8 are repaired,
+// when the input is not transcoded with WithCharsetDetection.
+type InvalidUTF8Policy int
+
+const (
+	// InvalidUTF8Replace replaces every invalid byte with the replacement character U+FFFD.
+	InvalidUTF8Replace InvalidUTF8Policy = iota
+
+	// InvalidUTF8Strip removes the invalid bytes.
+	InvalidUTF8Strip
+
+	// InvalidUTF8Error fails the repair with ErrInvalidUTF8 at the first invalid byte.
+	InvalidUTF8Error
+)
+
+// repairInvalidUTF8 repairs the bytes of the text which are not valid UTF-8 with the policy,
+// and returns the number of invalid bytes. With InvalidUTF8Error it returns an error at the
+// first invalid byte instead.
+func repairInvalidUTF8(text string, policy InvalidUTF8Policy) (string, int, error) {
+	if utf8.ValidString(text) {
+		return text, 0, nil
+	}
+
+	var output strings.Builder
+	output.Grow(len(text))
+	invalid := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r != utf8.RuneError || size > 1 {
+			output.WriteString(text[i : i+size])
+			i += size
+			continue
+		}
+		if policy == InvalidUTF8Error {
+			// the text before the first invalid byte is valid, so the offsets are exact
+			return "", 0, newError(ErrInvalidUTF8, fmt.Sprintf("0x%02x", text[i]), utf8.RuneCountInString(text[:i]))
+		}
+		if policy == InvalidUTF8Replace {
+			output.WriteRune(utf8.RuneError)
+		}
+		invalid++
+		i++
+	}
+	return output.String(), invalid, nil
+}
@@ -0,0 +1,144 @@
+package jsonrepair
+
+import (
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		charset  string
+	}{
+		{"valid utf-8", "{\"a\":\"★\"}", "{\"a\":\"★\"}", ""},
+		{"smart quotes", "{\x93a\x94:\x93b\x94}", "{“a”:“b”}", charsetWindows1252},
+		{"latin-1", "\"caf\xe9\"", "\"café\"", charsetWindows1252},
+		{"mixed", "\"★ \x80 caf\xc3\xa9\"", "\"★ € café\"", charsetWindows1252},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, charset := detectCharset(test.text)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.charset, charset)
+		})
+	}
+}
+
+func TestInvalidUTF8Policy(t *testing.T) {
+	text := "{\"a\": \"caf\xe9\", \"★\": \"\xff\xfe\"}"
+	tests := []struct {
+		name     string
+		policy   InvalidUTF8Policy
+		expected string
+	}{
+		{"replace", InvalidUTF8Replace, "{\"a\": \"caf�\", \"★\": \"��\"}"},
+		{"strip", InvalidUTF8Strip, "{\"a\": \"caf\", \"★\": \"\"}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var report Report
+			result, err := JSONRepair(text, WithInvalidUTF8Policy(test.policy), WithReport(&report))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, 3, report.InvalidUTF8)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := JSONRepair("{\"★\": \"caf\xe9\"}", WithInvalidUTF8Policy(InvalidUTF8Error))
+		require.ErrorIs(t, err, ErrInvalidUTF8)
+		var repairErr *Error
+		require.ErrorAs(t, err, &repairErr)
+		assert.Equal(t, 10, repairErr.Position)
+		assert.Equal(t, 12, repairErr.ByteOffset)
+		assert.Equal(t, "invalid utf-8: 0xe9 at position 10", err.Error())
+	})
+
+	t.Run("charset detection", func(t *testing.T) {
+		result, err := JSONRepair("\"caf\xe9\"", WithInvalidUTF8Policy(InvalidUTF8Error), WithCharsetDetection())
+		require.NoError(t, err)
+		assert.Equal(t, "\"café\"", result)
+	})
+}
+
+func TestByteOrderMark(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		encoding string
+	}{
+		{"utf-8", "\xef\xbb\xbf{\"a\": 1}", `{"a": 1}`, "utf-8"},
+		{"utf-16le", "\xff\xfe[\x001\x00]\x00", `[1]`, "utf-16le"},
+		{"utf-16be", "\xfe\xff\x00[\x001\x00]", `[1]`, "utf-16be"},
+		{"utf-32le", "\xff\xfe\x00\x00[\x00\x00\x00]\x00\x00\x00", `[]`, "utf-32le"},
+		{"utf-32be", "\x00\x00\xfe\xff\x00\x00\x00[\x00\x00\x00]", `[]`, "utf-32be"},
+		{"none", `[1]`, `[1]`, ""},
+		{"stray", "[1,\ufeff2,\ufeff {\ufeffa: 3}]", `[1,2, {"a": 3}]`, ""},
+		{"in string", "[\"a\ufeffb\"]", "[\"a\ufeffb\"]", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var report Report
+			result, err := JSONRepair(test.text, WithReport(&report))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.encoding, report.ByteOrderMark)
+		})
+	}
+
+	t.Run("only", func(t *testing.T) {
+		for _, text := range []string{"\ufeff", "\ufeff \n", "\xff\xfe"} {
+			_, err := JSONRepair(text)
+			require.ErrorIs(t, err, ErrNoJSONValue, text)
+		}
+	})
+}
+
+func TestUnicodeEncodings(t *testing.T) {
+	utf16le := func(text string) string {
+		var encoded []byte
+		for _, unit := range utf16.Encode([]rune(text)) {
+			encoded = binary.LittleEndian.AppendUint16(encoded, unit)
+		}
+		return string(encoded)
+	}
+	utf32be := func(text string) string {
+		var encoded []byte
+		for _, char := range text {
+			encoded = binary.BigEndian.AppendUint32(encoded, uint32(char))
+		}
+		return string(encoded)
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		opts     []Option
+		expected string
+		charset  string
+	}{
+		{"utf-16le with bom", "\xff\xfe" + utf16le("{a: '😀 ★'}"), nil, `{"a": "😀 ★"}`, "utf-16le"},
+		{"utf-16le without bom", utf16le("{a: 1}\r\n"), nil, "{\"a\": 1}\r\n", "utf-16le"},
+		{"utf-32be without bom", utf32be(`["é"]`), nil, `["é"]`, "utf-32be"},
+		{"incomplete unit", utf16le(`["a`) + "\x00", nil, "[\"a\ufffd\"]", "utf-16le"},
+		{"explicit encoding", utf16le("1"), []Option{WithEncoding("utf-16le")}, `1`, "utf-16le"},
+		{"explicit utf-8", "[1]", []Option{WithEncoding("utf-8")}, `[1]`, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var report Report
+			result, err := JSONRepair(test.text, append(test.opts, WithReport(&report))...)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.charset, report.Charset)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Command jsonrepair repairs invalid JSON documents.
+//
+// Usage:
+//
+//	jsonrepair [-batch] [file]
+//
+// The document is read from the file, or from stdin when no file is given, and the
+// repaired document is written to stdout.
+//
+// In batch mode every line of the input is repaired independently, and for every line an
+// NDJSON record with the result is written: {"ok":true,"out":...} for a repaired line and
+// {"ok":false,"error":"...","line":N} for a line which could not be repaired. Blank lines
+// are skipped. A bad line does not stop the stream.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kaptinlin/jsonrepair"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments and streams, and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("jsonrepair", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	batch := flags.Bool("batch", false, "repair every line independently and write an NDJSON record with the result per line")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	input := stdin
+	if flags.NArg() > 0 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var err error
+	if *batch {
+		err = repairBatch(input, stdout)
+	} else {
+		err = repairDocument(input, stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// repairDocument repairs the whole input as a single document.
+func repairDocument(input io.Reader, output io.Writer) error {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	repaired, err := jsonrepair.JSONRepair(string(data))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(output, repaired)
+	return err
+}
+
+// record is the result of repairing a single line in batch mode.
+type record struct {
+	OK    bool            `json:"ok"`
+	Out   json.RawMessage `json:"out,omitempty"`
+	Error string          `json:"error,omitempty"`
+	Line  int             `json:"line,omitempty"`
+}
+
+// errInvalidOutput is reported for a line whose repaired output is not valid JSON.
+var errInvalidOutput = errors.New("repaired output is not valid JSON")
+
+// repairBatch repairs every line of the input independently and writes an NDJSON record with
+// the result per line. Only errors reading the input or writing the output are returned.
+func repairBatch(input io.Reader, output io.Writer) error {
+	reader := bufio.NewReader(input)
+	writer := bufio.NewWriter(output)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	for line := 1; ; line++ {
+		text, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return readErr
+		}
+		if strings.TrimSpace(text) != "" {
+			if err := encoder.Encode(repairLine(text, line)); err != nil {
+				return err
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	return writer.Flush()
+}
+
+// repairLine repairs a single line and returns the record with the result.
+func repairLine(text string, line int) record {
+	repaired, err := jsonrepair.JSONRepair(text)
+	if err != nil {
+		return record{Error: err.Error(), Line: line}
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(repaired)); err != nil {
+		return record{Error: errInvalidOutput.Error(), Line: line}
+	}
+	return record{OK: true, Out: compact.Bytes()}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDocument(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(nil, strings.NewReader("{name: 'John'}"), &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "{\"name\": \"John\"}\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunDocumentFromFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "broken.json")
+	require.NoError(t, os.WriteFile(name, []byte("[1, 2"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{name}, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "[1, 2]\n", stdout.String())
+}
+
+func TestRunDocumentFailure(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(nil, strings.NewReader(`{"a": 1}}}x`), &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, "unexpected character: 'x' at position 10\n", stderr.String())
+}
+
+func TestRunBatch(t *testing.T) {
+	input := strings.Join([]string{
+		`{name: 'John'}`,
+		`[1, 2`,
+		``,
+		`{"a": 1}}}x`,
+		`"text`,
+	}, "\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-batch"}, strings.NewReader(input), &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, strings.Join([]string{
+		`{"ok":true,"out":{"name":"John"}}`,
+		`{"ok":true,"out":[1,2]}`,
+		`{"ok":false,"error":"unexpected character: 'x' at position 10","line":4}`,
+		`{"ok":true,"out":"text"}`,
+	}, "\n")+"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunInvalidFlag(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-unknown"}, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "flag provided but not defined: -unknown")
+}
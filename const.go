@@ -17,7 +17,12 @@ const (
 	codeReturn                  = 0xd  // "\r"
 	codeBackspace               = 0x08 // "\b"
 	codeFormFeed                = 0x0c // "\f"
+	codeExclamationMark         = 0x21 // "!"
 	codeDoubleQuote             = 0x22 // "
+	codeHash                    = 0x23 // "#"
+	codeDollar                  = 0x24 // "$"
+	codePercent                 = 0x25 // "%"
+	codeAmpersand               = 0x26 // "&"
 	codePlus                    = 0x2b // "+"
 	codeMinus                   = 0x2d // "-"
 	codeQuote                   = 0x27 // "'"
@@ -27,6 +32,10 @@ const (
 	codeDot                     = 0x2e // "." (dot, period)
 	codeColon                   = 0x3a // ":"
 	codeSemicolon               = 0x3b // ";"
+	codeLessThan                = 0x3c // "<"
+	codeEqual                   = 0x3d // "="
+	codeGreaterThan             = 0x3e // ">"
+	codeUnderscore              = 0x5f // "_"
 	codeUppercaseA              = 0x41 // "A"
 	codeLowercaseA              = 0x61 // "a"
 	codeUppercaseE              = 0x45 // "E"
@@ -45,6 +54,8 @@ const (
 	codeQuoteRight              = 0x2019 // ’
 	codeGraveAccent             = 0x60   // `
 	codeAcuteAccent             = 0xb4   // ´
+	codeReplacementCharacter    = 0xfffd // �
+	codeByteOrderMark           = 0xfeff
 )
 
 // Define control and escape character mappings
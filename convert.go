@@ -0,0 +1,53 @@
+package jsonrepair
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// repairEmbedded repairs a value embedded in another document, like the content of a string
+// or a value of a query string, with the same options. Its repairs are neither reported nor
+// annotated, since their positions are in the embedded text. It returns false when the text
+// is not a single value which can be repaired.
+func repairEmbedded(text string, opts *options) (string, bool) {
+	embedded := *opts
+	embedded.report, embedded.annotate, embedded.offset, embedded.err = nil, false, 0, nil
+	c := newCursor([]rune(text))
+	var output strings.Builder
+	if !parseValue(c, &output, &embedded) || !c.done() || embedded.err != nil {
+		return "", false
+	}
+	return strings.TrimRightFunc(output.String(), isWhitespace), true
+}
+
+// inferValue types a value of a converted format, like a query string or a properties file, by
+// the repair: a JSON value, or a value which looks like an object, array or quoted string, is
+// repaired with the options, other values are strings. So 1, true and null are kept, {a:1}
+// becomes an object, and a value like 007 or 2024-01-01 is kept as a string.
+func inferValue(text string, opts *options) any {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || !strings.ContainsRune(`{["'`, rune(trimmed[0])) && !json.Valid([]byte(trimmed)) {
+		return text
+	}
+	repaired, ok := repairEmbedded(trimmed, opts)
+	if !ok {
+		return text
+	}
+	decoder := json.NewDecoder(strings.NewReader(repaired))
+	decoder.UseNumber()
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return text
+	}
+	return value
+}
+
+// encodeValue returns a value built by a converter, like an *orderedObject, as compact JSON.
+func encodeValue(value any) (string, error) {
+	var output bytes.Buffer
+	if err := encodeOrdered(&output, value); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
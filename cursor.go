@@ -0,0 +1,74 @@
+package jsonrepair
+
+import "unicode/utf8"
+
+// cursor is a position in the text being repaired. The parser reads the text through the
+// cursor only: a read outside of the text returns 0, and moving forward stops at the end
+// of the text, so an index out of range can not happen.
+type cursor struct {
+	text []rune
+	pos  int
+}
+
+// newCursor returns a cursor at the start of the text.
+func newCursor(text []rune) *cursor {
+	return &cursor{text: text}
+}
+
+// peek returns the rune n positions after the current position, or 0 when that is outside of the text.
+// A negative n looks back.
+func (c *cursor) peek(n int) rune {
+	return c.at(c.pos + n)
+}
+
+// at returns the rune at the given position, or 0 when the position is outside of the text.
+func (c *cursor) at(pos int) rune {
+	if pos < 0 || pos >= len(c.text) {
+		return 0
+	}
+	return c.text[pos]
+}
+
+// next moves the cursor to the next rune, and returns the rune it was at.
+func (c *cursor) next() rune {
+	char := c.peek(0)
+	c.skip(1)
+	return char
+}
+
+// skip moves the cursor n runes forward, up to the end of the text.
+func (c *cursor) skip(n int) {
+	c.pos = min(c.pos+n, len(c.text))
+}
+
+// remaining returns the number of runes from the current position up to the end of the text.
+func (c *cursor) remaining() int {
+	return max(len(c.text)-c.pos, 0)
+}
+
+// done checks if the cursor is at the end of the text.
+func (c *cursor) done() bool {
+	return c.pos >= len(c.text)
+}
+
+// len returns the length of the text in runes.
+func (c *cursor) len() int {
+	return len(c.text)
+}
+
+// slice returns the text between the given positions, clamped to the text.
+func (c *cursor) slice(from, to int) []rune {
+	from = min(max(from, 0), len(c.text))
+	to = min(max(to, from), len(c.text))
+	return c.text[from:to]
+}
+
+// rest returns the text from the current position up to the end.
+func (c *cursor) rest() []rune {
+	return c.slice(c.pos, len(c.text))
+}
+
+// hasPrefix checks if the text at the current position starts with the given prefix.
+func (c *cursor) hasPrefix(prefix string) bool {
+	return string(c.slice(c.pos, c.pos+utf8.RuneCountInString(prefix))) == prefix
+}
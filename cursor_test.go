@@ -0,0 +1,37 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	c := newCursor([]rune("ab€"))
+	assert.Equal(t, 'a', c.peek(0))
+	assert.Equal(t, '€', c.peek(2))
+	assert.Equal(t, rune(0), c.peek(3))
+	assert.Equal(t, rune(0), c.peek(-1))
+	assert.Equal(t, 3, c.remaining())
+	assert.True(t, c.hasPrefix("ab"))
+	assert.False(t, c.hasPrefix("ab€d"))
+
+	assert.Equal(t, 'a', c.next())
+	assert.Equal(t, 'a', c.peek(-1))
+	assert.Equal(t, "b€", string(c.rest()))
+
+	c.skip(10)
+	assert.True(t, c.done())
+	assert.Equal(t, 3, c.pos)
+	assert.Equal(t, 0, c.remaining())
+	assert.Equal(t, rune(0), c.next())
+	assert.Equal(t, 3, c.pos)
+}
+
+func TestCursorSlice(t *testing.T) {
+	c := newCursor([]rune("abc"))
+	assert.Equal(t, "bc", string(c.slice(1, 3)))
+	assert.Equal(t, "abc", string(c.slice(-2, 10)))
+	assert.Empty(t, string(c.slice(2, 1)))
+	assert.Empty(t, string(c.slice(5, 8)))
+}
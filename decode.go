@@ -0,0 +1,210 @@
+package jsonrepair
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RepairInto repairs the text and decodes it into a value of type T with encoding/json. The
+// repair is guided by the type like by WithSchema: a value is coerced to the type of its field,
+// like "42" to 42 for an int field, and a missing value of a string field becomes "". Fields are
+// matched by their json tags like encoding/json does. A WithSchema or WithTypes option replaces
+// the schema of the type.
+func RepairInto[T any](text string, opts ...Option) (T, error) {
+	var value T
+	schema := typeSchemaOf(reflect.TypeOf(&value).Elem(), map[reflect.Type]*schemaNode{})
+	repaired, err := JSONRepair(text, append([]Option{withSchemaNode(schema)}, opts...)...)
+	if err != nil {
+		return value, err
+	}
+	err = json.Unmarshal([]byte(repaired), &value)
+	return value, err
+}
+
+// RepairToAny repairs the text and decodes it into a Go value like encoding/json decodes into
+// an any: a map[string]any for an object, a []any for an array, a string, a bool or nil. Numbers
+// are decoded as json.Number, so they keep their precision.
+func RepairToAny(text string, opts ...Option) (any, error) {
+	var value any
+	err := repairToValue(text, &value, opts)
+	return value, err
+}
+
+// RepairToMap repairs the text and decodes it like RepairToAny into a map. It fails when the
+// repaired value is not an object.
+func RepairToMap(text string, opts ...Option) (map[string]any, error) {
+	var value map[string]any
+	err := repairToValue(text, &value, opts)
+	return value, err
+}
+
+// repairToValue repairs the text and decodes it into the value, with numbers as json.Number.
+func repairToValue(text string, value any, opts []Option) error {
+	repaired, err := JSONRepair(text, opts...)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(repaired))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+// RepairValid is like JSONRepair, but checks the repaired text with encoding/json before it is
+// returned, and fails with an error wrapping ErrInvalidOutput when the standard decoders would
+// not accept it, like after a TokenRaw token whose transform does not return valid JSON.
+func RepairValid(text string, opts ...Option) (string, error) {
+	repaired, err := JSONRepair(text, opts...)
+	if err != nil {
+		return "", err
+	}
+	if err := checkValid([]byte(repaired)); err != nil {
+		return "", err
+	}
+	return repaired, nil
+}
+
+// RepairToRawMessage is like RepairValid, but returns the repaired text as a json.RawMessage,
+// to be embedded in a value which is encoded or decoded with encoding/json.
+func RepairToRawMessage(text string, opts ...Option) (json.RawMessage, error) {
+	repaired, err := RepairValid(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(repaired), nil
+}
+
+// checkValid returns an error wrapping ErrInvalidOutput when the data is not valid JSON for
+// encoding/json.
+func checkValid(data []byte) error {
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	return nil
+}
+
+// withSchemaNode guides the repair with the schema.
+func withSchemaNode(schema *schemaNode) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+)
+
+// typeSchemaOf returns the schema of the values which encoding/json decodes into the type, or
+// nil for any value. The schemas of structs are kept in structs, so that a recursive type
+// refers to the schema which is being built.
+func typeSchemaOf(t reflect.Type, structs map[reflect.Type]*schemaNode) *schemaNode {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	pointer := reflect.PointerTo(t)
+	switch {
+	case t.Implements(jsonUnmarshalerType) || pointer.Implements(jsonUnmarshalerType):
+		return nil
+	case t.Implements(textUnmarshalerType) || pointer.Implements(textUnmarshalerType):
+		return newTypeSchema("string", nullable)
+	case t == jsonNumberType:
+		return newTypeSchema("number", nullable)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return newTypeSchema("boolean", nullable)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return newTypeSchema("integer", nullable)
+	case reflect.Float32, reflect.Float64:
+		return newTypeSchema("number", nullable)
+	case reflect.String:
+		return newTypeSchema("string", nullable)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// a []byte is decoded from a base64 string
+			return newTypeSchema("string", nullable)
+		}
+		node := newTypeSchema("array", nullable || t.Kind() == reflect.Slice)
+		node.items = typeSchemaOf(t.Elem(), structs)
+		return node
+	case reflect.Map:
+		node := newTypeSchema("object", true)
+		node.additional = typeSchemaOf(t.Elem(), structs)
+		return node
+	case reflect.Struct:
+		node, ok := structs[t]
+		if !ok {
+			node = newTypeSchema("object", false)
+			node.properties = make(map[string]*schemaNode)
+			node.foldCase = true
+			structs[t] = node
+			addFieldSchemas(node, t, structs, map[reflect.Type]bool{t: true})
+		}
+		if nullable {
+			// the nullable schema shares the properties, which are complete once built
+			nullableNode := *node
+			nullableNode.types = []string{"object", "null"}
+			return &nullableNode
+		}
+		return node
+	}
+	return nil
+}
+
+// newTypeSchema returns the schema of a type, which allows null too when nullable.
+func newTypeSchema(name string, nullable bool) *schemaNode {
+	if nullable {
+		return &schemaNode{types: []string{name, "null"}}
+	}
+	return &schemaNode{types: []string{name}}
+}
+
+// addFieldSchemas adds the schemas of the fields of the struct type to the properties of the
+// node, by their names in JSON, including the fields of embedded structs. The embedded types
+// are skipped when they embed each other.
+func addFieldSchemas(node *schemaNode, t reflect.Type, structs map[reflect.Type]*schemaNode, embedded map[reflect.Type]bool) {
+	for k := 0; k < t.NumField(); k++ {
+		field := t.Field(k)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				if !embedded[fieldType] {
+					embedded[fieldType] = true
+					addFieldSchemas(node, fieldType, structs, embedded)
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := typeSchemaOf(field.Type, structs)
+		if schema != nil && strings.Contains(","+flags+",", ",string,") &&
+			(schema.expects("integer") || schema.expects("number") || schema.expects("boolean")) {
+			// a field with the string option is encoded as a string, like "42"
+			schema = newTypeSchema("string", false)
+		}
+		if _, exists := node.properties[name]; !exists {
+			node.properties[name] = schema
+		}
+	}
+}
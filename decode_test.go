@@ -0,0 +1,133 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	Zip string `json:"zip"`
+}
+
+type testBase struct {
+	ID int `json:"id"`
+}
+
+type testPerson struct {
+	testBase
+	Name     string            `json:"name"`
+	Age      int               `json:"age"`
+	Score    float64           `json:"score,omitempty"`
+	Active   bool              `json:"active"`
+	Count    int               `json:"count,string"`
+	Nickname *string           `json:"nickname"`
+	Tags     []string          `json:"tags"`
+	Address  *testAddress      `json:"address"`
+	Labels   map[string]string `json:"labels"`
+	Born     time.Time         `json:"born"`
+	Friends  []testPerson      `json:"friends"`
+	Title    string
+	Ignored  int `json:"-"`
+}
+
+func TestRepairInto(t *testing.T) {
+	person, err := RepairInto[testPerson](`{id: "7", name: 'John', age: "42", score: '9.5', active: "true",
+		count: 3, nickname: , tags: [1, "b"], address: {zip: 1234}, labels: {a: 1},
+		born: "2024-01-02T00:00:00Z", friends: [{name: "Jane", age: "40"}], title: 5, Ignored: 1`)
+	require.NoError(t, err)
+	assert.Equal(t, testPerson{
+		testBase: testBase{ID: 7},
+		Name:     "John",
+		Age:      42,
+		Score:    9.5,
+		Active:   true,
+		Count:    3,
+		Tags:     []string{"1", "b"},
+		Address:  &testAddress{Zip: "1234"},
+		Labels:   map[string]string{"a": "1"},
+		Born:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Friends:  []testPerson{{Name: "Jane", Age: 40}},
+		Title:    "5",
+	}, person)
+
+	// a missing string value is empty
+	person, err = RepairInto[testPerson](`{"name": }`)
+	require.NoError(t, err)
+	assert.Equal(t, "", person.Name)
+
+	numbers, err := RepairInto[[]int](`["1", 2, '3'`)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, numbers)
+
+	_, err = RepairInto[testPerson](`{"age": "old"}`)
+	require.Error(t, err)
+	_, err = RepairInto[testPerson](`{"a": 1} x`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
+
+type testCycleA struct {
+	*testCycleB
+	A int `json:"a"`
+}
+
+type testCycleB struct {
+	*testCycleA
+	B int `json:"b"`
+}
+
+func TestRepairIntoEmbeddedCycle(t *testing.T) {
+	value, err := RepairInto[testCycleA](`{a: "1"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, value.A)
+}
+
+func TestRepairToAny(t *testing.T) {
+	value, err := RepairToAny(`{name: 'John', age: 42, big: 12345678901234567890, tags: ['a', null, true]`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name": "John",
+		"age":  json.Number("42"),
+		"big":  json.Number("12345678901234567890"),
+		"tags": []any{"a", nil, true},
+	}, value)
+
+	value, err = RepairToAny(`'text'`)
+	require.NoError(t, err)
+	assert.Equal(t, "text", value)
+
+	_, err = RepairToAny(`{"a": 1} x`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
+
+func TestRepairToMap(t *testing.T) {
+	value, err := RepairToMap(`{a: 1.5, b: {c: None}}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": json.Number("1.5"), "b": map[string]any{"c": nil}}, value)
+
+	_, err = RepairToMap(`[1, 2]`)
+	var typeErr *json.UnmarshalTypeError
+	require.ErrorAs(t, err, &typeErr)
+}
+
+func TestRepairToRawMessage(t *testing.T) {
+	raw, err := RepairToRawMessage(`{a: [1, 2,], b: 'x'`)
+	require.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"a": [1, 2], "b": "x"}`), raw)
+
+	// a raw token which is not valid JSON is caught before it is returned
+	broken := WithTokens(Token{Name: "broken", Pattern: regexp.MustCompile(`^broken`), Handling: TokenRaw})
+	_, err = JSONRepair(`{"a": broken}`, broken)
+	require.NoError(t, err)
+	_, err = RepairToRawMessage(`{"a": broken}`, broken)
+	require.ErrorIs(t, err, ErrInvalidOutput)
+	_, err = RepairValid(`{"a": broken}`, broken)
+	require.ErrorIs(t, err, ErrInvalidOutput)
+
+	_, err = RepairToRawMessage(`{"a": 1} x`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
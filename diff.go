@@ -0,0 +1,258 @@
+package jsonrepair
+
+import "unicode/utf8"
+
+// EditKind is the kind of an Edit.
+type EditKind int
+
+const (
+	// EditDelete deletes text from the input.
+	EditDelete EditKind = iota
+
+	// EditInsert inserts text into the input.
+	EditInsert
+)
+
+// Edit is an edit which the repair applied to the input, returned by Diff.
+type Edit struct {
+	// Kind tells whether Text is deleted or inserted.
+	Kind EditKind
+
+	// Position is the offset in the input in runes where Text is deleted, or inserted.
+	Position int
+
+	// ByteOffset is the offset in the input in bytes, like Position.
+	ByteOffset int
+
+	// Text is the deleted or inserted text.
+	Text string
+}
+
+// Diff repairs the text like JSONRepair, and returns the edits which turn the text into the
+// repaired output, ordered by position, for example to show a diff for review. An edit which
+// replaces text is a deletion followed by an insertion at the same position. The edits are
+// minimal, except that a part of the text which differs too much from the output, like a
+// transcoded or reformatted text, is replaced as a whole.
+func Diff(text string, opts ...Option) ([]Edit, error) {
+	repaired, err := JSONRepair(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return diffTexts(text, repaired), nil
+}
+
+// diffTexts returns the edits which turn a into b. The lines which differ are found first, and
+// then the edits within them, which is much faster for texts with many lines. Lines which are
+// replaced with as many lines, like by most repairs, are compared line by line.
+func diffTexts(a, b string) []Edit {
+	ra, rb := []rune(a), []rune(b)
+	aStarts, bStarts := lineStarts(ra), lineStarts(rb)
+	lines := map[string]int{}
+	aLines, bLines := lineIDs(ra, aStarts, lines), lineIDs(rb, bStarts, lines)
+
+	var edits []Edit
+	for _, lh := range diffSequences(aLines, bLines) {
+		if lh.aHi-lh.aLo != lh.bHi-lh.bLo {
+			edits = diffRunes(edits, ra, rb, aStarts[lh.aLo], aStarts[lh.aHi], bStarts[lh.bLo], bStarts[lh.bHi])
+			continue
+		}
+		for k := 0; k < lh.aHi-lh.aLo; k++ {
+			edits = diffRunes(edits, ra, rb, aStarts[lh.aLo+k], aStarts[lh.aLo+k+1], bStarts[lh.bLo+k], bStarts[lh.bLo+k+1])
+		}
+	}
+
+	// the edits are ordered by position, so their byte offsets are found in a single pass
+	position, offset := 0, 0
+	for k := range edits {
+		for ; position < edits[k].Position; position++ {
+			offset += utf8.RuneLen(ra[position])
+		}
+		edits[k].ByteOffset = offset
+	}
+	return edits
+}
+
+// diffRunes appends the edits which turn a[aLo:aHi] into b[bLo:bHi] to the edits.
+func diffRunes(edits []Edit, a, b []rune, aLo, aHi, bLo, bHi int) []Edit {
+	for _, h := range diffSequences(a[aLo:aHi], b[bLo:bHi]) {
+		if h.aLo < h.aHi {
+			edits = append(edits, Edit{Kind: EditDelete, Position: aLo + h.aLo, Text: string(a[aLo+h.aLo : aLo+h.aHi])})
+		}
+		if h.bLo < h.bHi {
+			edits = append(edits, Edit{Kind: EditInsert, Position: aLo + h.aHi, Text: string(b[bLo+h.bLo : bLo+h.bHi])})
+		}
+	}
+	return edits
+}
+
+// lineStarts returns the positions where the lines of the text start, followed by the length
+// of the text. A line includes its newline.
+func lineStarts(text []rune) []int {
+	starts := []int{0}
+	for k, char := range text {
+		if char == codeNewline {
+			starts = append(starts, k+1)
+		}
+	}
+	if starts[len(starts)-1] != len(text) {
+		starts = append(starts, len(text))
+	}
+	return starts
+}
+
+// lineIDs returns the lines of the text as numbers, which are the same for the same lines.
+func lineIDs(text []rune, starts []int, lines map[string]int) []int {
+	ids := make([]int, len(starts)-1)
+	for k := range ids {
+		line := string(text[starts[k]:starts[k+1]])
+		id, ok := lines[line]
+		if !ok {
+			id = len(lines)
+			lines[line] = id
+		}
+		ids[k] = id
+	}
+	return ids
+}
+
+// maxDiffCost bounds the work of a single search for the middle of the edits of a part of the
+// sequences, about its length multiplied by the number of edits, so a diff of very different
+// texts can not take too long.
+const maxDiffCost = 1 << 24
+
+// hunk is a part a[aLo:aHi] of a sequence which is replaced with the part b[bLo:bHi] of another.
+type hunk struct {
+	aLo, aHi, bLo, bHi int
+}
+
+// diffSequences returns the hunks which turn a into b, ordered by position.
+func diffSequences[T comparable](a, b []T) []hunk {
+	d := &differ[T]{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+	return d.hunks
+}
+
+// differ finds the hunks which turn a into b, with the linear space variant of the O(ND)
+// algorithm of Myers.
+type differ[T comparable] struct {
+	a, b  []T
+	hunks []hunk
+}
+
+// compare adds the hunks which turn a[aLo:aHi] into b[bLo:bHi].
+func (d *differ[T]) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+	if aLo == aHi && bLo == bHi {
+		return
+	}
+	if aLo < aHi && bLo < bHi {
+		if x, y, ok := d.bisect(aLo, aHi, bLo, bHi); ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+			return
+		}
+	}
+	d.add(hunk{aLo, aHi, bLo, bHi})
+}
+
+// add adds a hunk, merged with the previous hunk when they are adjacent.
+func (d *differ[T]) add(h hunk) {
+	if n := len(d.hunks); n > 0 && d.hunks[n-1].aHi == h.aLo && d.hunks[n-1].bHi == h.bLo {
+		d.hunks[n-1].aHi, d.hunks[n-1].bHi = h.aHi, h.bHi
+		return
+	}
+	d.hunks = append(d.hunks, h)
+}
+
+// bisect finds the middle of the shortest edits which turn a[aLo:aHi] into b[bLo:bHi], by
+// searching forward from the start and backward from the end until the searches overlap, and
+// returns the positions in a and b where the edits can be split. It returns false when the
+// parts differ too much to search within maxDiffCost.
+func (d *differ[T]) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	limit := max(maxDiffCost/(n+m), 64)
+	offset := maxD + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for k := range forward {
+		forward[k], backward[k] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// with an odd delta the forward search finds the overlap, else the backward search
+	odd := delta%2 != 0
+	// the diagonals which left the edit graph are skipped
+	kStart, kEnd, rStart, rEnd := 0, 0, 0, 0
+	for e := 0; e < maxD && e <= limit; e++ {
+		for k := -e + kStart; k <= e-kEnd; k += 2 {
+			var x int
+			if k == -e || k != e && forward[offset+k-1] < forward[offset+k+1] {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				kEnd += 2
+			case y > m:
+				kStart += 2
+			case odd:
+				if r := offset + delta - k; r >= 0 && r < len(backward) && backward[r] != -1 && x >= n-backward[r] {
+					return d.split(aLo, bLo, x, y, n, m)
+				}
+			}
+		}
+		for r := -e + rStart; r <= e-rEnd; r += 2 {
+			var x int
+			if r == -e || r != e && backward[offset+r-1] < backward[offset+r+1] {
+				x = backward[offset+r+1]
+			} else {
+				x = backward[offset+r-1] + 1
+			}
+			y := x - r
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[offset+r] = x
+			switch {
+			case x > n:
+				rEnd += 2
+			case y > m:
+				rStart += 2
+			case !odd:
+				if k := offset + delta - r; k >= 0 && k < len(forward) && forward[k] != -1 {
+					fx := forward[k]
+					if fx >= n-x {
+						return d.split(aLo, bLo, fx, fx-(k-offset), n, m)
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// split returns the split point x, y of parts of the lengths n and m as positions in a and b,
+// or false when it does not split them, which would not make progress.
+func (d *differ[T]) split(aLo, bLo, x, y, n, m int) (int, int, bool) {
+	if x == 0 && y == 0 || x == n && y == m {
+		return 0, 0, false
+	}
+	return aLo + x, bLo + y, true
+}
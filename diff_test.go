@@ -0,0 +1,87 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyEdits applies the edits returned by Diff to the text.
+func applyEdits(text string, edits []Edit) string {
+	var output strings.Builder
+	position := 0
+	for _, edit := range edits {
+		output.WriteString(text[position:edit.ByteOffset])
+		position = edit.ByteOffset
+		if edit.Kind == EditInsert {
+			output.WriteString(edit.Text)
+		} else {
+			position += len(edit.Text)
+		}
+	}
+	output.WriteString(text[position:])
+	return output.String()
+}
+
+// TestDiff tests returning the edits of the repair.
+func TestDiff(t *testing.T) {
+	edits, err := Diff(`{name: 'é', "b": 1,}`)
+	require.NoError(t, err)
+	assert.Equal(t, []Edit{
+		{Kind: EditInsert, Position: 1, ByteOffset: 1, Text: `"`},
+		{Kind: EditInsert, Position: 5, ByteOffset: 5, Text: `"`},
+		{Kind: EditDelete, Position: 7, ByteOffset: 7, Text: `'`},
+		{Kind: EditInsert, Position: 8, ByteOffset: 8, Text: `"`},
+		{Kind: EditDelete, Position: 9, ByteOffset: 10, Text: `'`},
+		{Kind: EditInsert, Position: 10, ByteOffset: 11, Text: `"`},
+		{Kind: EditDelete, Position: 18, ByteOffset: 19, Text: `,`},
+	}, edits)
+
+	edits, err = Diff(`{"a": 1}`)
+	require.NoError(t, err)
+	assert.Empty(t, edits)
+
+	_, err = Diff(`{"a": 1} x`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
+
+// TestDiffAppliesToRepair tests that applying the edits to the input gives the repaired output.
+func TestDiffAppliesToRepair(t *testing.T) {
+	var corpus []string
+	data, err := os.ReadFile(corpusFile)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &corpus))
+	corpus = append(corpus, strings.Repeat("{a: 'b'}\n", 200))
+
+	for _, input := range corpus {
+		repaired, err := JSONRepair(input)
+		if err != nil {
+			continue
+		}
+		edits, err := Diff(input)
+		require.NoError(t, err)
+		assert.Equal(t, repaired, applyEdits(input, edits), input)
+	}
+}
+
+// TestDiffTexts tests finding the shortest edits between two texts.
+func TestDiffTexts(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected []Edit
+	}{
+		{"abc", "abc", nil},
+		{"abc", "abd", []Edit{{Kind: EditDelete, Position: 2, ByteOffset: 2, Text: "c"}, {Kind: EditInsert, Position: 3, ByteOffset: 3, Text: "d"}}},
+		{"abc", "xabcx", []Edit{{Kind: EditInsert, Position: 0, ByteOffset: 0, Text: "x"}, {Kind: EditInsert, Position: 3, ByteOffset: 3, Text: "x"}}},
+		{"axbxc", "abc", []Edit{{Kind: EditDelete, Position: 1, ByteOffset: 1, Text: "x"}, {Kind: EditDelete, Position: 3, ByteOffset: 3, Text: "x"}}},
+		{"", "ab", []Edit{{Kind: EditInsert, Position: 0, ByteOffset: 0, Text: "ab"}}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, diffTexts(tt.a, tt.b), tt.a+" > "+tt.b)
+		assert.Equal(t, tt.b, applyEdits(tt.a, diffTexts(tt.a, tt.b)))
+	}
+}
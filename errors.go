@@ -1,6 +1,11 @@
 package jsonrepair
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
 
 // Define error types for specific JSON repair issues
 var (
@@ -10,4 +15,145 @@ var (
 	ErrInvalidCharacter    = errors.New("invalid character")
 	ErrUnexpectedCharacter = errors.New("unexpected character")
 	ErrInvalidUnicode      = errors.New("invalid unicode character")
+	ErrInvalidNumber       = errors.New("invalid number")
+	ErrNoProgress          = errors.New("repair made no progress")
+	ErrNoJSONValue         = errors.New("no json value found")
+	ErrInvalidOption       = errors.New("invalid option")
+	ErrUnknownFunction     = errors.New("unknown function")
+	ErrTruncated           = errors.New("data truncated with ellipsis")
+	ErrInvalidUTF8         = errors.New("invalid utf-8")
+	ErrMaxDepth            = errors.New("maximum nesting depth exceeded")
+	ErrInputTooLarge       = errors.New("input too large")
+	ErrOutputTooLarge      = errors.New("output too large")
+	ErrInternal            = errors.New("internal error")
+	ErrRuleDisabled        = errors.New("repair rule disabled")
+	ErrInvalidOutput       = errors.New("repaired json is invalid")
 )
+
+// Error is returned when a text can not be repaired. It wraps one of the errors above, which
+// can be checked with errors.Is, and tells where in the text the repair stopped, both in runes
+// and in bytes. Use errors.As to get it.
+type Error struct {
+	// Err is the kind of error, like ErrUnexpectedEnd.
+	Err error
+
+	// Detail is the offending text, like 'x' for ErrUnexpectedCharacter, or empty.
+	Detail string
+
+	// Position is the offset in the text in runes (Unicode code points), like Repair.Position.
+	// Use it to index []rune(text).
+	Position int
+
+	// ByteOffset is the offset in the text in bytes. Use it to slice the text, like
+	// text[ByteOffset:]. When the input was transcoded or decoded, the offsets are in the
+	// transcoded or decoded text.
+	ByteOffset int
+
+	// Line and Column are the line and the column in runes, both starting at 1.
+	Line, Column int
+}
+
+// Error returns the message of the error, like "unexpected character: 'x' at position 10".
+// The position in the message is the offset in runes.
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%v: %s at position %d", e.Err, e.Detail, e.Position)
+	}
+	return fmt.Sprintf("%v at position %d", e.Err, e.Position)
+}
+
+// Unwrap returns the kind of error, so errors.Is(err, ErrUnexpectedEnd) works.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError returns an error at the given offset in runes. The byte offset, line and column are
+// set with locate once the whole text is known.
+func newError(err error, detail string, position int) *Error {
+	return &Error{Err: err, Detail: detail, Position: position}
+}
+
+// locate sets the byte offset, line and column of err, when it is an *Error, from the text.
+func locate(err error, text []rune) error {
+	var e *Error
+	if errors.As(err, &e) {
+		e.ByteOffset = byteOffset(text, e.Position)
+		e.Line, e.Column = lineColumn(text, e.Position)
+	}
+	return err
+}
+
+// maxJoinedErrors is the maximum number of errors collected with WithAllErrors.
+const maxJoinedErrors = 20
+
+// skippedText is a run of text skipped to collect the next error, in the text it was skipped from.
+type skippedText struct {
+	position, length int
+}
+
+// collectErrors repairs the text again after first, the error of the repair, skipping the
+// unexpected text where the repair stopped each time, and returns the errors joined. Only errors
+// which leave text to skip are followed, and their positions are mapped back to the text.
+func collectErrors(text string, o *options, first error) error {
+	var e *Error
+	if !errors.As(first, &e) || !resumable(e, text) || !keepsPositions(text, o) {
+		return first
+	}
+
+	input := []rune(text)
+	current := input
+	var skipped []skippedText
+	errs := []error{first}
+	for len(errs) < maxJoinedErrors && resumable(e, string(current)) {
+		position := e.Position
+		end := position + 1
+		if !isDelimiter(current[position]) && !isQuote(current[position]) {
+			for end < len(current) && !isWhitespace(current[end]) && !isDelimiter(current[end]) && !isQuote(current[end]) {
+				end++
+			}
+		}
+		skipped = append(skipped, skippedText{position, end - position})
+		current = append(current[:position:position], current[end:]...)
+
+		retry := *o
+		retry.allErrors, retry.report, retry.warnings = false, nil, nil
+		retry.err, retry.depth, retry.offset = nil, 0, 0
+		_, err := repairText(string(current), &retry)
+		if err == nil {
+			break
+		}
+		if !errors.As(err, &e) {
+			errs = append(errs, err)
+			break
+		}
+		next := *e
+		for k := len(skipped) - 1; k >= 0; k-- {
+			if next.Position >= skipped[k].position {
+				next.Position += skipped[k].length
+			}
+		}
+		errs = append(errs, locate(&next, input))
+	}
+	if len(errs) == 1 {
+		return first
+	}
+	return errors.Join(errs...)
+}
+
+// resumable reports whether the repair can go on after the error e in the text, by skipping the
+// text at its position.
+func resumable(e *Error, text string) bool {
+	return e.Position < utf8.RuneCountInString(text) &&
+		!errors.Is(e, ErrUnexpectedEnd) && !errors.Is(e, ErrMaxDepth)
+}
+
+// keepsPositions reports whether the positions of the errors of the repair are positions in the
+// text, which is not transcoded, decoded or stripped before it is repaired.
+func keepsPositions(text string, o *options) bool {
+	if _, bom := splitByteOrderMark(text); bom != "" {
+		return false
+	}
+	return (o.encoding == "" || o.encoding == "utf-8") && detectUnicodeEncoding(text) == "" && !o.base64Decoding &&
+		!o.urlDecoding && !o.detectCharset && utf8.ValidString(text) &&
+		!(o.nulPolicy == NULStrip && strings.ContainsRune(text, 0))
+}
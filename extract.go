@@ -0,0 +1,134 @@
+package jsonrepair
+
+import "strings"
+
+// Extract finds the first JSON object or array embedded in text like an LLM answer, an email
+// or an HTML page, and returns it repaired, together with its span text[start:end] in bytes.
+// Brackets which do not look like the start of JSON, like in "[citation needed]" or "{braces}",
+// are skipped. ErrNoJSONValue is returned when there is no JSON value in the text.
+func Extract(text string, opts ...Option) (repaired string, start, end int, err error) {
+	o := newOptions(opts...)
+	o.checkInputSize(len(text))
+	if o.err != nil {
+		return "", 0, 0, o.err
+	}
+	defer startReport(text, o)()
+
+	c := newCursor([]rune(text))
+	for !c.done() {
+		if repaired, from, to, ok := extractValue(c, o); ok {
+			if repaired, err = o.limitOutput(repaired); err != nil {
+				return "", 0, 0, err
+			}
+			return repaired, byteOffset(c.text, from), byteOffset(c.text, to), nil
+		}
+	}
+	return "", 0, 0, ErrNoJSONValue
+}
+
+// Extracted is a JSON value found by ExtractAll.
+type Extracted struct {
+	// Output is the repaired value.
+	Output string
+
+	// Start and End are the span text[Start:End] of the value in the input, in bytes.
+	Start, End int
+}
+
+// ExtractAll finds every JSON object or array embedded in text like a chat transcript or a
+// scraped page, and returns them repaired with their spans, in order of appearance. Values
+// nested in an extracted value are part of it. ErrNoJSONValue is returned when there is no
+// JSON value in the text.
+func ExtractAll(text string, opts ...Option) ([]Extracted, error) {
+	o := newOptions(opts...)
+	o.checkInputSize(len(text))
+	if o.err != nil {
+		return nil, o.err
+	}
+	defer startReport(text, o)()
+
+	var values []Extracted
+	var size int
+	c := newCursor([]rune(text))
+	for !c.done() {
+		if repaired, from, to, ok := extractValue(c, o); ok {
+			// the limit is for the values together
+			size += len(repaired)
+			if err := o.checkOutputSize(size); err != nil {
+				return nil, err
+			}
+			values = append(values, Extracted{Output: repaired, Start: byteOffset(c.text, from), End: byteOffset(c.text, to)})
+		}
+	}
+	if len(values) == 0 {
+		return nil, ErrNoJSONValue
+	}
+	return values, nil
+}
+
+// extractValue repairs the object or array starting at the current position and returns it
+// with its span, or moves the cursor to the next rune when there is no JSON value there.
+func extractValue(c *cursor, opts *options) (string, int, int, bool) {
+	start := c.pos
+	if !atEmbeddedValue(c) {
+		c.next()
+		return "", 0, 0, false
+	}
+
+	var repairs, comments, warnings int
+	if opts.report != nil {
+		repairs, comments = len(opts.report.Repairs), opts.report.Comments
+	}
+	if opts.warnings != nil {
+		warnings = len(*opts.warnings)
+	}
+	var output strings.Builder
+	if (parseObject(c, &output, opts) || parseArray(c, &output, opts)) && opts.err == nil {
+		end := prevNonWhitespaceIndex(c.text, c.pos-1) + 1
+		return strings.TrimRightFunc(output.String(), isWhitespace), start, end, true
+	}
+
+	// not a JSON value after all: forget its repairs, and continue after the bracket
+	if opts.report != nil {
+		opts.report.Repairs = opts.report.Repairs[:repairs]
+		opts.report.Comments = comments
+	}
+	if opts.warnings != nil {
+		*opts.warnings = (*opts.warnings)[:warnings]
+	}
+	opts.err = nil
+	c.pos = start + 1
+	return "", 0, 0, false
+}
+
+// atEmbeddedValue checks if the current position looks like the start of a JSON object or
+// array in prose: an opening brace followed by a key and a colon, a quote or the closing
+// brace, or an opening bracket followed by a value or the closing bracket.
+func atEmbeddedValue(c *cursor) bool {
+	j := *c
+	opening := j.next()
+	if opening != codeOpeningBrace && opening != codeOpeningBracket {
+		return false
+	}
+	skipWhitespace(&j)
+
+	char := j.peek(0)
+	switch {
+	case isQuote(char):
+		return true
+	case opening == codeOpeningBrace:
+		if char == codeClosingBrace {
+			return true
+		}
+		keyStart := j.pos
+		for isSymbolChar(j.peek(0)) || j.peek(0) == codeMinus {
+			j.next()
+		}
+		keyEnd := j.pos
+		skipWhitespace(&j)
+		return keyEnd > keyStart && j.peek(0) == codeColon
+	default:
+		return char == codeClosingBracket || char == codeOpeningBrace || char == codeOpeningBracket ||
+			isDigit(char) || char == codeMinus || atWord(&j, "true") || atWord(&j, "false") || atWord(&j, "null")
+	}
+}
@@ -0,0 +1,79 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		span     string
+	}{
+		{"prose", `Here is the JSON: {"a": 1}. Hope this helps`, `{"a": 1}`, `{"a": 1}`},
+		{"repaired", "Sure! {name: 'John', tags: ['a', 'b'],} Anything else?", `{"name": "John", "tags": ["a", "b"]}`, "{name: 'John', tags: ['a', 'b'],}"},
+		{"markdown", "```json\n[1, 2]\n```", `[1, 2]`, `[1, 2]`},
+		{"html", `<p>data: [{"id": 1}, {"id": 2}]</p>`, `[{"id": 1}, {"id": 2}]`, `[{"id": 1}, {"id": 2}]`},
+		{"skips brackets", `[citation needed] use {braces} for {"a": 1}`, `{"a": 1}`, `{"a": 1}`},
+		{"truncated", `the result is {"a": [1, 2`, `{"a": [1, 2]}`, `{"a": [1, 2`},
+		{"unclosed", "{'a' 1; ] is broken", `{"a": 1}`, "{'a' 1;"},
+		{"multibyte", `héllo → {'ü': 'x'} ok`, `{"ü": "x"}`, `{'ü': 'x'}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired, start, end, err := Extract(tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, repaired)
+			assert.Equal(t, tt.span, tt.text[start:end])
+		})
+	}
+}
+
+func TestExtractNoValue(t *testing.T) {
+	for _, text := range []string{"", "no json here", "use {braces} or [a list]"} {
+		_, _, _, err := Extract(text)
+		require.ErrorIs(t, err, ErrNoJSONValue, text)
+	}
+}
+
+func TestExtractReport(t *testing.T) {
+	var report Report
+	_, _, _, err := Extract(`{"x" ) then {a: 1}`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 13, Kind: RepairMissingQuote, Message: "added missing quotes"}}, report.Repairs)
+
+	// the comments of text which is not extracted are not counted
+	_, _, _, err = Extract(`{"x" /* c */ ) then {"a": /* c */ 1}`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Comments)
+}
+
+func TestExtractAll(t *testing.T) {
+	text := "user: what about {a: 1}?\nbot: try [1, 2] or {\"b\": {\"c\": 3}} instead [citation needed]"
+	values, err := ExtractAll(text)
+	require.NoError(t, err)
+	assert.Equal(t, []Extracted{
+		{Output: `{"a": 1}`, Start: 17, End: 23},
+		{Output: `[1, 2]`, Start: 34, End: 40},
+		{Output: `{"b": {"c": 3}}`, Start: 44, End: 59},
+	}, values)
+	for _, value := range values {
+		assert.Contains(t, []string{"{a: 1}", "[1, 2]", `{"b": {"c": 3}}`}, text[value.Start:value.End])
+	}
+
+	_, err = ExtractAll("nothing here")
+	require.ErrorIs(t, err, ErrNoJSONValue)
+}
+
+func TestExtractAllTruncated(t *testing.T) {
+	values, err := ExtractAll(`first {"a": 1} then {"b": [2`)
+	require.NoError(t, err)
+	assert.Equal(t, []Extracted{
+		{Output: `{"a": 1}`, Start: 6, End: 14},
+		{Output: `{"b": [2]}`, Start: 20, End: 28},
+	}, values)
+}
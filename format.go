@@ -0,0 +1,79 @@
+package jsonrepair
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// WhitespacePolicy defines the whitespace of the repaired output.
+type WhitespacePolicy int
+
+const (
+	// WhitespacePreserve keeps the whitespace of the input as closely as possible. Whitespace
+	// around removed parts, like comments or an ellipsis, is kept too.
+	WhitespacePreserve WhitespacePolicy = iota
+
+	// WhitespaceIndent reformats the output with one member or item per line, indented with
+	// two spaces, like json.MarshalIndent.
+	WhitespaceIndent
+
+	// WhitespaceCompact reformats the output without any whitespace, like json.Marshal.
+	WhitespaceCompact
+)
+
+// applyWhitespacePolicy reformats the repaired output with the given policy. The output is
+// kept when it is not valid JSON, which does not happen with WithAnnotations as it is rejected
+// with the other policies.
+func applyWhitespacePolicy(output string, policy WhitespacePolicy) string {
+	var formatted bytes.Buffer
+	var err error
+	trimmed := []byte(strings.TrimSpace(output))
+	switch policy {
+	case WhitespaceIndent:
+		err = json.Indent(&formatted, trimmed, "", "  ")
+	case WhitespaceCompact:
+		err = json.Compact(&formatted, trimmed)
+	default:
+		return output
+	}
+	if err != nil {
+		return output
+	}
+	return formatted.String()
+}
+
+// formatOutput applies the whitespace policy, the escaping and then the newline style to the
+// repaired output.
+func formatOutput(output string, opts *options) string {
+	output = applyWhitespacePolicy(output, opts.whitespacePolicy)
+	if opts.escapeNonASCII || opts.escapeHTML {
+		output = escapeOutput(output, opts)
+	}
+	return applyNewlineStyle(output, opts.newlineStyle)
+}
+
+// escapeOutput escapes the characters of the output as \uXXXX: the characters above U+007F
+// with WithEscapeNonASCII, with a surrogate pair for the characters above U+FFFF, and <, >, &,
+// U+2028 and U+2029 with WithEscapeHTML. Only strings contain such characters in a repaired
+// output.
+func escapeOutput(output string, opts *options) string {
+	var escaped strings.Builder
+	for _, char := range output {
+		switch {
+		case opts.escapeHTML && (char == '<' || char == '>' || char == '&' || char == '\u2028' || char == '\u2029'):
+			fmt.Fprintf(&escaped, `\u%04x`, char)
+		case char <= unicode.MaxASCII || !opts.escapeNonASCII:
+			escaped.WriteRune(char)
+		case char > 0xFFFF:
+			high, low := utf16.EncodeRune(char)
+			fmt.Fprintf(&escaped, `\u%04x\u%04x`, high, low)
+		default:
+			fmt.Fprintf(&escaped, `\u%04x`, char)
+		}
+	}
+	return escaped.String()
+}
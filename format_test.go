@@ -0,0 +1,69 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWhitespacePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		policy   WhitespacePolicy
+		expected string
+	}{
+		{"preserve", "[1, 2, 3, ... ]", WhitespacePreserve, "[1, 2, 3  ]"},
+		{"indent", "[1, 2, 3, ... ]", WhitespaceIndent, "[\n  1,\n  2,\n  3\n]"},
+		{"compact", "[1, 2, 3, ... ]", WhitespaceCompact, "[1,2,3]"},
+		{"indent nested", "\n{a:2,\n  /*c*/ b: [1,/*x*/]}\n", WhitespaceIndent, "{\n  \"a\": 2,\n  \"b\": [\n    1\n  ]\n}"},
+		{"compact nested", "{a: 2, b: {c: 'x y'}}", WhitespaceCompact, `{"a":2,"b":{"c":"x y"}}`},
+		{"numbers and strings are kept", `{"n": 1.50e3, "s": "<&>"}`, WhitespaceCompact, `{"n":1.50e3,"s":"<&>"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := JSONRepair(tt.input, WithWhitespacePolicy(tt.policy))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestWithWhitespacePolicyNewlineStyle(t *testing.T) {
+	result, err := JSONRepair("[1]", WithWhitespacePolicy(WhitespaceIndent), WithNewlineStyle(NewlineCRLF))
+	require.NoError(t, err)
+	assert.Equal(t, "[\r\n  1\r\n]", result)
+}
+
+func TestWithEscapeNonASCII(t *testing.T) {
+	result, err := JSONRepair(`{"a": "café 😀", é: ü}`, WithEscapeNonASCII())
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": "caf\u00e9 \ud83d\ude00", "\u00e9": "\u00fc"}`, result)
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal([]byte(result), &decoded))
+	assert.Equal(t, map[string]string{"a": "café 😀", "é": "ü"}, decoded)
+}
+
+func TestWithEscapeHTML(t *testing.T) {
+	input := "{\"html\": \"</script><b>&amp;\", \"sep\": \"a\u2028b\u2029c\", \"é\": 1}"
+	result, err := JSONRepair(input, WithEscapeHTML())
+	require.NoError(t, err)
+	assert.Equal(t, `{"html": "\u003c/script\u003e\u003cb\u003e\u0026amp;", "sep": "a\u2028b\u2029c", "é": 1}`, result)
+
+	// the same escaping as encoding/json
+	expected, err := json.Marshal(map[string]string{"html": "</script><b>&amp;", "sep": "a\u2028b\u2029c"})
+	require.NoError(t, err)
+	result, err = JSONRepair(string(expected), WithEscapeHTML())
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), result)
+	result, err = JSONRepair("{html: '</script><b>&amp;', sep: 'a\u2028b\u2029c'}", WithEscapeHTML(), WithWhitespacePolicy(WhitespaceCompact))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), result)
+
+	result, err = JSONRepair(`["<é>"]`, WithEscapeHTML(), WithEscapeNonASCII())
+	require.NoError(t, err)
+	assert.Equal(t, `["\u003c\u00e9\u003e"]`, result)
+}
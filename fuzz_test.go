@@ -0,0 +1,115 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// fuzzTimeout is the time budget for repairing a single fuzz input. Inputs are small,
+// so a repair which takes longer is considered to loop forever.
+const fuzzTimeout = 2 * time.Second
+
+// FuzzJSONRepair checks that every repair terminates within the time budget, and never
+// stops with ErrNoProgress, which reveals a parser bug. Run it with: go test -fuzz FuzzJSONRepair
+func FuzzJSONRepair(f *testing.F) {
+	var corpus []string
+	data, err := os.ReadFile(corpusFile)
+	if err != nil {
+		f.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		f.Fatal(err)
+	}
+	for _, input := range corpus {
+		f.Add(input)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		done := make(chan error, 1)
+		go func() {
+			// this harness looks for repairs which do not terminate, a panic counts as terminated
+			defer func() {
+				if recover() != nil {
+					done <- nil
+				}
+			}()
+			_, err := JSONRepair(input)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if errors.Is(err, ErrNoProgress) {
+				t.Fatalf("repair of %q made no progress: %v", input, err)
+			}
+		case <-time.After(fuzzTimeout):
+			t.Fatalf("repair of %q did not terminate within %s", input, fuzzTimeout)
+		}
+	})
+}
+
+// fuzzOptions are the options which FuzzJSONRepairPanics combines, to reach their code paths.
+var fuzzOptions = []Option{
+	WithAllErrors(),
+	WithAnnotations(),
+	WithCharsetDetection(),
+	WithColonsInKeys(),
+	WithDropBareKeys(),
+	WithDropEmptyArraySlots(),
+	WithEllipsisPolicy(EllipsisPlaceholder),
+	WithEqualsSeparator(),
+	WithEscapeHTML(),
+	WithEscapeNonASCII(),
+	WithFunctionCallPolicy(FunctionCallKeep),
+	WithGoSyntax(),
+	WithHTMLEntities(),
+	WithHashComments(),
+	WithInvalidNumberPolicy(InvalidNumberTruncate),
+	WithMarkdown(),
+	WithMaxDepth(8),
+	WithMaxStringScan(8),
+	WithNULPolicy(NULEscape),
+	WithPreserveBigNumbers(),
+	WithNestedStringRepair(regexp.MustCompile(`.`)),
+	WithInlineNestedStrings(),
+	WithNewlineStyle(NewlineCRLF),
+	WithReplacementCharQuotes(),
+	WithSkipLogPrefix(),
+	WithSkipPreamble(),
+	WithStringNewlinePolicy(StringNewlineEnd),
+	WithSurrogatePolicy(SurrogateDrop),
+	WithURLDecoding(),
+	WithWhitespacePolicy(WhitespaceCompact),
+	WithYAMLBlocks(),
+}
+
+// FuzzJSONRepairPanics checks that no input makes the repair panic, with any combination of
+// the fuzzOptions selected by the bits of the flags.
+// Run it with: go test -run '^$' -fuzz FuzzJSONRepairPanics
+func FuzzJSONRepairPanics(f *testing.F) {
+	var corpus []string
+	data, err := os.ReadFile(corpusFile)
+	if err != nil {
+		f.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		f.Fatal(err)
+	}
+	for i, input := range corpus {
+		f.Add(input, uint32(i*2654435761))
+	}
+
+	f.Fuzz(func(t *testing.T, input string, flags uint32) {
+		opts := []Option{WithReport(&Report{})}
+		for i, opt := range fuzzOptions {
+			if flags&(1<<i) != 0 {
+				opts = append(opts, opt)
+			}
+		}
+		_, _ = JSONRepair(input, opts...)
+	})
+}
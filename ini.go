@@ -0,0 +1,63 @@
+package jsonrepair
+
+import "strings"
+
+// RepairINI converts an INI file to a JSON object: a [section] header starts a nested object,
+// and keys before the first section are members of the root object. Keys are separated from
+// their values by an equals sign or a colon, lines starting with ; or # are comments, and an
+// unquoted value ends at a ; or # after whitespace. A section which is repeated is merged.
+// Values are typed like with RepairProperties: port=8080 becomes a number, enabled=true a
+// boolean and name=app a string.
+func RepairINI(text string, opts ...Option) (string, error) {
+	o := newOptions(opts...)
+	o.checkInputSize(len(text))
+	if o.err != nil {
+		return "", o.err
+	}
+
+	root := &orderedObject{values: map[string]any{}}
+	section := root
+	for _, line := range propertyLines(text) {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if name, ok := parseINISection(line); ok {
+			existing, ok := root.values[name].(*orderedObject)
+			if !ok {
+				existing = &orderedObject{values: map[string]any{}}
+				root.set(name, existing)
+			}
+			section = existing
+			continue
+		}
+		key, value := splitProperty(line)
+		if trimmed := strings.TrimSpace(value); trimmed != "" && !isQuote(rune(trimmed[0])) {
+			// a semicolon after whitespace starts a comment, like a hash
+			value, _, _ = strings.Cut(value, " ;")
+		}
+		section.set(key, propertyValue(value, o))
+	}
+	output, err := encodeValue(root)
+	if err != nil {
+		return "", err
+	}
+	return o.limitOutput(output)
+}
+
+// parseINISection parses a section header like [section], and returns the name of the section.
+// A comment may follow the header.
+func parseINISection(line string) (string, bool) {
+	if line[0] != '[' {
+		return "", false
+	}
+	end := strings.IndexByte(line, ']')
+	if end < 0 {
+		return "", false
+	}
+	rest := strings.TrimSpace(line[end+1:])
+	if rest != "" && rest[0] != ';' && rest[0] != '#' {
+		return "", false
+	}
+	return strings.TrimSpace(line[1:end]), true
+}
@@ -0,0 +1,36 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairINI(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"sections", "[database]\nhost = localhost\nport = 5432\n\n[server]\nenabled = true", `{"database":{"host":"localhost","port":5432},"server":{"enabled":true}}`},
+		{"global keys", "; global\nname = app\n[a]\nk = v", `{"name":"app","a":{"k":"v"}}`},
+		{"comments", "# comment\n[a] ; header comment\nhost = localhost ; the host\nuser = admin # the user", `{"a":{"host":"localhost","user":"admin"}}`},
+		{"quoted values", "[paths]\nroot = \"C:\\\\app ; x\"\nname = 'a # b'\nport = \"80\"", `{"paths":{"root":"C:\\app ; x","name":"a # b","port":"80"}}`},
+		{"colon separator", "[a]\nuser: admin", `{"a":{"user":"admin"}}`},
+		{"repeated section", "[a]\nx = 1\n[b]\ny = 2\n[a]\nz = 3", `{"a":{"x":1,"z":3},"b":{"y":2}}`},
+		{"empty section", "[empty]\n", `{"empty":{}}`},
+		{"section names", "[ spaced name ]\n[remote \"origin\"]\nurl = git@host:repo.git", `{"spaced name":{},"remote \"origin\"":{"url":"git@host:repo.git"}}`},
+		{"broken header", "[broken\nk=1", `{"[broken":"","k":1}`},
+		{"json value", "[a]\nlist = [1 2]", `{"a":{"list":[1,2]}}`},
+		{"crlf", "[a]\r\nk=1\r\n", `{"a":{"k":1}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := RepairINI(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
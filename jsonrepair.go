@@ -1,321 +1,1050 @@
 package jsonrepair
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // JSONRepair attempts to repair the given JSON string and returns the repaired version.
-func JSONRepair(text string) (string, error) {
-	runes := []rune(text)
-	i := 0
+func JSONRepair(text string, opts ...Option) (string, error) {
+	return repair(text, newOptions(opts...))
+}
+
+// JSONRepairContext is like JSONRepair, but stops the repair when the context is canceled or
+// its deadline passes, and returns the error of the context. Use it to bound the time spent on
+// untrusted input, which can be crafted to make the repair slow.
+func JSONRepairContext(ctx context.Context, text string, opts ...Option) (string, error) {
+	o := newOptions(opts...)
+	o.ctx = ctx
+	return repair(text, o)
+}
+
+// repair repairs the text with the options. With WithAllErrors, a failed repair goes on to
+// collect the further errors of the text.
+func repair(text string, o *options) (string, error) {
+	output, err := repairText(text, o)
+	if err != nil && o.allErrors {
+		err = collectErrors(text, o, err)
+	}
+	return output, err
+}
+
+// repairText repairs the text with the options, stopping at the first error.
+func repairText(text string, o *options) (string, error) {
+	o.checkInputSize(len(text))
+	o.canceled()
+	if o.err != nil {
+		return "", o.err
+	}
+	defer startReport(text, o)()
+	original := text
+
+	var bom string
+	text, bom = splitByteOrderMark(text)
+	if o.report != nil {
+		o.report.ByteOrderMark = bom
+	}
+	if bom != "" {
+		// the byte order mark is before the text, so the repair has no annotation
+		if logRepair(0, &strings.Builder{}, "removed byte order mark", o); o.err != nil {
+			return "", o.err
+		}
+	}
+
+	// UTF-16 and UTF-32 input is transcoded, by its byte order mark or its zero bytes
+	encoding := o.encoding
+	if encoding == "" && bom != "" {
+		encoding = bom
+	} else if encoding == "" {
+		encoding = detectUnicodeEncoding(text)
+	}
+	if encoding != "" && encoding != "utf-8" {
+		text = transcodeUnicode(text, encoding)
+		if o.report != nil {
+			o.report.Charset = encoding
+		}
+	}
+	// a byte order mark without a document after it is no JSON value
+	if bom != "" && strings.TrimSpace(text) == "" {
+		return "", locate(newError(ErrNoJSONValue, "", 0), []rune(text))
+	}
+
+	if o.base64Decoding {
+		var decoded bool
+		text, decoded = decodeBase64(text)
+		if o.report != nil {
+			o.report.Base64Decoded = decoded
+		}
+	}
+
+	if o.urlDecoding {
+		var decoded bool
+		text, decoded = decodePercentEncoding(text)
+		if o.report != nil {
+			o.report.URLDecoded = decoded
+		}
+	}
+
+	if o.detectCharset {
+		var charset string
+		text, charset = detectCharset(text)
+		if o.report != nil {
+			o.report.Charset = charset
+		}
+	}
+
+	repaired, invalid, err := repairInvalidUTF8(text, o.invalidUTF8Policy)
+	if err != nil {
+		return "", locate(err, []rune(text))
+	}
+	text = repaired
+	if o.report != nil {
+		o.report.InvalidUTF8 = invalid
+	}
+
+	// valid JSON, which is most of the input, is kept as it is
+	if o.keepsValidJSON() && isValidJSON(text) {
+		return o.result(original, formatOutput(text, o))
+	}
+
+	if o.nulPolicy == NULStrip {
+		text = strings.ReplaceAll(text, "\x00", "")
+	}
+
+	if o.yamlBlocks && atYAMLBlock(text) {
+		repaired, err := repairYAMLBlock(text, o)
+		if err != nil {
+			return "", locate(err, []rune(text))
+		}
+		var output strings.Builder
+		output.WriteString(repaired)
+		logRepair(0, &output, "converted yaml block", o)
+		return o.result(original, formatOutput(output.String(), o))
+	}
+
+	// errors are located in the text including the preamble
+	input := []rune(text)
+
+	// a shebang line is always skipped, front-matter only when enabled
+	var preamble string
+	if o.skipPreamble {
+		preamble, text = splitPreamble(text)
+	} else {
+		preamble, text = splitShebang(text)
+	}
+	if o.skipLogPrefix {
+		var prefix string
+		prefix, text = splitLogPrefix(text)
+		preamble += prefix
+	}
+	offset := utf8.RuneCountInString(preamble)
+	o.offset = offset
+	if o.report != nil {
+		o.report.Preamble = preamble
+	}
+
+	c := newCursor([]rune(text))
 	var output strings.Builder
+	if strings.HasPrefix(preamble, "#!") {
+		// the shebang line is at the start of the input, before the text at the offset
+		logRepair(-offset, &output, "removed shebang line", o)
+	}
+
+	if skipReturnKeyword(c, &output, o) || skipVariableAssignment(c, &output, o) {
+		// repair: remove the semicolon ending the statement
+		end := prevNonWhitespaceIndex(c.text, c.len()-1)
+		if end > c.pos && c.at(end) == codeSemicolon {
+			c.text = append(c.text[:end], c.text[end+1:]...)
+		}
+	}
+
+	parentheses := skipOpeningParentheses(c, &output, o)
+
+	if !parseValue(c, &output, o) {
+		if o.err != nil {
+			return "", locate(o.err, input)
+		}
+		return "", locate(newError(ErrUnexpectedEnd, "", offset+c.len()), input)
+	}
+
+	for ; parentheses > 0 && skipCharacter(c, codeCloseParenthesis); parentheses-- {
+		// repair: remove the closing parenthesis, and the semicolon ending the statement
+		if parentheses == 1 {
+			skipCharacter(c, codeSemicolon)
+		}
+		parseWhitespaceAndSkipComments(c, &output, o)
+	}
 
-	if !parseValue(&runes, &i, &output) {
-		return "", fmt.Errorf("%w at position %d", ErrUnexpectedEnd, len(runes))
+	// repair: remove the semicolon ending a statement like {"a": 1};
+	if c.peek(0) == codeSemicolon && c.pos == prevNonWhitespaceIndex(c.text, c.len()-1) {
+		c.next()
+		logRepair(c.pos-1, &output, "removed semicolon", o)
 	}
 
-	processedComma := parseCharacter(&runes, &i, &output, codeComma)
+	processedComma := parseCharacter(c, &output, codeComma)
 	if processedComma {
-		parseWhitespaceAndSkipComments(&runes, &i, &output)
+		parseWhitespaceAndSkipComments(c, &output, o)
 	}
 
-	if i < len(runes) && isStartOfValue(runes[i]) && endsWithCommaOrNewline(output.String()) {
+	if !c.done() && isStartOfValue(c.peek(0)) && endsWithCommaOrNewline(output.String()) {
 		if !processedComma {
 			outputStr := insertBeforeLastWhitespace(output.String(), ",")
 			output.Reset()
 			output.WriteString(outputStr)
 		}
-		parseNewlineDelimitedJSON(&runes, &i, &output)
+		parseNewlineDelimitedJSON(c, &output, o)
 	} else if processedComma {
 		outputStr := stripLastOccurrence(output.String(), ",", false)
 		output.Reset()
 		output.WriteString(outputStr)
+		logRepair(c.pos, &output, "removed trailing comma", o)
 	}
 
 	// repair redundant end quotes
-	for i < len(runes) && (runes[i] == codeClosingBrace || runes[i] == codeClosingBracket) {
-		i++
-		parseWhitespaceAndSkipComments(&runes, &i, &output)
+	for c.peek(0) == codeClosingBrace || c.peek(0) == codeClosingBracket {
+		logRepair(c.pos, &output, "removed redundant closing bracket", o)
+		c.next()
+		parseWhitespaceAndSkipComments(c, &output, o)
 	}
 
-	if i >= len(runes) {
-		return output.String(), nil
+	if o.err != nil {
+		return "", locate(o.err, input)
 	}
 
-	return "", fmt.Errorf("%w: '%c' at position %d", ErrUnexpectedCharacter, runes[i], i)
+	if c.done() {
+		return o.result(original, formatOutput(output.String(), o))
+	}
+
+	return "", locate(newError(ErrUnexpectedCharacter, fmt.Sprintf("'%c'", c.peek(0)), offset+c.pos), input)
+}
+
+// RepairStringLiteral repairs a single string literal, like 'hello' or "line\nbreak, and returns it
+// as a valid JSON string. Only the string level repairs are applied: quotes are normalized, escape
+// characters are fixed and control characters are escaped. Text without quotes is turned into a string.
+func RepairStringLiteral(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", locate(newError(ErrUnexpectedEnd, "", 0), nil)
+	}
+
+	runes := []rune(text)
+	if !isQuote(runes[0]) && !(runes[0] == codeBackslash && len(runes) > 1 && isQuote(runes[1])) {
+		// repair missing quotes: all double quotes are part of the content
+		quoted := []rune{codeDoubleQuote}
+		for j, char := range runes {
+			if char == codeDoubleQuote && (j == 0 || runes[j-1] != codeBackslash) {
+				quoted = append(quoted, codeBackslash)
+			}
+			quoted = append(quoted, char)
+		}
+		runes = append(quoted, codeDoubleQuote)
+	}
+
+	c := newCursor(runes)
+	var output strings.Builder
+	o := newOptions()
+	if !parseString(c, &output, false, o) {
+		return "", locate(newError(ErrInvalidCharacter, "", c.pos), c.text)
+	}
+	if !c.done() {
+		return "", locate(newError(ErrUnexpectedCharacter, fmt.Sprintf("'%c'", c.peek(0)), c.pos), c.text)
+	}
+
+	return strings.TrimRightFunc(output.String(), isWhitespace), nil
 }
 
 // parseValue determines the type of the next value in the input text and parses it accordingly.
-func parseValue(text *[]rune, i *int, output *strings.Builder) bool {
-	parseWhitespaceAndSkipComments(text, i, output)
-
-	processed := parseObject(text, i, output) ||
-		parseArray(text, i, output) ||
-		parseString(text, i, output, false) ||
-		parseNumber(text, i, output) ||
-		parseKeywords(text, i, output) ||
-		parseUnquotedString(text, i, output)
-	parseWhitespaceAndSkipComments(text, i, output)
+func parseValue(c *cursor, output *strings.Builder, opts *options) bool {
+	if opts.err != nil || opts.canceled() {
+		return false
+	}
+	parseWhitespaceAndSkipComments(c, output, opts)
+
+	valueStart, valuePos := output.Len(), c.pos
+	processed := parseCustomRule(c, output, opts) ||
+		parseGoConversion(c, output, opts) ||
+		parseGoCompositeLiteral(c, output, opts) ||
+		parseJavaObject(c, output, opts) ||
+		parseObject(c, output, opts) ||
+		parseGoMap(c, output, opts) ||
+		parseToken(c, output, opts) ||
+		parseArray(c, output, opts) ||
+		parseFunction(c, output, opts) ||
+		parseString(c, output, false, opts) ||
+		parseNumber(c, output, opts) ||
+		parseKeywords(c, output, opts) ||
+		parsePHPArray(c, output, opts) ||
+		parseRubySymbol(c, output, opts) ||
+		parseUnquotedString(c, output, false, opts)
+	if processed {
+		transformString(output, valueStart, opts)
+		coerceValue(output, valueStart, valuePos, opts)
+	}
+	parseWhitespaceAndSkipComments(c, output, opts)
 	return processed
 }
 
+// skipMarkdownFence skips the fence of a Markdown code block, like ```json before and ```
+// after the value. A fence is skipped wherever whitespace is allowed, so the brackets of
+// [```json ... ```] are kept.
+func skipMarkdownFence(c *cursor, output *strings.Builder, opts *options) bool {
+	if !c.hasPrefix("```") {
+		return false
+	}
+	start := c.pos
+	c.skip(3)
+	// skip the language identifier like json, which ends the line of the opening fence,
+	// so that a value on the same line like ```true``` is kept
+	j := *c
+	for !j.done() && (isSymbolChar(j.peek(0)) || j.peek(0) == codeMinus) {
+		j.next()
+	}
+	end := j.pos
+	for j.peek(0) == codeSpace || j.peek(0) == codeTab || j.peek(0) == codeReturn {
+		j.next()
+	}
+	if j.done() || j.peek(0) == codeNewline {
+		c.pos = end
+	}
+	logRepair(start, output, "removed markdown fence", opts)
+	return true
+}
+
+// skipMarkdownMarkup skips the Markdown markup enabled with WithMarkdown: a heading like
+// ### Result and a list bullet like - or * at the start of a line, and a **bold** marker.
+// Like a fence, markup is skipped wherever whitespace is allowed.
+func skipMarkdownMarkup(c *cursor, output *strings.Builder, opts *options) bool {
+	if !opts.markdown {
+		return false
+	}
+	start := c.pos
+	switch {
+	case c.hasPrefix("**"):
+		c.skip(2)
+		logRepair(start, output, "removed markdown bold marker", opts)
+		return true
+	case !atLineStart(c):
+		return false
+	case c.peek(0) == codeHash:
+		j := *c
+		for j.peek(0) == codeHash {
+			j.next()
+		}
+		if j.pos-c.pos > 6 || !isWhitespace(j.peek(0)) {
+			return false
+		}
+		for !c.done() && c.peek(0) != codeNewline {
+			c.next()
+		}
+		logRepair(start, output, "removed markdown heading", opts)
+		return true
+	case (c.peek(0) == codeMinus || c.peek(0) == codeAsterisk || c.peek(0) == codePlus) &&
+		(c.peek(1) == codeSpace || c.peek(1) == codeTab):
+		c.skip(2)
+		logRepair(start, output, "removed markdown bullet", opts)
+		return true
+	}
+	return false
+}
+
+// atLineStart checks if only spaces and tabs are between the start of the line and the
+// current position.
+func atLineStart(c *cursor) bool {
+	for k := c.pos - 1; k >= 0; k-- {
+		switch c.at(k) {
+		case codeNewline, codeReturn:
+			return true
+		case codeSpace, codeTab:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// skipTagWrapper skips an opening or closing tag wrapped around the value, like <tool_call>
+// or </json>, when its name is one of the tag wrappers. Like a Markdown fence, a tag is
+// skipped wherever whitespace is allowed.
+func skipTagWrapper(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.peek(0) != codeLessThan {
+		return false
+	}
+	j := *c
+	j.next()
+	skipCharacter(&j, codeSlash)
+	nameStart := j.pos
+	for isSymbolChar(j.peek(0)) || j.peek(0) == codeMinus || j.peek(0) == codeColon {
+		j.next()
+	}
+	if !slices.Contains(opts.tagWrappers, string(c.slice(nameStart, j.pos))) {
+		return false
+	}
+	// skip attributes like <tool_call name="search">
+	for !j.done() && j.peek(0) != codeGreaterThan && j.peek(0) != codeNewline && j.peek(0) != codeLessThan {
+		j.next()
+	}
+	if !skipCharacter(&j, codeGreaterThan) {
+		return false
+	}
+	logRepair(c.pos, output, "removed tag wrapper", opts)
+	c.pos = j.pos
+	return true
+}
+
 // parseWhitespaceAndSkipComments parses whitespace and skips comments.
-func parseWhitespaceAndSkipComments(text *[]rune, i *int, output *strings.Builder) bool {
-	start := *i
-	parseWhitespace(text, i, output)
+func parseWhitespaceAndSkipComments(c *cursor, output *strings.Builder, opts *options) bool {
+	start := c.pos
+	parseWhitespace(c, output, opts)
 	for {
-		changed := parseComment(text, i)
-		if changed {
-			changed = parseWhitespace(text, i, output)
+		commentStart := c.pos
+		comment := parseComment(c, opts)
+		if comment {
+			if opts.report != nil {
+				opts.report.Comments++
+			}
+			logRepair(commentStart, output, "removed comment", opts)
 		}
-
+		changed := comment || skipMarkdownFence(c, output, opts) || skipTagWrapper(c, output, opts) ||
+			skipMarkdownMarkup(c, output, opts)
 		if !changed {
 			break
 		}
+		parseWhitespace(c, output, opts)
 	}
 
-	return *i > start
+	return c.pos > start
 }
 
 // parseWhitespace parses whitespace characters.
-func parseWhitespace(text *[]rune, i *int, output *strings.Builder) bool {
-	start := *i
+func parseWhitespace(c *cursor, output *strings.Builder, opts *options) bool {
+	start, specialPos, markPos := c.pos, -1, -1
 	whitespace := strings.Builder{}
-	for *i < len(*text) && (isWhitespace((*text)[*i]) || isSpecialWhitespace((*text)[*i])) {
-		if isWhitespace((*text)[*i]) {
-			whitespace.WriteRune((*text)[*i])
+	for !c.done() && (isWhitespace(c.peek(0)) || isSpecialWhitespace(c.peek(0)) || isCustomWhitespace(c.peek(0), opts) ||
+		c.peek(0) == codeByteOrderMark || c.peek(0) == 0 && opts.nulPolicy == NULEscape) {
+		if c.peek(0) == codeByteOrderMark || c.peek(0) == 0 {
+			// repair: remove a stray byte order mark, like one of concatenated files, or a NUL byte
+			if markPos < 0 && c.peek(0) == codeByteOrderMark {
+				markPos = c.pos
+			}
+		} else if isWhitespace(c.peek(0)) {
+			whitespace.WriteRune(c.peek(0))
 		} else {
-			whitespace.WriteRune(' ') // repair special whitespace
+			if specialPos < 0 && isSpecialWhitespace(c.peek(0)) {
+				specialPos = c.pos
+			}
+			whitespace.WriteRune(' ') // repair special and custom whitespace
 		}
-		*i++
+		c.next()
 	}
 	if whitespace.Len() > 0 {
 		output.WriteString(whitespace.String())
-		return true
 	}
-	return *i > start
+	if specialPos >= 0 {
+		logRepair(specialPos, output, "replaced special whitespace", opts)
+	}
+	if markPos >= 0 {
+		logRepair(markPos, output, "removed byte order mark", opts)
+	}
+	return c.pos > start
 }
 
 // parseComment parses both single-line (//) and multi-line (/* */) comments.
-func parseComment(text *[]rune, i *int) bool {
-	if *i+1 < len(*text) {
-		if (*text)[*i] == codeSlash && (*text)[*i+1] == codeAsterisk { // multi-line comment
-			// repair block comment by skipping it
-			for *i < len(*text) && !atEndOfBlockComment(text, i) {
-				*i++
-			}
-			if *i+2 <= len(*text) {
-				*i += 2 // move past the end of the block comment
-			}
-			return true
-		} else if (*text)[*i] == codeSlash && (*text)[*i+1] == codeSlash { // single-line comment
-			// repair line comment by skipping it
-			for *i < len(*text) && (*text)[*i] != codeNewline {
-				*i++
-			}
-			return true
+func parseComment(c *cursor, opts *options) bool {
+	if opts.hashComments && !c.done() && c.peek(0) == codeHash { // hash line comment
+		// repair Python, YAML or shell style line comment by skipping it
+		for !c.done() && c.peek(0) != codeNewline {
+			c.next()
+		}
+		return true
+	}
+	if atHTMLCommentStart(c) { // HTML comment
+		// repair HTML comment by skipping it
+		for !c.done() && !c.hasPrefix("-->") {
+			c.next()
+		}
+		c.skip(3) // move past the end of the HTML comment
+		return true
+	}
+	if c.peek(0) == codeSlash && c.peek(1) == codeAsterisk { // multi-line comment
+		// repair block comment by skipping it
+		for !c.done() && !atEndOfBlockComment(c) {
+			c.next()
+		}
+		c.skip(2) // move past the end of the block comment
+		return true
+	} else if c.peek(0) == codeSlash && c.peek(1) == codeSlash { // single-line comment
+		// repair line comment by skipping it
+		for !c.done() && c.peek(0) != codeNewline {
+			c.next()
 		}
+		return true
 	}
 	return false
 }
 
 // parseCharacter parses a specific character and adds it to the output if it matches the expected code.
-func parseCharacter(text *[]rune, i *int, output *strings.Builder, code rune) bool {
-	if *i < len(*text) && (*text)[*i] == code {
-		output.WriteRune((*text)[*i])
-		*i++
+func parseCharacter(c *cursor, output *strings.Builder, code rune) bool {
+	if !c.done() && c.peek(0) == code {
+		output.WriteRune(c.peek(0))
+		c.next()
 		return true
 	}
 	return false
 }
 
 // skipCharacter skips a specific character in the input text if it matches the expected code.
-func skipCharacter(text *[]rune, i *int, code rune) bool {
-	if *i < len(*text) && (*text)[*i] == code {
-		*i++
+func skipCharacter(c *cursor, code rune) bool {
+	if !c.done() && c.peek(0) == code {
+		c.next()
 		return true
 	}
 	return false
 }
 
 // skipEscapeCharacter skips an escape character in the input text.
-func skipEscapeCharacter(text *[]rune, i *int) bool {
-	return skipCharacter(text, i, codeBackslash)
+func skipEscapeCharacter(c *cursor) bool {
+	return skipCharacter(c, codeBackslash)
 }
 
-// skipEllipsis skips ellipsis (three dots) in arrays or objects.
-func skipEllipsis(text *[]rune, i *int, output *strings.Builder) bool {
-	parseWhitespaceAndSkipComments(text, i, output)
+// parseEllipsis repairs an ellipsis (three dots) in arrays or objects according to the ellipsis
+// policy. It returns true when the ellipsis was replaced with a placeholder, which is an item
+// of an array, or a member with the key "..." of an object when isObject is set.
+func parseEllipsis(c *cursor, output *strings.Builder, isObject bool, opts *options) bool {
+	parseWhitespaceAndSkipComments(c, output, opts)
 
-	if *i+2 < len(*text) &&
-		(*text)[*i] == codeDot &&
-		(*text)[*i+1] == codeDot &&
-		(*text)[*i+2] == codeDot {
-		*i += 3
-		parseWhitespaceAndSkipComments(text, i, output)
-		skipCharacter(text, i, codeComma)
+	if !c.hasPrefix("...") {
+		return false
+	}
+	start := c.pos
+	switch opts.ellipsisPolicy {
+	case EllipsisError:
+		opts.fail(newError(ErrTruncated, "", opts.offset+start))
+		return false
+	case EllipsisPlaceholder:
+		c.skip(3)
+		if isObject {
+			output.WriteString(`"...": `)
+		}
+		output.WriteString(opts.ellipsisPlaceholder)
+		logRepair(start, output, "replaced ellipsis with placeholder", opts)
+		parseWhitespaceAndSkipComments(c, output, opts)
 		return true
 	}
+	c.skip(3)
+	parseWhitespaceAndSkipComments(c, output, opts)
+	skipCharacter(c, codeComma)
+	logRepair(start, output, "removed ellipsis", opts)
 	return false
 }
 
 // parseObject parses an object from the input text.
-func parseObject(text *[]rune, i *int, output *strings.Builder) bool {
-	if *i < len(*text) && (*text)[*i] == codeOpeningBrace {
-		output.WriteRune((*text)[*i])
-		*i++
-		parseWhitespaceAndSkipComments(text, i, output)
-
-		// repair: skip leading comma like in {, message: "hi"}
-		if skipCharacter(text, i, codeComma) {
-			parseWhitespaceAndSkipComments(text, i, output)
-		}
-
-		initial := true
-		for *i < len(*text) && (*text)[*i] != codeClosingBrace {
-			var processedComma bool
-			if !initial {
-				processedComma = parseCharacter(text, i, output, codeComma)
-				if !processedComma {
-					// repair missing comma
-					outputStr := insertBeforeLastWhitespace(output.String(), ",")
-					output.Reset()
-					output.WriteString(outputStr)
+func parseObject(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.done() || c.peek(0) != codeOpeningBrace {
+		return false
+	}
+
+	parse := func() bool {
+		c.next()
+		return parseObjectMembers(c, output, codeClosingBrace, opts)
+	}
+	// a struct printed with %v or %+v, like {Name:John Age:30}, is only taken as a Go
+	// literal when it does not parse as an object
+	if opts.goSyntax && !opts.goLiteral &&
+		(tryParse(c, output, opts, parse) || parseGoLiteral(c, output, opts, parse)) {
+		return true
+	}
+	return parse()
+}
+
+// parseObjectMembers parses the members of an object up to and including the closing character.
+func parseObjectMembers(c *cursor, output *strings.Builder, closing rune, opts *options) bool {
+	if !opts.enter(c.pos - 1) {
+		return false
+	}
+	defer opts.leave()
+
+	output.WriteRune(codeOpeningBrace)
+	parseWhitespaceAndSkipComments(c, output, opts)
+
+	// repair: skip leading comma like in {, message: "hi"}
+	if skipCharacter(c, codeComma) {
+		parseWhitespaceAndSkipComments(c, output, opts)
+	}
+
+	arrows := opts.arrows
+	opts.arrows = true
+	defer func() { opts.arrows = arrows }()
+
+	initial := true
+	last := -1
+	// the keys of the object, to warn about repeated keys and to add the defaults of the schema
+	var keys map[string]bool
+	schema := opts.schema
+	for !c.done() && c.peek(0) != closing && progressed(&last, c.pos, opts) {
+		memberStart, firstMember := output.Len(), initial
+		var processedComma bool
+		if !initial {
+			processedComma = parseCharacter(c, output, codeComma) || parseSemicolonSeparator(c, output, opts) ||
+				parseCustomDelimiter(c, output, opts)
+			if !processedComma {
+				// repair missing comma, which is inserted before the whitespace of the member
+				memberStart = len(strings.TrimRightFunc(output.String(), isWhitespace))
+				outputStr := insertBeforeLastWhitespace(output.String(), ",")
+				output.Reset()
+				output.WriteString(outputStr)
+				logRepair(c.pos, output, "added missing comma", opts)
+			}
+			parseWhitespaceAndSkipComments(c, output, opts)
+		} else {
+			processedComma = true
+			initial = false
+		}
+
+		if parseEllipsis(c, output, true, opts) {
+			continue
+		}
+
+		keyStart, keyPosition := output.Len(), c.pos
+		processedKey := parsePartiallyQuotedKey(c, output, opts) ||
+			parseString(c, output, false, opts) ||
+			parseRubySymbol(c, output, opts) ||
+			parseUnquotedString(c, output, true, opts)
+		if !processedKey {
+			if c.done() ||
+				c.peek(0) == codeClosingBrace ||
+				c.peek(0) == codeOpeningBrace ||
+				c.peek(0) == codeClosingBracket ||
+				c.peek(0) == codeOpeningBracket ||
+				c.peek(0) == 0 {
+				// repair trailing comma
+				outputStr := stripLastOccurrence(output.String(), ",", false)
+				stripped := len(outputStr) < output.Len()
+				output.Reset()
+				output.WriteString(outputStr)
+				if stripped {
+					logRepair(c.pos, output, "removed trailing comma", opts)
 				}
-				parseWhitespaceAndSkipComments(text, i, output)
+				break
 			} else {
-				processedComma = true
-				initial = false
-			}
-
-			skipEllipsis(text, i, output)
-
-			processedKey := parseString(text, i, output, false) || parseUnquotedString(text, i, output)
-			if !processedKey {
-				if *i >= len(*text) ||
-					(*text)[*i] == codeClosingBrace ||
-					(*text)[*i] == codeOpeningBrace ||
-					(*text)[*i] == codeClosingBracket ||
-					(*text)[*i] == codeOpeningBracket ||
-					(*text)[*i] == 0 {
-					// repair trailing comma
-					outputStr := stripLastOccurrence(output.String(), ",", false)
-					output.Reset()
-					output.WriteString(outputStr)
-					break
-				} else {
-					// throwObjectKeyExpected() equivalent
-					return false
-				}
+				// throwObjectKeyExpected() equivalent
+				return false
 			}
+		}
 
-			parseWhitespaceAndSkipComments(text, i, output)
-			processedColon := parseCharacter(text, i, output, codeColon)
-			truncatedText := *i >= len(*text)
-			if !processedColon {
-				if *i < len(*text) && isStartOfValue((*text)[*i]) || truncatedText {
-					// repair missing colon
-					outputStr := insertBeforeLastWhitespace(output.String(), ":")
-					output.Reset()
-					output.WriteString(outputStr)
+		transformKey(output, keyStart, opts)
+		key := strings.TrimSpace(output.String()[keyStart:])
+		if opts.warnings != nil || schema != nil {
+			if keys == nil {
+				keys = make(map[string]bool)
+			}
+			if keys[key] && opts.warnings != nil {
+				warn(keyPosition, warningDuplicateKey, "", opts)
+			}
+			keys[key] = true
+		}
+		member := schema.property(key)
+
+		parseWhitespaceAndSkipComments(c, output, opts)
+		processedColon := parseCharacter(c, output, codeColon)
+		if processedColon && opts.equalsSeparator && c.peek(0) == codeEqual && !atArrow(c) {
+			// repair Go style ":=" separator by skipping the equals sign
+			c.next()
+			logRepair(c.pos-1, output, "replaced colon equals with colon", opts)
+		}
+		if !processedColon && skipArrow(c) {
+			// repair Ruby hash rocket: replace "=>" with a colon
+			output.WriteRune(codeColon)
+			logRepair(c.pos-2, output, "replaced arrow with colon", opts)
+			processedColon = true
+		}
+		if !processedColon && opts.equalsSeparator && skipCharacter(c, codeEqual) {
+			// repair key=value pair: replace "=" with a colon
+			output.WriteRune(codeColon)
+			logRepair(c.pos-1, output, "replaced equals sign with colon", opts)
+			processedColon = true
+		}
+		if !processedColon && !c.done() && (c.peek(0) == codeComma || c.peek(0) == closing) {
+			// repair key without value, like the shorthand {a, b} or a truncated {"flag"}
+			if opts.dropBareKeys {
+				outputStr := output.String()
+				output.Reset()
+				if firstMember {
+					output.WriteString(outputStr[:keyStart])
+					skipCharacter(c, codeComma)
+					parseWhitespaceAndSkipComments(c, output, opts)
+					initial = true
 				} else {
-					// throwColonExpected() equivalent
-					return false
+					output.WriteString(outputStr[:memberStart])
 				}
+				logRepair(c.pos, output, "removed key without value", opts)
+			} else {
+				outputStr := insertBeforeLastWhitespace(output.String(), ": "+member.missingValue())
+				output.Reset()
+				output.WriteString(outputStr)
+				logRepair(c.pos, output, "added missing value", opts)
+			}
+			continue
+		}
+		truncatedText := c.done()
+		if !processedColon {
+			if !c.done() && isStartOfValue(c.peek(0)) || truncatedText {
+				// repair missing colon
+				outputStr := insertBeforeLastWhitespace(output.String(), ":")
+				output.Reset()
+				output.WriteString(outputStr)
+				logRepair(c.pos, output, "added missing colon", opts)
+			} else {
+				// throwColonExpected() equivalent
+				return false
 			}
+		}
 
-			processedValue := parseValue(text, i, output)
-			if !processedValue {
-				if processedColon || truncatedText {
-					// repair missing object value
-					output.WriteString("null")
-				} else {
-					// throwColonExpected() equivalent
-					return false
-				}
+		parseWhitespaceAndSkipComments(c, output, opts)
+		valueStart, valuePos := output.Len(), c.pos
+		parent := enterMember(key, opts)
+		opts.schema = member
+		processedValue := parseValue(c, output, opts)
+		opts.key, opts.schema = parent, schema
+		if opts.err != nil {
+			// the repair failed, its output is discarded
+			return false
+		}
+		if processedValue && opts.nestedKeys != nil {
+			repairNestedString(output, key, valueStart, valuePos, opts)
+		}
+		if !processedValue {
+			if processedColon || truncatedText {
+				// repair missing object value
+				output.WriteString(member.missingValue())
+				logRepair(c.pos, output, "added missing value", opts)
+			} else {
+				// throwColonExpected() equivalent
+				return false
 			}
 		}
+	}
 
-		if *i < len(*text) && (*text)[*i] == codeClosingBrace {
-			output.WriteRune((*text)[*i])
-			*i++
-		} else {
-			// repair missing end bracket
-			outputStr := insertBeforeLastWhitespace(output.String(), "}")
-			output.Reset()
-			output.WriteString(outputStr)
+	if !c.done() && c.peek(0) == closing {
+		output.WriteRune(codeClosingBrace)
+		c.next()
+	} else {
+		// repair missing end bracket
+		defaults := schemaDefaults(schema, keys, len(keys) > 0)
+		outputStr := insertBeforeLastWhitespace(output.String(), defaults+"}")
+		output.Reset()
+		output.WriteString(outputStr)
+		if defaults != "" {
+			logRepair(c.pos, output, "added schema default", opts)
+		}
+		logRepair(c.pos, output, "added missing closing brace", opts)
+	}
+	return true
+}
+
+// parsePartiallyQuotedKey parses a key with a dropped or misplaced quote, like na"me or "na"me
+// followed by a colon, and turns it into a single key without the quotes, instead of splitting
+// it into separate members. A key which starts with a quote needs a second quote in the middle,
+// so that a quoted key with a missing end quote like "http://a.com is left to parseString.
+func parsePartiallyQuotedKey(c *cursor, output *strings.Builder, opts *options) bool {
+	j := *c
+	quotes := 0
+	for !j.done() && !isDelimiter(j.peek(0)) && !isWhitespace(j.peek(0)) && !isSpecialWhitespace(j.peek(0)) &&
+		!isCustomDelimiter(j.peek(0), opts) && !isCustomWhitespace(j.peek(0), opts) &&
+		!(opts.markdown && j.hasPrefix("**")) {
+		if j.peek(0) == codeBackslash {
+			return false
+		}
+		if isDoubleQuoteLike(j.peek(0)) {
+			quotes++
 		}
+		j.next()
+	}
+	key := c.slice(c.pos, j.pos)
+	if quotes == 0 || isQuote(key[0]) && (quotes < 2 || isQuote(key[len(key)-1])) {
+		return false
+	}
+	end := j.pos
+	skipWhitespace(&j)
+	if j.peek(0) != codeColon || !atValidValue(cursor{text: c.text, pos: j.pos + 1}, opts) {
+		return false
+	}
+
+	// repair: remove the quotes and quote the key as a whole
+	name := strings.Builder{}
+	for _, char := range key {
+		if !isDoubleQuoteLike(char) {
+			name.WriteRune(char)
+		}
+	}
+	writeQuoted(output, name.String())
+	logRepair(c.pos, output, "reunited partially quoted key", opts)
+	c.pos = end
+	return true
+}
+
+// atValidValue checks if the text at the cursor repairs to a valid JSON value, like the value
+// after the colon of a partially quoted key. The repairs are not made.
+func atValidValue(c cursor, opts *options) bool {
+	annotate := opts.annotate
+	opts.annotate = false
+	defer func() { opts.annotate = annotate }()
+
+	valid := false
+	var output strings.Builder
+	tryParse(&c, &output, opts, func() bool {
+		valid = parseValue(&c, &output, opts) && opts.err == nil && isValidJSON(output.String())
+		return false
+	})
+	return valid
+}
+
+// parseArray parses an array from the input text.
+func parseArray(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.done() {
+		return false
+	}
+
+	if c.peek(0) == codeOpeningBracket {
+		c.next()
+		parseArrayItems(c, output, codeClosingBracket, opts)
 		return true
 	}
 	return false
 }
 
-// parseArray parses an array from the input text.
-func parseArray(text *[]rune, i *int, output *strings.Builder) bool {
-	if *i >= len(*text) {
+// parsePHPArray parses a PHP array like array(1, 2) or array('a' => 1) from the input text.
+func parsePHPArray(c *cursor, output *strings.Builder, opts *options) bool {
+	keyword := "array"
+	if !c.hasPrefix(keyword) {
+		return false
+	}
+
+	j := *c
+	j.skip(len(keyword))
+	skipWhitespace(&j)
+	if j.peek(0) != codeOpenParenthesis {
+		return false
+	}
+
+	// repair PHP array: replace array(...) with brackets
+	logRepair(c.pos, output, "replaced php array with brackets", opts)
+	c.pos = j.pos + 1
+	parseArrayItems(c, output, codeCloseParenthesis, opts)
+	return true
+}
+
+// parseSemicolonSeparator replaces a semicolon separating items, like in {a:1; b:2}, with a comma.
+func parseSemicolonSeparator(c *cursor, output *strings.Builder, opts *options) bool {
+	if !skipCharacter(c, codeSemicolon) {
 		return false
 	}
+	output.WriteRune(codeComma)
+	logRepair(c.pos-1, output, "replaced semicolon with comma", opts)
+	return true
+}
 
-	if (*text)[*i] == codeOpeningBracket {
-		output.WriteRune((*text)[*i])
-		*i++
-		parseWhitespaceAndSkipComments(text, i, output)
+// parseCustomDelimiter replaces a custom delimiter separating items, see WithDelimiters, with a comma.
+func parseCustomDelimiter(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.done() || !isCustomDelimiter(c.peek(0), opts) {
+		return false
+	}
+	c.next()
+	output.WriteRune(codeComma)
+	logRepair(c.pos-1, output, "replaced delimiter with comma", opts)
+	return true
+}
 
-		if skipCharacter(text, i, codeComma) {
-			parseWhitespaceAndSkipComments(text, i, output)
+// parseEmptyArraySlots repairs the holes of a sparse array like [1,,2], which JavaScript
+// accepts. Every empty slot becomes null, or is dropped when using WithDropEmptyArraySlots.
+// A comma followed by a quoted key is left, as the array is missing its closing bracket then.
+func parseEmptyArraySlots(c *cursor, output *strings.Builder, opts *options) {
+	parseWhitespaceAndSkipComments(c, output, opts)
+	for !c.done() && c.peek(0) == codeComma {
+		j := cursor{text: c.text, pos: c.pos + 1}
+		skipWhitespace(&j)
+		if atQuotedKey(&j) {
+			return
+		}
+		if opts.dropEmptySlots {
+			c.next()
+			logRepair(c.pos-1, output, "removed empty array slot", opts)
+		} else {
+			output.WriteString("null")
+			logRepair(c.pos, output, "replaced empty array slot with null", opts)
+			parseCharacter(c, output, codeComma)
 		}
+		parseWhitespaceAndSkipComments(c, output, opts)
+	}
+}
 
-		initial := true
-		for *i < len(*text) && (*text)[*i] != codeClosingBracket {
-			if !initial {
-				processedComma := parseCharacter(text, i, output, codeComma)
-				if !processedComma {
-					outputStr := insertBeforeLastWhitespace(output.String(), ",")
-					output.Reset()
-					output.WriteString(outputStr)
-				}
+// parseArrayItems parses the items of an array up to and including the closing character.
+// When the items are PHP style "key => value" pairs, the array is turned into an object.
+func parseArrayItems(c *cursor, output *strings.Builder, closing rune, opts *options) {
+	if !opts.enter(c.pos - 1) {
+		return
+	}
+	defer opts.leave()
+
+	start := output.Len()
+	output.WriteRune(codeOpeningBracket)
+	parseWhitespaceAndSkipComments(c, output, opts)
+
+	if skipCharacter(c, codeComma) {
+		parseWhitespaceAndSkipComments(c, output, opts)
+	}
+
+	initial := true
+	isObject := false
+	var items []arrayItem
+	last := -1
+	schema, arrows := opts.schema, opts.arrows
+	defer func() { opts.schema, opts.arrows = schema, arrows }()
+	opts.schema, opts.arrows = schema.itemSchema(), true
+	for !c.done() && c.peek(0) != closing && progressed(&last, c.pos, opts) {
+		if !initial {
+			processedComma := parseCharacter(c, output, codeComma) || parseSemicolonSeparator(c, output, opts) ||
+				parseCustomDelimiter(c, output, opts)
+			if !processedComma {
+				outputStr := insertBeforeLastWhitespace(output.String(), ",")
+				output.Reset()
+				output.WriteString(outputStr)
+				logRepair(c.pos, output, "added missing comma", opts)
 			} else {
-				initial = false
+				parseEmptyArraySlots(c, output, opts)
 			}
+		} else {
+			initial = false
+		}
+
+		if parseEllipsis(c, output, false, opts) {
+			continue
+		}
+
+		valueStart, valuePos := output.Len(), c.pos
+		processedValue := parseValue(c, output, opts)
+		if opts.err != nil {
+			// the repair failed, its output is discarded
+			return
+		}
 
-			skipEllipsis(text, i, output)
+		if !processedValue {
+			// repair trailing comma
+			outputStr := stripLastOccurrence(output.String(), ",", false)
+			stripped := len(outputStr) < output.Len()
+			output.Reset()
+			output.WriteString(outputStr)
+			if stripped {
+				logRepair(c.pos, output, "removed trailing comma", opts)
+			}
+			break
+		}
+
+		if !skipArrow(c) {
+			items = append(items, arrayItem{position: valuePos, start: valueStart})
+		} else {
+			// repair PHP array key: the value was a key, turn the array into an object
+			isObject = true
+			outputStr := output.String()
+			key := outputStr[valueStart:]
+			trimmedKey := strings.TrimRightFunc(key, isWhitespace)
+			if !strings.HasPrefix(trimmedKey, "\"") {
+				key = fmt.Sprintf(`"%s"`, trimmedKey) + key[len(trimmedKey):]
+			}
+			name, _, _ := strings.Cut(strings.TrimLeftFunc(key, isWhitespace)[1:], `"`)
+			items = append(items, arrayItem{start: -1, key: name})
+			output.Reset()
+			output.WriteString(outputStr[:valueStart] + key)
+			transformKey(output, valueStart, opts)
+			output.WriteRune(codeColon)
+			logRepair(c.pos-2, output, "replaced arrow with colon", opts)
+
+			if !parseValue(c, output, opts) {
+				// repair missing value
+				output.WriteString("null")
+				logRepair(c.pos, output, "added missing value", opts)
+			}
+		}
+	}
+
+	closingOutput := "]"
+	if isObject {
+		closingOutput = "}"
+		addIndexKeys(output, items, opts)
+		outputStr := output.String()
+		output.Reset()
+		output.WriteString(outputStr[:start] + "{" + outputStr[start+1:])
+	}
+
+	if !c.done() && c.peek(0) == closing {
+		output.WriteString(closingOutput)
+		c.next()
+	} else {
+		// repair missing closing array bracket
+		outputStr := insertBeforeLastWhitespace(output.String(), closingOutput)
+		output.Reset()
+		output.WriteString(outputStr)
+		logRepair(c.pos, output, "added missing closing bracket", opts)
+	}
+}
 
-			processedValue := parseValue(text, i, output)
+// arrayItem is an item of a PHP array: a keyed item with its key and a start of -1, or an item
+// without key, which starts at position in the text and at start in the output.
+type arrayItem struct {
+	position, start int
+	key             string
+}
 
-			if !processedValue {
-				// repair trailing comma
-				outputStr := stripLastOccurrence(output.String(), ",", false)
-				output.Reset()
-				output.WriteString(outputStr)
-				break
+// addIndexKeys adds the keys of the items without key of a PHP array which mixes keyed items and
+// items without key, like [0 => 'x', 1]. Like PHP, an item gets the integer index after the
+// largest integer key before it.
+func addIndexKeys(output *strings.Builder, items []arrayItem, opts *options) {
+	keys := make([]string, len(items))
+	next := 0
+	for k, item := range items {
+		if item.start < 0 {
+			if index, err := strconv.Atoi(item.key); err == nil && index >= next {
+				next = index + 1
 			}
+			continue
 		}
+		keys[k] = strconv.Itoa(next)
+		next++
+	}
 
-		if *i < len(*text) && (*text)[*i] == codeClosingBracket {
-			output.WriteRune((*text)[*i])
-			*i++
-		} else {
-			// repair missing closing array bracket
-			outputStr := insertBeforeLastWhitespace(output.String(), "]")
-			output.Reset()
-			output.WriteString(outputStr)
+	outputStr := output.String()
+	shift := 0
+	for k, item := range items {
+		if item.start < 0 {
+			continue
 		}
-		return true
+		// log the repair on the output up to the key, so an annotation follows the key
+		keyed := strings.Builder{}
+		keyed.WriteString(outputStr[:item.start+shift] + `"` + keys[k] + `": `)
+		logRepair(item.position, &keyed, "added array index key", opts)
+		length := len(outputStr)
+		outputStr = keyed.String() + outputStr[item.start+shift:]
+		shift += len(outputStr) - length
 	}
-	return false
+	output.Reset()
+	output.WriteString(outputStr)
 }
 
 // parseNewlineDelimitedJSON parses Newline Delimited JSON (NDJSON) from the input text.
-func parseNewlineDelimitedJSON(text *[]rune, i *int, output *strings.Builder) {
+func parseNewlineDelimitedJSON(c *cursor, output *strings.Builder, opts *options) {
 	initial := true
 	processedValue := true
 
-	for processedValue {
+	last := -1
+	for processedValue && progressed(&last, c.pos, opts) {
 		if !initial {
 			// parse optional comma, insert when missing
-			processedComma := parseCharacter(text, i, output, codeComma)
+			processedComma := parseCharacter(c, output, codeComma)
 			if !processedComma {
 				// repair: add missing comma
 				outputStr := insertBeforeLastWhitespace(output.String(), ",")
@@ -326,7 +1055,7 @@ func parseNewlineDelimitedJSON(text *[]rune, i *int, output *strings.Builder) {
 			initial = false
 		}
 
-		processedValue = parseValue(text, i, output)
+		processedValue = parseValue(c, output, opts)
 	}
 
 	if !processedValue {
@@ -340,23 +1069,112 @@ func parseNewlineDelimitedJSON(text *[]rune, i *int, output *strings.Builder) {
 	outputStr := fmt.Sprintf("[\n%s\n]", output.String())
 	output.Reset()
 	output.WriteString(outputStr)
+	logRepair(c.pos, output, "wrapped newline delimited values in an array", opts)
+}
+
+// skipReturnKeyword skips a return keyword in front of the value, like in return {...};
+// copied from a function body.
+func skipReturnKeyword(c *cursor, output *strings.Builder, opts *options) bool {
+	parseWhitespaceAndSkipComments(c, output, opts)
+	start := c.pos
+	j := *c
+	if !atWord(&j, "return") {
+		return false
+	}
+	j.skip(len("return"))
+	skipWhitespace(&j)
+	if j.done() || j.peek(0) == codeSemicolon || j.peek(0) == codeColon {
+		return false
+	}
+
+	// repair: remove the return keyword
+	c.pos = j.pos
+	logRepair(start, output, "removed return keyword", opts)
+	return true
+}
+
+// skipVariableAssignment skips a JavaScript variable assignment in front of the value, like
+// const data = {...}, var x = [...] or module.exports = {...}. Without a declaration keyword,
+// only an assignment of an object or array is skipped, so that text like "a = b" is kept.
+func skipVariableAssignment(c *cursor, output *strings.Builder, opts *options) bool {
+	parseWhitespaceAndSkipComments(c, output, opts)
+	start := c.pos
+	j := *c
+
+	if atWord(&j, "export") {
+		j.skip(len("export"))
+		skipWhitespace(&j)
+		if atWord(&j, "default") {
+			// repair: remove export default, when a value follows
+			j.skip(len("default"))
+			skipWhitespace(&j)
+			if j.done() {
+				return false
+			}
+			c.pos = j.pos
+			logRepair(start, output, "removed variable assignment", opts)
+			return true
+		}
+	}
+
+	declared := false
+	for _, keyword := range []string{"const", "let", "var"} {
+		if atWord(&j, keyword) {
+			j.skip(len(keyword))
+			skipWhitespace(&j)
+			declared = true
+			break
+		}
+	}
+
+	if j.done() || !isSymbolStart(j.peek(0)) {
+		return false
+	}
+	for isSymbolChar(j.peek(0)) || j.peek(0) == codeDot || j.peek(0) == codeDollar {
+		j.next()
+	}
+	skipWhitespace(&j)
+	if j.peek(0) != codeEqual || atArrow(&j) || j.peek(1) == codeEqual {
+		return false
+	}
+	j.next()
+	skipWhitespace(&j)
+	if j.done() || j.peek(0) == codeComma || j.peek(0) == codeSemicolon ||
+		!declared && j.peek(0) != codeOpeningBrace && j.peek(0) != codeOpeningBracket {
+		return false
+	}
+
+	// repair: remove the variable assignment
+	c.pos = j.pos
+	logRepair(start, output, "removed variable assignment", opts)
+	return true
 }
 
 // parseString parses a string from the input text, handling various quote and escape scenarios.
-func parseString(text *[]rune, i *int, output *strings.Builder, stopAtDelimiter bool) bool {
-	if *i >= len(*text) {
+func parseString(c *cursor, output *strings.Builder, stopAtDelimiter bool, opts *options) bool {
+	if c.done() {
 		return false
 	}
 
-	skipEscapeChars := (*text)[*i] == codeBackslash
+	if parseTripleQuotedString(c, output, opts) {
+		return true
+	}
+
+	skipEscapeChars := c.peek(0) == codeBackslash
 	if skipEscapeChars {
-		*i++
+		c.next()
 	}
 
-	if isQuote((*text)[*i]) {
+	// an HTML entity like &quot; forming the quotes of the string
+	entityQuote := atQuoteEntity(c, opts) > 0
+
+	if isQuote(c.peek(0)) || isReplacementQuote(c.peek(0), opts) || entityQuote {
 		var isEndQuote func(rune) bool
 
-		startQuote := (*text)[*i]
+		startQuote := c.peek(0)
+		if entityQuote {
+			startQuote = codeDoubleQuote
+		}
 		isEndQuote = func(code rune) bool {
 			switch startQuote {
 			case codeDoubleQuote:
@@ -367,64 +1185,122 @@ func parseString(text *[]rune, i *int, output *strings.Builder, stopAtDelimiter
 				return isDoubleQuoteLike(code)
 			case codeQuoteLeft, codeQuoteRight, codeGraveAccent, codeAcuteAccent:
 				return isSingleQuoteLike(code)
+			case codeReplacementCharacter:
+				// a smart quote which got lost in a broken encoding
+				return code == codeReplacementCharacter || isDoubleQuoteLike(code)
 			default:
 				return code == startQuote
 			}
 		}
 
-		iBefore := *i
+		iBefore := c.pos
 		oBefore := output.Len()
 
+		// endQuoteLength returns the length of the end quote at the current position, or 0
+		endQuoteLength := func() int {
+			if entityQuote {
+				if length := atQuoteEntity(c, opts); length > 0 {
+					return length
+				}
+			}
+			if isEndQuote(c.peek(0)) {
+				return 1
+			}
+			return 0
+		}
+
 		str := strings.Builder{}
 		str.WriteRune('"')
-		*i++
+		if entityQuote {
+			logRepair(c.pos, output, "decoded html entity", opts)
+			c.skip(atQuoteEntity(c, opts))
+		} else {
+			c.next()
+		}
+
+		// the control characters of the string are reported once the string is complete, since
+		// the string may be parsed again
+		controlPos := -1
+		endString := func() bool {
+			if controlPos >= 0 {
+				logRepair(controlPos, output, "escaped control character", opts)
+			}
+			return true
+		}
 
+		// the first delimiter in the string, where the string ends when its end quote is missing
+		firstDelimiter := -1
 		for {
-			if *i >= len(*text) {
+			if opts.canceled() {
+				return false
+			}
+			if opts.maxStringScan > 0 && !stopAtDelimiter && !c.done() {
+				if firstDelimiter < 0 && isDelimiter(c.peek(0)) {
+					firstDelimiter = c.pos
+				}
+				if firstDelimiter >= 0 && c.pos-firstDelimiter > opts.maxStringScan && endQuoteLength() == 0 {
+					// no end quote within the scan limit after the first delimiter: the end quote is
+					// considered missing, retry parsing the string, stopping at the first next delimiter
+					c.pos = iBefore
+					tempOutput := output.String()[:oBefore]
+					output.Reset()
+					output.WriteString(tempOutput)
+					return parseString(c, output, true, opts)
+				}
+			}
+			if c.done() {
 				// end of text, we are missing an end quote
 
-				iPrev := prevNonWhitespaceIndex(*text, *i-1)
-				if !stopAtDelimiter && isDelimiter((*text)[iPrev]) {
+				iPrev := prevNonWhitespaceIndex(c.text, c.pos-1)
+				if !stopAtDelimiter && isDelimiter(c.at(iPrev)) {
 					// if the text ends with a delimiter, like ["hello],
 					// so the missing end quote should be inserted before this delimiter
 					// retry parsing the string, stopping at the first next delimiter
-					*i = iBefore
+					c.pos = iBefore
 					tempOutput := output.String()[:oBefore]
 					output.Reset()
 					output.WriteString(tempOutput)
-					return parseString(text, i, output, true)
+					return parseString(c, output, true, opts)
 				}
 
 				// repair missing quote
 				output.WriteString(insertBeforeLastWhitespace(str.String(), "\""))
-				return true
-			} else if isEndQuote((*text)[*i]) {
+				logRepair(c.pos, output, "added missing end quote", opts)
+				return endString()
+			} else if quoteLength := endQuoteLength(); quoteLength > 0 {
 				// end quote
 				// let us check what is before and after the quote to verify whether this is a legit end quote
-				iQuote := *i
+				iQuote := c.pos
 				oQuote := str.Len()
 				str.WriteRune('"')
-				*i++
+				c.skip(quoteLength)
 				output.WriteString(str.String())
 
-				parseWhitespaceAndSkipComments(text, i, output)
+				parseWhitespaceAndSkipComments(c, output, opts)
 
-				if stopAtDelimiter || *i >= len(*text) || isDelimiter((*text)[*i]) || isQuote((*text)[*i]) || isDigit((*text)[*i]) {
+				// a quote preceded by a delimiter is only the end quote when a delimiter follows too,
+				// so a semicolon after it, like in {"c: [1, {";": "e"}]}, does not end the string
+				afterDelimiter := isDelimiter(c.at(prevNonWhitespaceIndex(c.text, iQuote-1)))
+				if stopAtDelimiter || c.done() || isDelimiter(c.peek(0)) || isQuote(c.peek(0)) || isDigit(c.peek(0)) || opts.arrows && atArrow(c) ||
+					opts.equalsSeparator && c.peek(0) == codeEqual || !afterDelimiter && atSemicolonBeforeQuote(c) || isCustomDelimiter(c.peek(0), opts) {
 					// The quote is followed by the end of the text, a delimiter, or a next value
 					// so the quote is indeed the end of the string
-					parseConcatenatedString(text, i, output)
-					return true
+					parseConcatenatedString(c, output, opts)
+					if startQuote != codeDoubleQuote {
+						logRepair(iBefore, output, "replaced quotes with double quotes", opts)
+					}
+					return endString()
 				}
 
-				if isDelimiter((*text)[prevNonWhitespaceIndex(*text, iQuote-1)]) {
+				if afterDelimiter {
 					// This is not the right end quote: it is preceded by a delimiter,
 					// and NOT followed by a delimiter. So, there is an end quote missing
 					// parse the string again and then stop at the first next delimiter
-					*i = iBefore
+					c.pos = iBefore
 					tempOutput := output.String()[:oBefore]
 					output.Reset()
 					output.WriteString(tempOutput)
-					return parseString(text, i, output, true)
+					return parseString(c, output, true, opts)
 				}
 
 				// revert to right after the quote but before any whitespace, and continue parsing the string
@@ -433,7 +1309,7 @@ func parseString(text *[]rune, i *int, output *strings.Builder, stopAtDelimiter
 					output.Reset()
 					output.WriteString(tempOutput[:oBefore])
 				}
-				*i = iQuote + 1
+				c.pos = iQuote + quoteLength
 
 				// repair unescaped quote
 				if oQuote <= str.Len() {
@@ -443,90 +1319,191 @@ func parseString(text *[]rune, i *int, output *strings.Builder, stopAtDelimiter
 					str.WriteRune('\\')
 					str.WriteString(tempStr[oQuote:])
 				}
-			} else if stopAtDelimiter && isDelimiter((*text)[*i]) {
+			} else if opts.stringNewlinePolicy == StringNewlineEnd && (c.peek(0) == codeNewline || c.peek(0) == codeReturn) {
+				// repair missing quote: a newline ends the string
+				output.WriteString(str.String() + "\"")
+				logRepair(c.pos, output, "added missing end quote", opts)
+				return endString()
+			} else if stopAtDelimiter && isDelimiter(c.peek(0)) &&
+				!(opts.stringNewlinePolicy == StringNewlineEscape && c.peek(0) == codeNewline) {
 				// we're in the mode to stop the string at the first delimiter
 				// because there is an end quote missing
 
+				// a URL like "https://... is not cut at its colon and slashes
+				if j := (cursor{text: c.text, pos: iBefore + 1}); c.peek(0) == codeColon && skipURL(&j, opts) && j.pos > c.pos {
+					str.WriteString(string(c.slice(c.pos, j.pos)))
+					c.pos = j.pos
+					continue
+				}
+
 				// repair missing quote
 				output.WriteString(insertBeforeLastWhitespace(str.String(), "\""))
-				parseConcatenatedString(text, i, output)
-				return true
-			} else if (*text)[*i] == codeBackslash {
+				logRepair(c.pos, output, "added missing end quote", opts)
+				parseConcatenatedString(c, output, opts)
+				return endString()
+			} else if c.peek(0) == codeBackslash {
 				// handle escaped content like \n or \u2605
-				if *i+1 >= len(*text) {
+				if !parseEscape(c, &str, output, opts) {
 					return false
 				}
-				char := (*text)[*i+1]
-				_, exists := escapeCharacters[char]
-				if exists {
-					str.WriteRune('\\') // different from the original code
-					str.WriteRune(char)
-					*i += 2
-				} else if char == 'u' {
-					// Handling Unicode escape sequence \uXXXX
-					j := 2
-					for j < 6 && *i+j < len(*text) && isHex((*text)[*i+j]) {
-						j++
-					}
-
-					if j == 6 {
-						// Valid Unicode escape sequence
-						unicodeStr := string((*text)[*i : *i+6])
-						str.WriteString(unicodeStr)
-						*i += 6
-					} else if *i+j >= len(*text) {
-						// repair invalid or truncated Unicode char at the end of the text
-						// by removing the Unicode char and ending the string here
-						*i = len(*text)
-					} else {
-						// repair invalid Unicode character: remove it
-						str.WriteRune('\\')
-						str.WriteRune('u')
-						*i += 2
-					}
-				} else {
-					str.WriteRune(char)
-					*i += 2
-				}
 			} else {
 				// handle regular characters
-				char := (*text)[*i]
-				code := (*text)[*i]
-				if code == codeDoubleQuote && (*text)[*i-1] != codeBackslash {
+				char := c.peek(0)
+				code := c.peek(0)
+				if decoded, length := decodeHTMLEntity(c, opts); length > 0 {
+					// repair HTML entity: replace it with the character
+					writeEscaped(&str, decoded)
+					logRepair(c.pos, output, "decoded html entity", opts)
+					c.skip(length)
+				} else if code == codeDoubleQuote && c.peek(-1) != codeBackslash {
 					// repair unescaped double quote
 					str.WriteRune('\\')
 					str.WriteRune(char)
-					*i++
+					c.next()
 				} else if isControlCharacter(code) {
 					// unescaped control character
+					if controlPos < 0 {
+						controlPos = c.pos
+					}
 					str.WriteString(controlCharacters[code])
-					*i++
+					c.next()
+				} else if code == 0 && opts.nulPolicy == NULEscape {
+					// repair NUL byte: escape it
+					str.WriteString(`\u0000`)
+					c.next()
 				} else {
 					if !isValidStringCharacter(code) {
 						return false // different from the original code
 					}
 					str.WriteRune(char)
-					*i++
+					c.next()
 				}
 			}
 			if skipEscapeChars {
 				// repair: skipped escape character (nothing to do)
-				skipEscapeCharacter(text, i)
+				skipEscapeCharacter(c)
 			}
 		}
 	}
 	return false
 }
 
+// parseEscape parses the escaped content at the backslash at the cursor, like \n or \u2605,
+// into the string str, and repairs an invalid escape. It returns false when the backslash
+// is the last character of the text.
+func parseEscape(c *cursor, str, output *strings.Builder, opts *options) bool {
+	if c.remaining() < 2 {
+		return false
+	}
+	char := c.peek(1)
+	_, exists := escapeCharacters[char]
+	if exists {
+		str.WriteRune('\\') // different from the original code
+		str.WriteRune(char)
+		c.skip(2)
+	} else if char == 'u' {
+		// Handling Unicode escape sequence \uXXXX
+		j := 2
+		for j < 6 && isHex(c.peek(j)) {
+			j++
+		}
+
+		if j == 6 && utf16.IsSurrogate(escapedCode(c, 0)) {
+			// repair lone surrogate like \ud83d without its other half
+			parseEscapedSurrogate(c, str, output, opts)
+		} else if j == 6 {
+			// Valid Unicode escape sequence
+			unicodeStr := string(c.slice(c.pos, c.pos+6))
+			str.WriteString(unicodeStr)
+			c.skip(6)
+		} else if j >= c.remaining() {
+			// repair invalid or truncated Unicode char at the end of the text
+			// by removing the Unicode char and ending the string here
+			logRepair(c.pos, output, "removed invalid escape", opts)
+			c.pos = c.len()
+		} else if escapesFilePath(c, str, output, opts) {
+			// repair backslash of a file path like C:\users: escape it
+		} else {
+			// repair invalid Unicode character: remove the backslash
+			logRepair(c.pos, output, "removed invalid escape", opts)
+			str.WriteRune('u')
+			c.skip(2)
+		}
+	} else if escapesFilePath(c, str, output, opts) {
+		// repair backslash of a file path like C:\Users: escape it
+	} else {
+		// repair invalid escape: remove the backslash
+		logRepair(c.pos, output, "removed invalid escape", opts)
+		writeEscaped(str, string(char))
+		c.skip(2)
+	}
+	return true
+}
+
+// parseTripleQuotedString parses a Python style triple quoted string like """multi-line text""",
+// or the same with single quotes, and turns it into a single JSON string.
+func parseTripleQuotedString(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.remaining() < 3 {
+		return false
+	}
+	quote := c.peek(0)
+	if (quote != codeDoubleQuote && quote != codeQuote) || c.peek(1) != quote || c.peek(2) != quote {
+		return false
+	}
+
+	start, controlPos := c.pos, -1
+	str := strings.Builder{}
+	str.WriteRune(codeDoubleQuote)
+	c.skip(3)
+	for !c.done() {
+		if c.peek(0) == quote && c.peek(1) == quote && c.peek(2) == quote {
+			c.skip(3)
+			break
+		}
+
+		char := c.peek(0)
+		switch {
+		case char == codeBackslash && c.remaining() == 1:
+			// escape a dangling backslash at the end of the text
+			str.WriteString(`\\`)
+		case char == codeBackslash:
+			parseEscape(c, &str, output, opts)
+			continue
+		case char == codeDoubleQuote:
+			// repair unescaped double quote
+			str.WriteString(`\"`)
+		case isControlCharacter(char):
+			// repair unescaped control character
+			if controlPos < 0 {
+				controlPos = c.pos
+			}
+			str.WriteString(controlCharacters[char])
+		case !isValidStringCharacter(char):
+			str.WriteString(fmt.Sprintf(`\u%04x`, char))
+		default:
+			str.WriteRune(char)
+		}
+		c.next()
+	}
+	str.WriteRune(codeDoubleQuote)
+
+	output.WriteString(str.String())
+	logRepair(start, output, "replaced quotes with double quotes", opts)
+	if controlPos >= 0 {
+		logRepair(controlPos, output, "escaped control character", opts)
+	}
+	return true
+}
+
 // parseConcatenatedString parses and repairs concatenated strings (e.g., "hello" + "world").
-func parseConcatenatedString(text *[]rune, i *int, output *strings.Builder) bool {
+func parseConcatenatedString(c *cursor, output *strings.Builder, opts *options) bool {
 	processed := false
 
-	parseWhitespaceAndSkipComments(text, i, output)
-	for *i < len(*text) && (*text)[*i] == '+' {
+	parseWhitespaceAndSkipComments(c, output, opts)
+	for !c.done() && c.peek(0) == '+' {
 		processed = true
-		*i++
-		parseWhitespaceAndSkipComments(text, i, output)
+		c.next()
+		parseWhitespaceAndSkipComments(c, output, opts)
 
 		// Repair: remove the end quote of the first string
 		outputString := output.String()
@@ -537,7 +1514,7 @@ func parseConcatenatedString(text *[]rune, i *int, output *strings.Builder) bool
 		}
 
 		start := output.Len()
-		if parseString(text, i, output, false) {
+		if parseString(c, output, false, opts) {
 			// Repair: remove the start quote of the second string
 			outputString = output.String()
 			if start < len(outputString) {
@@ -550,20 +1527,24 @@ func parseConcatenatedString(text *[]rune, i *int, output *strings.Builder) bool
 		}
 	}
 
+	if processed {
+		logRepair(c.pos, output, "concatenated strings", opts)
+	}
 	return processed
 }
 
 // parseNumber parses a number from the input text, handling various numeric formats.
-func parseNumber(text *[]rune, i *int, output *strings.Builder) bool {
-	start := *i
-	if *i < len(*text) && (*text)[*i] == codeMinus {
-		*i++
-		if atEndOfNumber(text, i) {
-			repairNumberEndingWithNumericSymbol(text, start, i, output)
+func parseNumber(c *cursor, output *strings.Builder, opts *options) bool {
+	start := c.pos
+	if !c.done() && c.peek(0) == codeMinus {
+		c.next()
+		if atEndOfNumberOrCustom(c, opts) {
+			repairNumberEndingWithNumericSymbol(c, start, output)
+			logRepair(start, output, "completed truncated number", opts)
 			return true
 		}
-		if !isDigit((*text)[*i]) {
-			*i = start
+		if !isDigit(c.peek(0)) {
+			c.pos = start
 			return false
 		}
 	}
@@ -572,125 +1553,544 @@ func parseNumber(text *[]rune, i *int, output *strings.Builder) bool {
 	// We will allow all leading zeros here though and at the end of parseNumber
 	// check against trailing zeros and repair that if needed.
 	// Leading zeros can have meaning, so we should not clear them.
-	for *i < len(*text) && isDigit((*text)[*i]) {
-		*i++
+	for !c.done() && isDigit(c.peek(0)) {
+		c.next()
 	}
 
-	if *i < len(*text) && (*text)[*i] == codeDot {
-		*i++
-		if atEndOfNumber(text, i) {
-			repairNumberEndingWithNumericSymbol(text, start, i, output)
+	if !c.done() && c.peek(0) == codeDot {
+		c.next()
+		if atEndOfNumberOrCustom(c, opts) {
+			repairNumberEndingWithNumericSymbol(c, start, output)
+			logRepair(start, output, "completed truncated number", opts)
 			return true
 		}
-		if !isDigit((*text)[*i]) {
-			*i = start
-			return false
+		if !isDigit(c.peek(0)) {
+			return repairInvalidNumber(c, start, c.pos-1, output, opts)
 		}
-		for *i < len(*text) && isDigit((*text)[*i]) {
-			*i++
+		for !c.done() && isDigit(c.peek(0)) {
+			c.next()
 		}
 	}
 
-	if *i < len(*text) && ((*text)[*i] == codeLowercaseE || (*text)[*i] == codeUppercaseE) {
-		*i++
-		if *i < len(*text) && ((*text)[*i] == codeMinus || (*text)[*i] == codePlus) {
-			*i++
+	validEnd := c.pos
+	if !c.done() && (c.peek(0) == codeLowercaseE || c.peek(0) == codeUppercaseE) {
+		c.next()
+		if !c.done() && (c.peek(0) == codeMinus || c.peek(0) == codePlus) {
+			c.next()
 		}
-		if atEndOfNumber(text, i) {
-			repairNumberEndingWithNumericSymbol(text, start, i, output)
+		if atEndOfNumberOrCustom(c, opts) {
+			repairNumberEndingWithNumericSymbol(c, start, output)
+			logRepair(start, output, "completed truncated number", opts)
 			return true
 		}
-		if !isDigit((*text)[*i]) {
-			*i = start
-			return false
+		if !isDigit(c.peek(0)) {
+			return repairInvalidNumber(c, start, validEnd, output, opts)
 		}
-		for *i < len(*text) && isDigit((*text)[*i]) {
-			*i++
+		for !c.done() && isDigit(c.peek(0)) {
+			c.next()
 		}
 	}
 
-	if !atEndOfNumber(text, i) {
-		*i = start
+	if !atEndOfNumberOrCustom(c, opts) && !atSemicolonSeparator(c) {
+		return repairInvalidNumber(c, start, c.pos, output, opts)
+	}
+
+	if c.pos > start {
+		writeNumber(c, start, c.pos, output, opts)
+		return true
+	}
+	return false
+}
+
+// writeNumber writes the number between start and end to the output, quoting it when it has leading
+// zeros, or when it is a big integer and big numbers are preserved.
+func writeNumber(c *cursor, start, end int, output *strings.Builder, opts *options) {
+	num := string(c.slice(start, end))
+	hasInvalidLeadingZero := regexp.MustCompile(`^0\d`).MatchString(num)
+	if hasInvalidLeadingZero {
+		output.WriteString(fmt.Sprintf(`"%s"`, num))
+		logRepair(start, output, "quoted number with leading zero", opts)
+	} else if opts.preserveBigNumbers && isBigInteger(num) {
+		output.WriteString(fmt.Sprintf(`"%s"`, num))
+		logRepair(start, output, "quoted big number", opts)
+	} else {
+		output.WriteString(num)
+	}
+}
+
+// maxSafeInteger is 2^53, the largest integer up to which a float64 holds all integers exactly.
+const maxSafeInteger = "9007199254740992"
+
+// isBigInteger checks if the number is an integer beyond ±2^53, which loses precision in a float64.
+func isBigInteger(num string) bool {
+	digits := strings.TrimPrefix(num, "-")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
 		return false
 	}
+	if len(digits) != len(maxSafeInteger) {
+		return len(digits) > len(maxSafeInteger)
+	}
+	return digits > maxSafeInteger
+}
 
-	if *i > start {
-		num := string((*text)[start:*i])
-		hasInvalidLeadingZero := regexp.MustCompile(`^0\d`).MatchString(num)
-		if hasInvalidLeadingZero {
-			output.WriteString(fmt.Sprintf(`"%s"`, num))
-		} else {
-			output.WriteString(num)
+// regexNumberPrefix matches the valid number at the start of an invalid number token which is
+// kept with InvalidNumberTruncate. Leading zeros are allowed, since writeNumber quotes them.
+var regexNumberPrefix = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][+-]?\d+)?$`)
+
+// repairInvalidNumber handles a malformed numeric token like 0.0.1 or 2e3.4, which consists of
+// digits, dots, exponents and signs only, according to the invalid number policy. validEnd is
+// the end of the longest valid number at the start of the token. It returns false when the
+// token should be parsed as an unquoted string instead.
+func repairInvalidNumber(c *cursor, start, validEnd int, output *strings.Builder, opts *options) bool {
+	c.pos = start
+	if validEnd <= start || !isDigit(c.at(validEnd-1)) {
+		return false
+	}
+	end := cursor{text: c.text, pos: validEnd}
+	for isNumericChar(end.peek(0)) {
+		end.next()
+	}
+	if !atEndOfNumberOrCustom(&end, opts) {
+		return false
+	}
+
+	switch opts.invalidNumberPolicy {
+	case InvalidNumberTruncate:
+		if !regexNumberPrefix.MatchString(string(c.slice(start, validEnd))) {
+			return false // quoted by parseUnquotedString
 		}
+		// repair invalid number: keep the valid number at the start only
+		writeNumber(c, start, validEnd, output, opts)
+		logRepair(start, output, "truncated invalid number", opts)
+		c.pos = end.pos
 		return true
+	case InvalidNumberError:
+		opts.fail(newError(ErrInvalidNumber, "'"+string(c.slice(start, end.pos))+"'", opts.offset+start))
+		return false
+	default:
+		return false // quoted by parseUnquotedString
 	}
-	return false
 }
 
 // parseKeywords parses and repairs JSON keywords (true, false, null) and Python keywords (True, False, None).
-func parseKeywords(text *[]rune, i *int, output *strings.Builder) bool {
-	return parseKeyword(text, i, output, "true", "true") ||
-		parseKeyword(text, i, output, "false", "false") ||
-		parseKeyword(text, i, output, "null", "null") ||
-		parseKeyword(text, i, output, "True", "true") ||
-		parseKeyword(text, i, output, "False", "false") ||
-		parseKeyword(text, i, output, "None", "null")
+func parseKeywords(c *cursor, output *strings.Builder, opts *options) bool {
+	start := c.pos
+	if (opts.goSyntax && parseGoKeywords(c, output)) ||
+		parseKeyword(c, output, "True", "true") ||
+		parseKeyword(c, output, "False", "false") ||
+		parseKeyword(c, output, "None", "null") {
+		logRepair(start, output, "replaced keyword", opts)
+		return true
+	}
+	return parseKeyword(c, output, "true", "true") ||
+		parseKeyword(c, output, "false", "false") ||
+		parseKeyword(c, output, "null", "null")
+}
+
+// parseGoKeywords parses and repairs the Go keywords nil and <nil> as printed by the fmt package.
+func parseGoKeywords(c *cursor, output *strings.Builder) bool {
+	return parseKeyword(c, output, "<nil>", "null") ||
+		atWord(c, "nil") && parseKeyword(c, output, "nil", "null")
 }
 
 // parseKeyword parses a specific keyword from the input text.
-func parseKeyword(text *[]rune, i *int, output *strings.Builder, name, value string) bool {
-	if len(*text)-*i >= len(name) && string((*text)[*i:*i+len(name)]) == name {
+func parseKeyword(c *cursor, output *strings.Builder, name, value string) bool {
+	if c.hasPrefix(name) {
 		output.WriteString(value)
-		*i += len(name)
+		c.skip(len(name))
+		return true
+	}
+	return false
+}
+
+// parseRubySymbol parses a Ruby symbol like :name or :"quoted name" and turns it into a string.
+func parseRubySymbol(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.remaining() < 2 || c.peek(0) != codeColon {
+		return false
+	}
+
+	start := c.pos
+	if isQuote(c.peek(1)) {
+		c.next()
+		if parseString(c, output, false, opts) {
+			logRepair(start, output, "replaced symbol with string", opts)
+			return true
+		}
+		c.pos--
+		return false
+	}
+
+	if !isSymbolStart(c.peek(1)) {
+		return false
+	}
+
+	c.skip(2)
+	for !c.done() && isSymbolChar(c.peek(0)) {
+		c.next()
+	}
+	output.WriteString(fmt.Sprintf(`"%s"`, string(c.slice(start+1, c.pos))))
+	logRepair(start, output, "replaced symbol with string", opts)
+	return true
+}
+
+// skipArrow skips a "=>" separator like used in Ruby hashes and PHP arrays.
+func skipArrow(c *cursor) bool {
+	if atArrow(c) {
+		c.skip(2)
+		return true
+	}
+	return false
+}
+
+// parseFunction parses a JavaScript function value, like function() { ... } or () => x,
+// and replaces it with null. The function body is skipped as a whole, so braces and
+// commas inside it do not break the surrounding object.
+func parseFunction(c *cursor, output *strings.Builder, opts *options) bool {
+	start := c.pos
+	j := *c
+	if atWord(&j, "async") {
+		j.skip(len("async"))
+		skipWhitespace(&j)
+	}
+
+	switch {
+	case atWord(&j, "function"):
+		j.skip(len("function"))
+		skipWhitespace(&j)
+		skipCharacter(&j, codeAsterisk) // generator function
+		for isSymbolChar(j.peek(0)) {
+			j.next()
+		}
+		skipWhitespace(&j)
+		if j.done() || j.peek(0) != codeOpenParenthesis {
+			return false
+		}
+		skipBlock(&j, opts)
+		skipWhitespace(&j)
+		if j.done() || j.peek(0) != codeOpeningBrace {
+			return false
+		}
+		skipBlock(&j, opts)
+	case j.peek(0) == codeOpenParenthesis:
+		// arrow function like (a, b) => a + b
+		skipBlock(&j, opts)
+		skipWhitespace(&j)
+		if !skipArrow(&j) {
+			return false
+		}
+		skipWhitespace(&j)
+		skipArrowFunctionBody(&j, opts)
+	case isSymbolStart(j.peek(0)):
+		// arrow function like event => { ... }, only with a block body so that
+		// it is not mistaken for a PHP style key => value pair
+		for isSymbolChar(j.peek(0)) {
+			j.next()
+		}
+		skipWhitespace(&j)
+		if !skipArrow(&j) {
+			return false
+		}
+		skipWhitespace(&j)
+		if j.done() || j.peek(0) != codeOpeningBrace {
+			return false
+		}
+		skipBlock(&j, opts)
+	default:
+		return false
+	}
+
+	c.pos = j.pos
+	output.WriteString("null")
+	logRepair(start, output, "replaced function with null", opts)
+	return true
+}
+
+// skipOpeningParentheses skips redundant parentheses around the root value, like in ({"a": 1}),
+// and returns the number of skipped parentheses. Parentheses of an arrow function are kept.
+func skipOpeningParentheses(c *cursor, output *strings.Builder, opts *options) int {
+	count := 0
+	for {
+		parseWhitespaceAndSkipComments(c, output, opts)
+		if c.done() || c.peek(0) != codeOpenParenthesis {
+			return count
+		}
+		j := *c
+		skipBlock(&j, opts)
+		skipWhitespace(&j)
+		if atArrow(&j) {
+			return count
+		}
+
+		// repair: remove the parenthesis
+		logRepair(c.pos, output, "removed parentheses", opts)
+		c.next()
+		count++
+	}
+}
+
+// parseJavaObject parses an object as printed by Java toString methods, like
+// Person{name=John} or Lombok's Person(name=John), and drops the class name.
+func parseJavaObject(c *cursor, output *strings.Builder, opts *options) bool {
+	if !opts.equalsSeparator || c.done() || !isSymbolStart(c.peek(0)) {
+		return false
+	}
+
+	j := *c
+	for isSymbolChar(j.peek(0)) || j.peek(0) == codeDot || j.peek(0) == codeDollar {
+		j.next()
+	}
+	if skipCharacter(&j, codeOpeningBrace) {
+		c.pos = j.pos
+		return parseObjectMembers(c, output, codeClosingBrace, opts)
+	}
+	if !skipCharacter(&j, codeOpenParenthesis) {
+		return false
+	}
+
+	// only parentheses containing key=value pairs are an object, otherwise this is a function call
+	k := j
+	skipWhitespace(&k)
+	if k.peek(0) == codeCloseParenthesis {
+		c.pos = j.pos
+		return parseObjectMembers(c, output, codeCloseParenthesis, opts)
+	}
+	if !isSymbolStart(k.peek(0)) {
+		return false
+	}
+	for isSymbolChar(k.peek(0)) {
+		k.next()
+	}
+	skipWhitespace(&k)
+	if k.peek(0) != codeEqual {
+		return false
+	}
+
+	c.pos = j.pos
+	return parseObjectMembers(c, output, codeCloseParenthesis, opts)
+}
+
+// parseGoMap parses a map as printed by the fmt package with %v, like map[a:1 b:2].
+func parseGoMap(c *cursor, output *strings.Builder, opts *options) bool {
+	prefix := "map["
+	if !opts.goSyntax || !c.hasPrefix(prefix) {
+		return false
+	}
+
+	return parseGoLiteral(c, output, opts, func() bool {
+		c.skip(len(prefix))
+		return parseObjectMembers(c, output, codeClosingBracket, opts)
+	})
+}
+
+// parseGoLiteral parses a Go literal with parse, in which whitespace separates the values.
+// When the literal does not parse as a whole, it is left to the default parser.
+func parseGoLiteral(c *cursor, output *strings.Builder, opts *options, parse func() bool) bool {
+	inLiteral := opts.goLiteral
+	opts.goLiteral = true
+	defer func() { opts.goLiteral = inLiteral }()
+	return tryParse(c, output, opts, parse)
+}
+
+// tryParse parses with parse, and restores the cursor, output and report and returns false
+// when it does not parse without an error.
+func tryParse(c *cursor, output *strings.Builder, opts *options, parse func() bool) bool {
+	start, outputStart := c.pos, output.Len()
+	var repairs, comments, warnings int
+	if opts.report != nil {
+		repairs, comments = len(opts.report.Repairs), opts.report.Comments
+	}
+	if opts.warnings != nil {
+		warnings = len(*opts.warnings)
+	}
+
+	if parse() && opts.err == nil {
+		return true
+	}
+
+	outputStr := output.String()[:outputStart]
+	output.Reset()
+	output.WriteString(outputStr)
+	if opts.report != nil {
+		opts.report.Repairs = opts.report.Repairs[:repairs]
+		opts.report.Comments = comments
+	}
+	if opts.warnings != nil {
+		*opts.warnings = (*opts.warnings)[:warnings]
+	}
+	opts.err = nil
+	c.pos = start
+	return false
+}
+
+// parseGoConversion parses a Go type conversion like []string(nil) or (*main.User)(nil),
+// and replaces it with the converted value.
+func parseGoConversion(c *cursor, output *strings.Builder, opts *options) bool {
+	if !opts.goSyntax {
+		return false
+	}
+
+	j := *c
+	if skipCharacter(&j, codeOpenParenthesis) {
+		if !scanGoType(&j) || !skipCharacter(&j, codeCloseParenthesis) {
+			return false
+		}
+	} else if !scanGoType(&j) {
+		return false
+	}
+	if !skipCharacter(&j, codeOpenParenthesis) {
+		return false
+	}
+
+	return parseGoLiteral(c, output, opts, func() bool {
+		c.pos = j.pos
+		return parseValue(c, output, opts) && skipCharacter(c, codeCloseParenthesis)
+	})
+}
+
+// parseGoCompositeLiteral parses a Go composite literal like []int{1, 2} or main.User{Name:"a"},
+// and replaces it with a JSON array or object depending on the type. The & of a pointer, like
+// in &main.User{Name:"a"} or &{Name:a}, is removed.
+func parseGoCompositeLiteral(c *cursor, output *strings.Builder, opts *options) bool {
+	if !opts.goSyntax {
+		return false
+	}
+
+	j := *c
+	pointer := skipCharacter(&j, codeAmpersand)
+	if pointer && j.peek(0) == codeOpeningBrace {
+		// a pointer to a struct printed with %v or %+v, like &{Name:John Age:30}
+		c.pos = j.pos
+		return parseObject(c, output, opts)
+	}
+	typeStart := j.pos
+	if !scanGoType(&j) || j.pos == typeStart || !skipCharacter(&j, codeOpeningBrace) {
+		return false
+	}
+
+	return parseGoLiteral(c, output, opts, func() bool {
+		c.pos = j.pos
+		if c.at(typeStart) == codeOpeningBracket {
+			parseArrayItems(c, output, codeClosingBrace, opts)
+			return true
+		}
+		return parseObjectMembers(c, output, codeClosingBrace, opts)
+	})
+}
+
+// scanGoType moves the cursor past a Go type expression, and returns false when there is none.
+func scanGoType(c *cursor) bool {
+	for c.peek(0) == codeAsterisk {
+		c.next()
+	}
+
+	switch {
+	case c.hasPrefix("map["):
+		c.skip(len("map["))
+		if !scanGoType(c) || !skipCharacter(c, codeClosingBracket) {
+			return false
+		}
+		return scanGoType(c)
+	case c.hasPrefix("["):
+		c.next()
+		for isDigit(c.peek(0)) || c.peek(0) == codeDot {
+			c.next()
+		}
+		if !skipCharacter(c, codeClosingBracket) {
+			return false
+		}
+		return scanGoType(c)
+	case c.hasPrefix("interface {}"):
+		c.skip(len("interface {}"))
+		return true
+	case c.hasPrefix("interface{}"):
+		c.skip(len("interface{}"))
+		return true
+	case isSymbolStart(c.peek(0)):
+		for isSymbolChar(c.peek(0)) || c.peek(0) == codeDot {
+			c.next()
+		}
 		return true
 	}
 	return false
 }
 
 // parseUnquotedString parses and repairs unquoted strings, MongoDB function calls, and JSONP function calls.
-func parseUnquotedString(text *[]rune, i *int, output *strings.Builder) bool {
-	start := *i
-	// Move the index forward until a delimiter or quote is found
-	for *i < len(*text) && !isDelimiterExceptSlash((*text)[*i]) && !isQuote((*text)[*i]) {
-		*i++
+func parseUnquotedString(c *cursor, output *strings.Builder, isKey bool, opts *options) bool {
+	start := c.pos
+	// Move the cursor forward until a delimiter or quote is found
+	scan := func() {
+		for !c.done() && (!isDelimiterExceptSlash(c.peek(0)) || isKey && opts.colonsInKeys && atColonInKey(c)) &&
+			!(!isKey && c.pos > start && atSemicolonSeparator(c) && !atCharacterReferenceEnd(c, start, c.pos) &&
+				(opts.depth > 0 || c.pos == prevNonWhitespaceIndex(c.text, c.len()-1))) && !isCustomDelimiter(c.peek(0), opts) &&
+			!isQuote(c.peek(0)) && !atCommentStart(c, opts) &&
+			!(opts.goLiteral && isWhitespace(c.peek(0))) &&
+			!(isKey && c.pos > start && (atArrow(c) || opts.equalsSeparator && c.peek(0) == codeEqual)) {
+			if !skipPlaceholder(c) {
+				c.next()
+			}
+		}
 	}
+	scan()
 
-	if *i > start {
+	if c.pos > start {
 		// Check for MongoDB function call or JSONP function call
-		trimmedSymbol := strings.TrimSpace(string((*text)[start:*i]))
-		if *i < len(*text) && (*text)[*i] == codeOpenParenthesis && isFunctionName(trimmedSymbol) {
-			*i++
-			parseValue(text, i, output)
-			if *i < len(*text) && (*text)[*i] == codeCloseParenthesis {
-				*i++
-				if *i < len(*text) && (*text)[*i] == codeSemicolon {
-					*i++
-				}
+		trimmedSymbol := strings.TrimSpace(string(c.slice(start, c.pos)))
+		// repair JavaScript constructor call like new Date("2024-01-01") by ignoring the new keyword
+		trimmedSymbol = stripNewKeyword(trimmedSymbol)
+		// a comment may be placed between the function name and the parenthesis
+		j := *c
+		for atCommentStart(&j, opts) && parseComment(&j, opts) {
+			skipWhitespace(&j)
+		}
+		if j.peek(0) == codeOpenParenthesis && isFunctionName(trimmedSymbol) && !isAllowedFunction(trimmedSymbol, opts) {
+			if opts.functionCallPolicy == FunctionCallError {
+				opts.fail(newError(ErrUnknownFunction, trimmedSymbol, opts.offset+start))
+				return false
+			}
+			// keep the function call as a string like "foo (1)"
+			for !c.done() && c.peek(0) == codeOpenParenthesis {
+				skipParenthesized(c)
+				scan()
 			}
+			for c.pos > start && (isWhitespace(c.peek(-1)) || isCustomWhitespace(c.peek(-1), opts)) {
+				c.pos--
+			}
+			writeQuoted(output, strings.TrimSpace(string(c.slice(start, c.pos))))
+			logRepair(start, output, "added missing quotes", opts)
+			return true
+		} else if j.peek(0) == codeOpenParenthesis && isFunctionName(trimmedSymbol) {
+			c.pos = j.pos + 1
+			writeCall(output, trimmedSymbol, parseCallArguments(c, opts))
+			skipCharacter(c, codeSemicolon)
+			logRepair(start, output, "removed function call", opts)
 			return true
 		} else {
 			// Move back to prevent trailing whitespaces in the string
-			for *i > start && isWhitespace((*text)[*i-1]) {
-				*i--
+			for c.pos > start && (isWhitespace(c.peek(-1)) || isCustomWhitespace(c.peek(-1), opts)) {
+				c.pos--
 			}
-			symbol := strings.TrimSpace(string((*text)[start:*i]))
+			symbol := strings.TrimSpace(string(c.slice(start, c.pos)))
 			if symbol == "undefined" {
 				output.WriteString("null")
+				logRepair(start, output, "replaced undefined with null", opts)
 			} else {
 				// Ensure special quotes are replaced with double quotes
 				repairedSymbol := strings.Builder{}
 				for _, char := range symbol {
 					if isSingleQuoteLike(char) || isDoubleQuoteLike(char) {
 						repairedSymbol.WriteRune('"')
+					} else if char == 0 && opts.nulPolicy == NULEscape {
+						repairedSymbol.WriteString(`\u0000`)
 					} else {
 						repairedSymbol.WriteRune(char)
 					}
 				}
 				output.WriteString(fmt.Sprintf(`"%s"`, repairedSymbol.String()))
+				logRepair(start, output, "added missing quotes", opts)
 			}
 			// Skip the end quote if encountered
-			if *i < len(*text) && (*text)[*i] == codeDoubleQuote {
-				*i++
+			if !c.done() && c.peek(0) == codeDoubleQuote {
+				c.next()
 			}
 			return true
 		}
@@ -1,7 +1,13 @@
 package jsonrepair
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -100,6 +106,20 @@ func TestSupportsEscapedUnicodeCharactersInString(t *testing.T) {
 	assertRepairEqual(t, `"\\u0439\\u043d\\u0444\\u043e\\u0440\\u043c\\u0430\\u0446\\u0438\\u044f"`)
 }
 
+// TestShouldRepairLoneSurrogates tests repairing escaped surrogates without their other half.
+func TestShouldRepairLoneSurrogates(t *testing.T) {
+	assertRepair(t, `"\ud83d"`, `"\ufffd"`)
+	assertRepair(t, `"a\uD83Dx"`, `"a\ufffdx"`)
+	assertRepair(t, `"\ude00\ud83d\ude00"`, `"\ufffd\ud83d\ude00"`)
+	assertRepair(t, `["\ud83d`, `["\ufffd"]`)
+	assertRepair(t, `{"a": "x\ud83d", "b": 1}`, `{"a": "x", "b": 1}`, WithSurrogatePolicy(SurrogateDrop))
+
+	var report Report
+	_, err := JSONRepair(`"ab\ud83d"`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 3, Kind: RepairLoneSurrogate, Message: "replaced lone surrogate"}}, report.Repairs)
+}
+
 // TestSupportsUnicodeCharactersInKey tests parsing JSON objects with Unicode characters in keys.
 func TestSupportsUnicodeCharactersInKey(t *testing.T) {
 	assertRepairEqual(t, `{"★":true}`)
@@ -175,6 +195,26 @@ func TestShouldRepairEllipsisInArray(t *testing.T) {
 	assertRepair(t, `[ ... ]`, `[  ]`)
 }
 
+// TestEllipsisPolicy tests replacing an ellipsis with a placeholder or failing on it.
+func TestEllipsisPolicy(t *testing.T) {
+	placeholder := WithEllipsisPolicy(EllipsisPlaceholder)
+	assertRepair(t, `[1,2,3,...]`, `[1,2,3,null]`, placeholder)
+	assertRepair(t, `[1, ... ,2]`, `[1, null ,2]`, placeholder)
+	assertRepair(t, `[...]`, `[null]`, placeholder)
+	assertRepair(t, `{"a":2,"b":3,...}`, `{"a":2,"b":3,"...": null}`, placeholder)
+	assertRepair(t, `[1,...]`, `[1,"…"]`, placeholder, WithEllipsisPlaceholder(`"…"`))
+
+	reject := WithEllipsisPolicy(EllipsisError)
+	assertRepairFailure(t, `[1,2,3,...]`, `data truncated with ellipsis`, 7, reject)
+	assertRepairFailure(t, `{"a": {"b": 1, ...}}`, `data truncated with ellipsis`, 15, reject)
+	assertRepair(t, `["..."]`, `["..."]`, reject)
+
+	var report Report
+	_, err := JSONRepair(`[..., 1]`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 1, Kind: RepairEllipsis, Message: "removed ellipsis"}}, report.Repairs)
+}
+
 // TestShouldRepairEllipsisInObject tests repairing ellipses in JSON objects.
 func TestShouldRepairEllipsisInObject(t *testing.T) {
 	assertRepair(t, `{"a":2,"b":3,...}`, `{"a":2,"b":3}`)
@@ -322,6 +362,61 @@ func TestShouldRemoveLineComments(t *testing.T) {
 	assertRepair(t, "{\n\"a\":\"foo\",//hello\n\"b\":\"bar\"\n}", "{\n\"a\":\"foo\",\n\"b\":\"bar\"\n}")
 }
 
+// TestShouldRemoveCommentsAtEveryPosition tests removing comments at every position of the grammar.
+func TestShouldRemoveCommentsAtEveryPosition(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		// around the root value
+		{`/*c*/{}`, `{}`},
+		{`{}/*c*/`, `{}`},
+		{"{}// c", `{}`},
+
+		// object: before and after keys, colons, values and commas
+		{`{/*c*/"a":1}`, `{"a":1}`},
+		{`{"a"/*c*/:1}`, `{"a":1}`},
+		{`{"a":/*c*/1}`, `{"a":1}`},
+		{`{"a":1/*c*/}`, `{"a":1}`},
+		{`{"a":1/*c*/,"b":2}`, `{"a":1,"b":2}`},
+		{`{"a":1,/*c*/"b":2}`, `{"a":1,"b":2}`},
+		{`{"a":1,/*c*/}`, `{"a":1}`},
+		{"{\"a\" // c\n: 1}", "{\"a\" \n: 1}"},
+		{`{"a": [1,2]/*c*/}`, `{"a": [1,2]}`},
+
+		// array: before and after items and commas
+		{`[/*c*/1]`, `[1]`},
+		{`[1/*c*/,2]`, `[1,2]`},
+		{`[1,/*c*/2]`, `[1,2]`},
+		{`[1,/*c*/]`, `[1]`},
+
+		// unquoted keys and values end at a comment
+		{`{a/*c*/:1}`, `{"a":1}`},
+		{"{a // c\n: 1}", "{\"a\" \n: 1}"},
+		{`{a:b/*c*/, c:d}`, `{"a":"b", "c":"d"}`},
+		{`[a/*c*/, b]`, `["a", "b"]`},
+		{"[hello world // greeting\n]", "[\"hello world\" \n]"},
+		{`{"a": undefined/*c*/}`, `{"a": null}`},
+		{`{"a": True/*c*/}`, `{"a": true}`},
+		{`{a:b//c}`, `{"a":"b//c"}`},
+
+		// repairs next to comments
+		{`{"a":"b"/*c*/"c":1}`, `{"a":"b","c":1}`},
+		{`{"a" /*c*/ 1}`, `{"a":  1}`},
+		{`["a"/*c*/+/*c*/"b"]`, `["ab"]`},
+		{`{a: NumberLong(/*c*/2/*c*/)}`, `{"a": 2}`},
+		{`callback/*c*/({})`, `{}`},
+		{`{:a /*c*/ => 1}`, `{"a"  : 1}`},
+		{`['a' /*c*/ => /*c*/ 1]`, `{"a"  :  1}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			assertRepair(t, test.text, test.expected)
+		})
+	}
+}
+
 // TestShouldNotRemoveCommentsInsideString tests not removing comments inside a string in JSON.
 func TestShouldNotRemoveCommentsInsideString(t *testing.T) {
 	assertRepairEqual(t, `"/* foo */"`)
@@ -357,6 +452,75 @@ func TestShouldStripJSONPNotation(t *testing.T) {
 	assertRepairFailure(t, `callback {}`, `unexpected character: '{'`, 9)
 }
 
+// TestFunctionCallPolicy tests keeping or rejecting function calls which are not allowed.
+func TestFunctionCallPolicy(t *testing.T) {
+	keep := WithFunctionCallPolicy(FunctionCallKeep)
+	assertRepair(t, `[foo (1)]`, `["foo (1)"]`, keep)
+	assertRepair(t, `{"a": foo (1, ")") bar, "b": 2}`, `{"a": "foo (1, \")\") bar", "b": 2}`, keep)
+	assertRepair(t, `callback({});`, `"callback({})"`, keep)
+	assertRepair(t, `callback({"a": 1});`, `{"a": 1}`, keep, WithFunctionNames("callback"))
+	// MongoDB shell types and JavaScript constructors are always stripped
+	assertRepair(t, `{"id": ObjectId("123"), "d": new Date("2024-01-01")}`, `{"id": "123", "d": "2024-01-01"}`, keep)
+
+	reject := WithFunctionCallPolicy(FunctionCallError)
+	assertRepairFailure(t, `[foo (1)]`, `unknown function: foo`, 1, reject)
+	assertRepairFailure(t, `jsonp_1({"a": 1})`, `unknown function: jsonp_1`, 0, reject, WithFunctionNames("callback"))
+	assertRepair(t, `callback({"a": 1})`, `{"a": 1}`, reject, WithFunctionNames("callback"))
+	assertRepair(t, `[foo bar]`, `["foo bar"]`, reject)
+}
+
+// TestShouldStripVariableAssignments tests stripping JavaScript variable assignments in front of the value.
+func TestShouldStripVariableAssignments(t *testing.T) {
+	assertRepair(t, "const data = {a: 1};\n", "{\"a\": 1}\n")
+	assertRepair(t, `var x = [1, 2]`, `[1, 2]`)
+	assertRepair(t, `let config = {"a": true};`, `{"a": true}`)
+	assertRepair(t, `const n = 5;`, `5`)
+	assertRepair(t, `let s = "x;";`, `"x;"`)
+	assertRepair(t, `data = {"a": 1}`, `{"a": 1}`)
+	assertRepair(t, `window.__STATE__ = {"a": 1};`, `{"a": 1}`)
+	assertRepair(t, `module.exports = {a: 1}`, `{"a": 1}`)
+	assertRepair(t, `export default {a: 1};`, `{"a": 1}`)
+	assertRepair(t, `export const data = [1];`, `[1]`)
+	assertRepair(t, "/* state */ var state = {};", " {}")
+
+	// non-matching
+	assertRepair(t, `a = b`, `"a = b"`)
+	assertRepair(t, `constant = 1`, `"constant = 1"`)
+	assertRepair(t, `const`, `"const"`)
+	assertRepair(t, `const data =`, `"const data ="`)
+	assertRepair(t, `const data = , 1`, "[\n\"const data =\" , 1\n]")
+	assertRepair(t, `export default`, `"export default"`)
+	assertRepair(t, `{"a": 1};`, `{"a": 1}`)
+}
+
+// TestShouldUnwrapParenthesizedValues tests removing redundant parentheses around the root value.
+func TestShouldUnwrapParenthesizedValues(t *testing.T) {
+	assertRepair(t, `({"a": 1})`, `{"a": 1}`)
+	assertRepair(t, `((([1,2])))`, `[1,2]`)
+	assertRepair(t, `( {"a": 1} );`, ` {"a": 1} `)
+	assertRepair(t, `({"a": 1}`, `{"a": 1}`)
+	assertRepair(t, `("a")`, `"a"`)
+	assertRepair(t, "// comment\n({})", "\n{}")
+	assertRepair(t, `(a, b) => a`, `null`)
+	assertRepairFailure(t, `()`, "unexpected end of json string", 2)
+}
+
+// TestShouldStripReturnKeyword tests stripping a return keyword in front of the value.
+func TestShouldStripReturnKeyword(t *testing.T) {
+	assertRepair(t, `return { "a": 1 };`, `{ "a": 1 }`)
+	assertRepair(t, "return [1, 2]\n", "[1, 2]\n")
+	assertRepair(t, `return{"a":1}`, `{"a":1}`)
+	assertRepair(t, `return 5;`, `5`)
+	assertRepair(t, `return "x"`, `"x"`)
+	assertRepair(t, "// result\nreturn {a: 1};", "\n{\"a\": 1}")
+
+	// non-matching
+	assertRepair(t, `return`, `"return"`)
+	assertRepair(t, `return;`, `"return"`)
+	assertRepair(t, `returned`, `"returned"`)
+	assertRepair(t, `{"a": return}`, `{"a": "return"}`)
+}
+
 // TestShouldRepairEscapedStringContents tests repairing escaped string contents in JSON strings.
 func TestShouldRepairEscapedStringContents(t *testing.T) {
 	assertRepair(t, `\"hello world\"`, `"hello world"`)
@@ -489,6 +653,52 @@ func TestShouldStripMongoDBDataTypes(t *testing.T) {
 	assertRepair(t, mongoDocument, expectedJson)
 }
 
+// TestShouldRepairMongoDBShellTypes tests repairing MongoDB shell types with more than one argument.
+func TestShouldRepairMongoDBShellTypes(t *testing.T) {
+	assertRepair(t, `{"ts": Timestamp(162, 1)}`, `{"ts": {"$timestamp": {"t": 162, "i": 1}}}`)
+	assertRepair(t, `{"bin": BinData(0, "AQID")}`, `{"bin": {"$binary": {"base64": "AQID", "subType": "00"}}}`)
+	assertRepair(t, `{"ref": DBRef("users", ObjectId("abc"))}`, `{"ref": {"$ref": "users", "$id": "abc"}}`)
+	assertRepair(t, `{"ref": DBRef("users", 1, "app")}`, `{"ref": {"$ref": "users", "$id": 1, "$db": "app"}}`)
+	assertRepair(t, `{"uuid": UUID("0e3b8f2c-8d1a-4c1e-9c55-3b7f0c2d1e4a")}`, `{"uuid": "0e3b8f2c-8d1a-4c1e-9c55-3b7f0c2d1e4a"}`)
+	assertRepair(t, `{"d": new Date(2024, 0, 31)}`, `{"d": "2024-01-31T00:00:00.000Z"}`)
+	assertRepair(t, `{"d": ISODate(2024, 11, 31, 23, 59, 59, 500)}`, `{"d": "2024-12-31T23:59:59.500Z"}`)
+	assertRepair(t, `{"d": Date()}`, `{"d": null}`)
+	// other functions keep their arguments as an array
+	assertRepair(t, `[foo(1, "a")]`, `[[1, "a"]]`)
+	assertRepair(t, `{"d": new Date(2024, "x")}`, `{"d": [2024, "x"]}`)
+	// arguments which are not a list leave the call with its first value
+	assertRepair(t, `{"a": NumberLo(ng("2"), "b": `, `{"a": "2", "b": null}`)
+	assertRepair(t, `{"a": NumberLo(ng("2"), "b": 1}`, `{"a": "2", "b": 1}`)
+}
+
+// TestShouldStripJavaScriptConstructors tests stripping new Date(...) and similar constructors.
+func TestShouldStripJavaScriptConstructors(t *testing.T) {
+	assertRepair(t, `new Date("2024-01-01")`, `"2024-01-01"`)
+	assertRepair(t, `{"created": new Date("2024-01-01"), "id": new ObjectId("123")}`, `{"created": "2024-01-01", "id": "123"}`)
+	assertRepair(t, `[new Number(2), new   Boolean(true)]`, `[2, true]`)
+	assertRepair(t, `{"now": new Date()}`, `{"now": null}`)
+	assertRepair(t, `{"city": new york}`, `{"city": "new york"}`)
+	assertRepair(t, `{"a": newDate}`, `{"a": "newDate"}`)
+}
+
+// TestShouldReplaceJavaScriptFunctionsWithNull tests replacing function values in object literals with null.
+func TestShouldReplaceJavaScriptFunctionsWithNull(t *testing.T) {
+	assertRepair(t, `{onClick: function() { if (a) { return "}" } }, data: 1}`, `{"onClick": null, "data": 1}`)
+	assertRepair(t, `{cb: async function load(x) { await x }, d: 1}`, `{"cb": null, "d": 1}`)
+	assertRepair(t, "{cb: function() { // }\n}, x: 1}", `{"cb": null, "x": 1}`)
+	assertRepair(t, `[function () {}, 2]`, `[null, 2]`)
+
+	// arrow functions
+	assertRepair(t, `{cb: () => x, b: 2}`, `{"cb": null, "b": 2}`)
+	assertRepair(t, `{cb: () => foo(1, 2)}`, `{"cb": null}`)
+	assertRepair(t, `{cb: (a, b) => { return {a, b} }, c: [1]}`, `{"cb": null, "c": [1]}`)
+	assertRepair(t, `{cb: e => { e.preventDefault() }}`, `{"cb": null}`)
+
+	// not a function
+	assertRepair(t, `{type: function}`, `{"type": "function"}`)
+	assertRepair(t, `{"a": functional}`, `{"a": "functional"}`)
+}
+
 // TestShouldNotMatchMongoDBLikeFunctionsInUnquotedString tests not matching MongoDB-like functions in an unquoted string.
 func TestShouldNotMatchMongoDBLikeFunctionsInUnquotedString(t *testing.T) {
 	assertRepairFailure(t, `["This is C(2)", "This is F(3)]`, `unexpected character: '('`, 27)
@@ -525,6 +735,338 @@ func TestShouldTurnInvalidNumbersIntoStrings(t *testing.T) {
 	assertRepair(t, `2e3.4`, `"2e3.4"`)
 }
 
+// TestShouldApplyInvalidNumberPolicy tests truncating invalid numbers or failing on them.
+func TestShouldApplyInvalidNumberPolicy(t *testing.T) {
+	truncate := WithInvalidNumberPolicy(InvalidNumberTruncate)
+	assertRepair(t, `0.0.1`, `0.0`, truncate)
+	assertRepair(t, `[1.2.3, 2e3.4]`, `[1.2, 2e3]`, truncate)
+	assertRepair(t, `{"a": -1.2.3}`, `{"a": -1.2}`, truncate)
+	assertRepair(t, `234..5`, `234`, truncate)
+	assertRepair(t, `00.1.2`, `"00.1"`, truncate)
+	assertRepair(t, `[2 0.0.1 2]`, `[2, 0.0, 2]`, truncate)
+	assertRepair(t, `ES2020`, `"ES2020"`, truncate)
+	assertRepair(t, `746de9ad-d4ff`, `"746de9ad-d4ff"`, truncate)
+	assertRepair(t, `--5`, `"--5"`, truncate)
+	assertRepair(t, `[e2.3]`, `["e2.3"]`, truncate)
+	assertRepair(t, `[.5.5]`, `[".5.5"]`, truncate)
+	assertRepair(t, `[00789, .3.4, 1e]`, `["00789", ".3.4", 1e0]`, truncate)
+
+	failOnInvalid := WithInvalidNumberPolicy(InvalidNumberError)
+	assertRepairFailure(t, `0.0.1`, `invalid number: '0.0.1'`, 0, failOnInvalid)
+	assertRepairFailure(t, `{"a": [1, 2e3.4]}`, `invalid number: '2e3.4'`, 10, failOnInvalid)
+	assertRepair(t, `ES2020`, `"ES2020"`, failOnInvalid)
+	assertRepair(t, `[1.5, 2.]`, `[1.5, 2.0]`, failOnInvalid)
+
+	_, err := JSONRepair(`1.2.3`, failOnInvalid)
+	require.ErrorIs(t, err, ErrInvalidNumber)
+}
+
+// TestShouldPreserveBigNumbers tests quoting integers beyond 2^53.
+func TestShouldPreserveBigNumbers(t *testing.T) {
+	preserve := WithPreserveBigNumbers()
+	assertRepair(t, `{"id": 12345678901234567890}`, `{"id": "12345678901234567890"}`, preserve)
+	assertRepair(t, `[9007199254740992, 9007199254740993, -9007199254740993]`,
+		`[9007199254740992, "9007199254740993", "-9007199254740993"]`, preserve)
+	assertRepair(t, `[1.5, 1e30, 12345678901234567890.5]`, `[1.5, 1e30, 12345678901234567890.5]`, preserve)
+	assertRepair(t, `{'id': 12345678901234567890,}`, `{"id": "12345678901234567890"}`, preserve)
+	assertRepair(t, `{"id": 12345678901234567890}`, `{"id": 12345678901234567890}`)
+
+	var report Report
+	_, err := JSONRepair(`[12345678901234567890]`, preserve, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 1, Kind: RepairNumber, Message: "quoted big number"}}, report.Repairs)
+
+	// a schema which expects a number keeps the number
+	repaired, err := JSONRepair(`{"a": 12345678901234567890, "b": 12345678901234567890}`, preserve,
+		WithSchema(`{"properties": {"a": {"type": "integer"}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 12345678901234567890, "b": "12345678901234567890"}`, repaired)
+}
+
+// TestShouldRepairSparseArrays tests repairing the empty slots of sparse arrays.
+func TestShouldRepairSparseArrays(t *testing.T) {
+	assertRepair(t, `[1,,2]`, `[1,null,2]`)
+	assertRepair(t, `[1, , 2]`, `[1, null, 2]`)
+	assertRepair(t, `[1,,,2]`, `[1,null,null,2]`)
+	assertRepair(t, `[1,,]`, `[1,null]`)
+	assertRepair(t, `{"a": ["x",/* hole */,"y"]}`, `{"a": ["x",null,"y"]}`)
+	assertRepair(t, `{"a": [1, , "b": "x`, `{"a": [1] , "b": "x"}`)
+
+	drop := WithDropEmptyArraySlots()
+	assertRepair(t, `[1,,2]`, `[1,2]`, drop)
+	assertRepair(t, `[1,,,2]`, `[1,2]`, drop)
+	assertRepair(t, `[1,,]`, `[1]`, drop)
+
+	var report Report
+	assertRepair(t, `[1,,2]`, `[1,null,2]`, WithReport(&report))
+	assert.Equal(t, []Repair{{Position: 3, Kind: RepairEmptySlot, Message: "replaced empty array slot with null"}}, report.Repairs)
+}
+
+// TestShouldDecodeHTMLEntitiesWhenEnabled tests decoding HTML entities inside strings.
+func TestShouldDecodeHTMLEntitiesWhenEnabled(t *testing.T) {
+	assertRepair(t, `{"a": "x &amp; y &lt;b&gt;"}`, `{"a": "x & y <b>"}`, WithHTMLEntities())
+	assertRepair(t, `{"a": "say &quot;hi&quot; &#39;ok&#39; &#x22;"}`, `{"a": "say \"hi\" 'ok' \""}`, WithHTMLEntities())
+	assertRepair(t, `{"a": "tab&#9;end"}`, `{"a": "tab\tend"}`, WithHTMLEntities())
+	assertRepair(t, `{"a": "&nosuch; &amp"}`, `{"a": "&nosuch; &amp"}`, WithHTMLEntities())
+
+	// entities forming the quotes of strings
+	assertRepair(t, `{&quot;a&quot;: &quot;x &amp; y&quot;}`, `{"a": "x & y"}`, WithHTMLEntities())
+	assertRepair(t, `[&quot;a&quot;, &#34;b]`, `["a", "b"]`, WithHTMLEntities())
+
+	// double encoded entities
+	assertRepair(t, `{&amp;quot;a&amp;quot;: "&amp;amp;"}`, `{"a": "&"}`, WithHTMLEntities())
+
+	assertRepair(t, `{"a": "x &amp; y"}`, `{"a": "x &amp; y"}`)
+}
+
+// TestShouldKeepColonsInKeysWhenEnabled tests keeping the colons of namespaced unquoted keys.
+func TestShouldKeepColonsInKeysWhenEnabled(t *testing.T) {
+	assertRepair(t, `{db:host: "x"}`, `{"db:host": "x"}`, WithColonsInKeys())
+	assertRepair(t, `{a:b:c: 1, d:e:f}`, `{"a:b:c": 1, "d:e":"f"}`, WithColonsInKeys())
+	assertRepair(t, `{a:b, time: 12:30}`, `{"a":"b", "time": "12:30"}`, WithColonsInKeys())
+	assertRepair(t, `{db:host:"x"}`, `{"db":"host","x": null}`)
+}
+
+// TestShouldRepairCustomDelimitersAndWhitespace tests adding delimiters and whitespace characters.
+func TestShouldRepairCustomDelimitersAndWhitespace(t *testing.T) {
+	assertRepair(t, `[1|2|3]`, `[1,2,3]`, WithDelimiters("|"))
+	assertRepair(t, `{"a":1|"b":"x"|c:y}`, `{"a":1,"b":"x","c":"y"}`, WithDelimiters("|"))
+	assertRepair(t, `[1.5^-2^true^a b]`, `[1.5,-2,true,"a b"]`, WithDelimiters("|^"))
+	assertRepair(t, `{a:~1,~b:~two~}`, `{"a": 1, "b": "two" }`, WithWhitespace("~"))
+	assertRepair(t, `{~"a"~:~[1~|~2]~}`, `{ "a" : [1 , 2] }`, WithDelimiters("|"), WithWhitespace("~"))
+	assertRepair(t, `[a|b]`, `["a|b"]`)
+
+	var report Report
+	_, err := JSONRepair(`[1|2]`, WithDelimiters("|"), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 2, Kind: RepairSeparator, Message: "replaced delimiter with comma"}}, report.Repairs)
+
+	for _, opt := range []Option{WithDelimiters(":"), WithDelimiters("|'"), WithWhitespace("a"), WithWhitespace("\t"), WithWhitespace("{")} {
+		_, err := JSONRepair(`[1]`, opt)
+		require.ErrorIs(t, err, ErrInvalidOption)
+	}
+	_, _, _, err = Extract(`see [1]`, WithWhitespace("-"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+// TestShouldRepairNestedStrings tests repairing JSON embedded in string values.
+func TestShouldRepairNestedStrings(t *testing.T) {
+	keys := WithNestedStringRepair(regexp.MustCompile(`^(payload|body)$`))
+	assertRepair(t, `{"payload": "{\"a\": 1,}", "b": "{x}"}`, `{"payload": "{\"a\": 1}", "b": "{x}"}`, keys)
+	assertRepair(t, `{payload: "{\"body\": \"{d: 1}\"}"}`, `{"payload": "{\"body\": \"{\\\"d\\\": 1}\"}"}`, keys)
+	assertRepair(t, `{"payload": "not json", "other": "{a: 1}"}`, `{"payload": "not json", "other": "{a: 1}"}`, keys)
+	assertRepair(t, `{"payload": "{\"a\": 1,}"}`, `{"payload": "{\"a\": 1,}"}`)
+
+	inline := WithInlineNestedStrings()
+	assertRepair(t, `{"payload": "{\"a\": 1,}", "b": "{x}"}`, `{"payload": {"a": 1}, "b": "{x}"}`, keys, inline)
+	assertRepair(t, `{payload: "{\"body\": \"{d: 1}\"}"}`, `{"payload": {"body": {"d": 1}}}`, keys, inline)
+	assertRepair(t, `[{"body": " [1 2] " }]`, `[{"body": [1, 2] }]`, keys, inline)
+	assertRepair(t, `{"body": "{\"a\": 1} x"}`, `{"body": "{\"a\": 1} x"}`, keys, inline)
+
+	var report Report
+	_, err := JSONRepair(`{"body": "[1 2]"}`, keys, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 9, Kind: RepairNestedString, Message: "repaired nested json string"}}, report.Repairs)
+}
+
+// TestShouldRepairPartiallyQuotedKeys tests reuniting a key with a dropped or misplaced quote.
+func TestShouldRepairPartiallyQuotedKeys(t *testing.T) {
+	assertRepair(t, `{na"me": 1}`, `{"name": 1}`)
+	assertRepair(t, `{na"me: 1}`, `{"name": 1}`)
+	assertRepair(t, `{"na"me: 1}`, `{"name": 1}`)
+	assertRepair(t, `{key"with"quotes: 1}`, `{"keywithquotes": 1}`)
+	assertRepair(t, `{"a": 1, b"c": 2}`, `{"a": 1, "bc": 2}`)
+	assertRepair(t, `{"na me: 1}`, `{"na me": 1}`)
+	assertRepair(t, `{"na"me": 1}`, `{"na\"me": 1}`)
+	assertRepair(t, `{"a"b: {"c": 1`, `{"ab": {"c": 1}}`)
+	// not followed by a valid value
+	assertRepair(t, `{"a"é:.`, `{"a\"é:.":null}`)
+
+	var report Report
+	_, err := JSONRepair(`{na"me": 1}`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 1, Kind: RepairMissingQuote, Message: "reunited partially quoted key"}}, report.Repairs)
+}
+
+// TestShouldRepairKeysWithoutValue tests repairing object keys without colon and value.
+func TestShouldRepairKeysWithoutValue(t *testing.T) {
+	assertRepair(t, `{a, b: 1}`, `{"a": null, "b": 1}`)
+	assertRepair(t, `{ "flag" }`, `{ "flag": null }`)
+	assertRepair(t, `{"a": 1, "b"}`, `{"a": 1, "b": null}`)
+	assertRepair(t, `{a, b}`, `{"a": null, "b": null}`)
+	assertRepair(t, `[{a}]`, `[{"a": null}]`)
+
+	drop := WithDropBareKeys()
+	assertRepair(t, `{a, b: 1}`, `{ "b": 1}`, drop)
+	assertRepair(t, `{"a":1, b, c:2}`, `{"a":1, "c":2}`, drop)
+	assertRepair(t, `{"a": 1, "b"}`, `{"a": 1}`, drop)
+	assertRepair(t, `{ "flag" }`, `{ }`, drop)
+	assertRepair(t, `{a: 1 b}`, `{"a": 1}`, drop)
+	assertRepair(t, "{a: b\n\\u}", `{"a": "b"}`, drop)
+}
+
+// TestShouldRepairUnquotedURLs tests repairing URLs with missing quotes.
+func TestShouldRepairUnquotedURLs(t *testing.T) {
+	assertRepair(t, `{url: https://example.com/a?b=1}`, `{"url": "https://example.com/a?b=1"}`)
+	assertRepair(t, `[https://example.com/a, 2]`, `["https://example.com/a", 2]`)
+	assertRepair(t, `{u: https://a.com/a%20b, v: 2}`, `{"u": "https://a.com/a%20b", "v": 2}`)
+	assertRepair(t, `{url: http://[::1]:8080/x}`, `{"url": "http://[::1]:8080/x"}`)
+	assertRepair(t, `[http://[2001:db8::1%25eth0]/]`, `["http://[2001:db8::1%25eth0]/"]`)
+	assertRepair(t, `{w: https://en.wikipedia.org/wiki/Go_(lang)}`, `{"w": "https://en.wikipedia.org/wiki/Go_(lang)"}`)
+	assertRepair(t, `callback(http://a.com)`, `"http://a.com"`)
+
+	// missing end quote
+	assertRepair(t, `{"u": "https://example.com/a, "b": 1}`, `{"u": "https://example.com/a", "b": 1}`)
+	assertRepair(t, `["https://example.com/a?x=1, 2]`, `["https://example.com/a?x=1", 2]`)
+
+	withBrackets := WithURLChars("[]{}|")
+	assertRepair(t, `[http://a.com/a[0]|b, 1]`, `["http://a.com/a[0]|b", 1]`, withBrackets)
+	assertRepair(t, `{u: http://a.com/{id}}`, `{"u": "http://a.com/{id}"}`, withBrackets)
+	assertRepair(t, `[http://a.com/x]`, `["http://a.com/x"]`, withBrackets)
+}
+
+// TestShouldRepairUnquotedEndpoints tests repairing unquoted hosts with a port and IPv6 addresses.
+func TestShouldRepairUnquotedEndpoints(t *testing.T) {
+	assertRepair(t, `{host: 10.0.0.1:9200}`, `{"host": "10.0.0.1:9200"}`)
+	assertRepair(t, `{host: localhost:8080}`, `{"host": "localhost:8080"}`)
+	assertRepair(t, `{host: db.example.com:5432}`, `{"host": "db.example.com:5432"}`)
+	assertRepair(t, `{host: [::1]:8080}`, `{"host": "[::1]:8080"}`)
+	assertRepair(t, `[10.0.0.1:9200, [::1], [fe80::1%eth0]]`, `["10.0.0.1:9200", "[::1]", "[fe80::1%eth0]"]`)
+	assertRepair(t, `[2001:db8:0:0:0:0:0:1]`, `"[2001:db8:0:0:0:0:0:1]"`)
+
+	// no endpoints
+	assertRepair(t, `[[1,2]]`, `[[1,2]]`)
+	assertRepair(t, `{t: 12:30:45}`, `{"t": "12:30:45"}`)
+	assertRepair(t, `{a: 10.0.0.1}`, `{"a": "10.0.0.1"}`)
+	assertRepairFailure(t, `[1.5:3:4]`, "unexpected character: ':'", 4)
+}
+
+// TestShouldRepairSemicolonSeparators tests repairing semicolons used instead of commas.
+func TestShouldRepairSemicolonSeparators(t *testing.T) {
+	assertRepair(t, `{a:1; b:2}`, `{"a":1, "b":2}`)
+	assertRepair(t, `[1; 2; 3]`, `[1, 2, 3]`)
+	assertRepair(t, `{a: x; b: y;}`, `{"a": "x", "b": "y"}`)
+	assertRepair(t, `["a";"b"]`, `["a","b"]`)
+	assertRepair(t, "{a: 1;\n b: 2\n}", "{\"a\": 1,\n \"b\": 2\n}")
+	assertRepair(t, `{"a":1};`, `{"a":1}`)
+
+	// character references are no separators
+	assertRepair(t, `{a: Tom &amp; Jerry; b: &#39;x&#39;}`, `{"a": "Tom &amp; Jerry", "b": "&#39;x&#39;"}`)
+
+	// semicolons which do not separate items are kept
+	assertRepair(t, `{"text": "hello; world}`, `{"text": "hello; world"}`)
+	assertRepair(t, `[1,2,3,.;.]`, `[1,2,3,".;."]`)
+	assertRepair(t, `[Tru;e, `, `["Tru;e"] `)
+	assertRepair(t, `"The TV has a 24"; screen"`, `"The TV has a 24\"; screen"`)
+	assertRepair(t, `[1,; 2]`, `[1,"; 2"]`)
+	assertRepair(t, `hello ; world`, `"hello ; world"`)
+	assertRepair(t, `{a; 'b'}`, `{"a;": "b"}`)
+
+	// a quote after a delimiter which is followed by a semicolon is no end quote
+	assertRepair(t, `{"a": {"b": {"c: [1, {";": "e"}]}}}`, `{"a": {"b": {"c": [1, {";": "e"}]}}}`)
+}
+
+// TestShouldRepairTokenShapes tests keeping unquoted well-known tokens whole.
+func TestShouldRepairTokenShapes(t *testing.T) {
+	assertRepair(t, `{contact: bob@example.com}`, `{"contact": "bob@example.com"}`)
+	assertRepair(t, `[123@example.com, @handle, @scope/pkg]`, `["123@example.com", "@handle", "@scope/pkg"]`)
+	assertRepair(t, `{m: mailto:bob@example.com, n: 1}`, `{"m": "mailto:bob@example.com", "n": 1}`)
+	assertRepair(t, `{m: mailto:bob@example.com?subject=hi}`, `{"m": "mailto:bob@example.com?subject=hi"}`)
+	assertRepair(t, "[@a\n@b]", "[\"@a\",\n\"@b\"]")
+
+	// part of a longer string
+	assertRepair(t, `{a: bob@example.com is here}`, `{"a": "bob@example.com is here"}`)
+	assertRepair(t, `@abc"`, `"@abc"`)
+	assertRepair(t, `{"a": @abc"}`, `{"a": "@abc"}`)
+	assertRepair(t, `[@abc "x"]`, `["@abc", "x"]`)
+
+	// placeholders of logging templates
+	assertRepair(t, `{msg: user %s logged in, n: %d}`, `{"msg": "user %s logged in", "n": "%d"}`)
+	assertRepair(t, `{msg: hello %{name}!, fmt: %-10s|%05.2f}`, `{"msg": "hello %{name}!", "fmt": "%-10s|%05.2f"}`)
+	assertRepair(t, `[%{user.id}, %(name)s, 50%]`, `["%{user.id}", "%(name)s", "50%"]`)
+
+	ticket := regexp.MustCompile(`^[A-Z]+-\d+:\d+`)
+	assertRepair(t, `{ref: JIRA-12:3}`, `{"ref": "JIRA-12:3"}`, WithTokenShapes(ticket))
+}
+
+// TestShouldRepairArrowSeparators tests repairing "=>" used instead of a colon.
+func TestShouldRepairArrowSeparators(t *testing.T) {
+	assertRepair(t, `{"a" => 1}`, `{"a" : 1}`)
+	assertRepair(t, `{a=>1, b => [1]}`, `{"a":1, "b" : [1]}`)
+	assertRepair(t, "{'a' =>\n 1}", "{\"a\" :\n 1}")
+	assertRepair(t, `{"a"=>{"b"=>2}}`, `{"a":{"b":2}}`)
+	assertRepair(t, `{1 => "x"}`, `{"1" : "x"}`)
+}
+
+// TestShouldStripMarkdownFences tests removing the fences of Markdown code blocks.
+func TestShouldStripMarkdownFences(t *testing.T) {
+	assertRepair(t, "```json\n{\"a\":1}\n```", "\n{\"a\":1}\n")
+	assertRepair(t, "```\n[1,2]\n```\n", "\n[1,2]\n\n")
+	assertRepair(t, "```json \n{\"a\":1} ```", " \n{\"a\":1} ")
+	assertRepair(t, "```true```", "true")
+	assertRepair(t, "```[1]```", "[1]")
+
+	// the container around the code block is kept
+	assertRepair(t, "[```json\n{\"a\":1}\n```]", "[\n{\"a\":1}\n]")
+	assertRepair(t, "{\"a\": ```json\n[1]\n```}", "{\"a\": \n[1]\n}")
+}
+
+// TestShouldStripMarkdownMarkup tests removing headings, list bullets and bold markers with WithMarkdown.
+func TestShouldStripMarkdownMarkup(t *testing.T) {
+	markdown := WithMarkdown()
+	assertRepair(t, "### Result\n\n```json\n{\"a\": 1}\n```", "\n\n\n{\"a\": 1}\n", markdown)
+	assertRepair(t, "**```json\n[1, 2]\n```**", "\n[1, 2]\n", markdown)
+	assertRepair(t, `{**"name"**: "John"}`, `{"name": "John"}`, markdown)
+	assertRepair(t, "- {\"a\": 1}\n- {\"b\": 2}\n", "[\n{\"a\": 1},\n{\"b\": 2}\n\n]", markdown)
+	assertRepair(t, "[\n  - 1,\n  * 2\n]", "[\n  1,\n  2\n]", markdown)
+
+	// markup inside strings and in the middle of a line is kept
+	assertRepair(t, `{"a": "- b **c**"}`, `{"a": "- b **c**"}`, markdown)
+	assertRepair(t, `[1, -2, #x]`, `[1, -2, "#x"]`, markdown)
+}
+
+// TestShouldStripTagWrappers tests removing XML or HTML tags wrapped around the value.
+func TestShouldStripTagWrappers(t *testing.T) {
+	assertRepair(t, `<tool_call>{"name": "x"}</tool_call>`, `{"name": "x"}`)
+	assertRepair(t, "<json>\n[1, 2]\n</json>", "\n[1, 2]\n")
+	assertRepair(t, `<tool_call name="search">{a: 1}</tool_call>`, `{"a": 1}`)
+	assertRepair(t, `<json>{"a": 1`, `{"a": 1}`)
+	assertRepair(t, `["<json>", <b>]`, `["<json>", "<b>"]`)
+
+	assertRepair(t, `<payload>[1]</payload>`, `[1]`, WithTagWrappers("payload"))
+	assertRepair(t, `<answer>[1]</answer>`, `[1]`, WithTagWrappers("output", "result", "response", "answer"))
+	assertRepairFailure(t, `<answer>[1]</answer>`, `unexpected character: '['`, 8)
+	assertRepairFailure(t, `<json>[1]</json>`, `unexpected character: '['`, 6, WithTagWrappers())
+
+	var report Report
+	_, err := JSONRepair(`<json>[1]</json>`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 0, Kind: RepairStrippedWrapper, Message: "removed tag wrapper"}, {Position: 9, Kind: RepairStrippedWrapper, Message: "removed tag wrapper"}}, report.Repairs)
+}
+
+// TestShouldRemoveHTMLComments tests removing HTML comments around and inside the document.
+func TestShouldRemoveHTMLComments(t *testing.T) {
+	assertRepair(t, "<!-- data -->\n{\"a\": 1}\n<!-- end -->", "\n{\"a\": 1}\n")
+	assertRepair(t, `{"a": 1 <!-- x --> , "b": [1, <!--y-->2]}`, `{"a": 1  , "b": [1, 2]}`)
+	assertRepair(t, `{a: b<!-- c -->}`, `{"a": "b"}`)
+	assertRepair(t, `{"a": "<!-- keep -->"}`, `{"a": "<!-- keep -->"}`)
+	assertRepair(t, `[1 <!-- unterminated`, `[1] `)
+	assertRepair(t, `{a: x < y}`, `{"a": "x < y"}`)
+}
+
+// TestShouldRemoveHashCommentsWhenEnabled tests removing Python, YAML and shell style comments.
+func TestShouldRemoveHashCommentsWhenEnabled(t *testing.T) {
+	hash := WithHashComments()
+	assertRepair(t, "# config\n{\"a\": 1, # first\n \"b\": [1, 2] # list\n}", "\n{\"a\": 1, \n \"b\": [1, 2] \n}", hash)
+	assertRepair(t, "{a: b # comment\n}", "{\"a\": \"b\" \n}", hash)
+	assertRepair(t, "[1,#x\n2]", "[1,\n2]", hash)
+	assertRepair(t, `{lang: C#, b: 1}`, `{"lang": "C#", "b": 1}`, hash)
+	assertRepair(t, `{"a": "x # y"}`, `{"a": "x # y"}`, hash)
+
+	// opt-in only
+	assertRepair(t, "{a: b # c}", `{"a": "b # c"}`)
+}
+
 // TestShouldRepairRegularExpressions tests repairing regular expressions in JSON.
 func TestShouldRepairRegularExpressions(t *testing.T) {
 	assertRepair(t, `{regex: /standalone-styles.css/}`, `{"regex": "/standalone-styles.css/"}`)
@@ -543,6 +1085,185 @@ func TestShouldConcatenateStrings(t *testing.T) {
 	assertRepair(t, `["hello +]`, `["hello"]`)
 }
 
+// TestStringNewlinePolicy tests escaping raw newlines in strings or ending the strings at them.
+func TestStringNewlinePolicy(t *testing.T) {
+	escape := WithStringNewlinePolicy(StringNewlineEscape)
+	assertRepair(t, "{\"a\": \"line 1\nline 2\", \"b\": 1}", `{"a": "line 1\nline 2", "b": 1}`, escape)
+	assertRepair(t, "[\"a\n1,2]", `["a\n1",2]`, escape)
+	assertRepair(t, "[\"a\n1,2]", "[\"a\",\n1,2]")
+
+	end := WithStringNewlinePolicy(StringNewlineEnd)
+	assertRepair(t, "[\"a\n\"b\"]", "[\"a\",\n\"b\"]", end)
+	assertRepair(t, "[\"a\n\"b\"]", `["a\n\"b"]`)
+	assertRepair(t, "{\"a\": \"open\n\"b\": 2}", "{\"a\": \"open\",\n\"b\": 2}", end)
+	assertRepair(t, "[\"a\r\n\"b\"]", "[\"a\",\r\n\"b\"]", end)
+	assertRepair(t, "[\"x\\ny\"]", `["x\ny"]`, end)
+}
+
+// TestMaxStringScan tests limiting the scan for the end quote of a string.
+func TestMaxStringScan(t *testing.T) {
+	limit := WithMaxStringScan(10)
+	assertRepair(t, `{"a": "abcdefghij", "b": 1}`, `{"a": "abcdefghij", "b": 1}`, limit)
+	assertRepair(t, `{"a": "ab, "b": 1}`, `{"a": "ab", "b": 1}`, limit)
+	// the end quote is looked for up to the limit after the first delimiter in the string
+	assertRepair(t, `["abc, defghi"]`, `["abc, defghi"]`, limit)
+	assertRepair(t, `["abc, defghijklmnop"]`, `["abc", "defghijklmnop"]`, limit)
+	assertRepair(t, `{"a": "ab, "b": 1, "c": "abcdefghijklmnop"}`, `{"a": "ab", "b": 1, "c": "abcdefghijklmnop"}`, limit)
+
+	// a string without delimiters is not cut
+	limit = WithMaxStringScan(4)
+	assertRepair(t, `{"a": "0123456789abc"}`, `{"a": "0123456789abc"}`, limit)
+	assertRepair(t, `"abcdefghijklmnop`, `"abcdefghijklmnop"`, limit)
+	assertRepair(t, `{\"stringified\": \"content\"}`, `{"stringified": "content"}`, limit)
+}
+
+// TestMaxDepth tests limiting the nesting depth.
+func TestMaxDepth(t *testing.T) {
+	limit := WithMaxDepth(3)
+	assertRepair(t, `{"a": {"b": [1]}}`, `{"a": {"b": [1]}}`, limit)
+	assertRepair(t, `[[[1`, `[[[1]]]`, limit)
+	assertRepairFailure(t, `{"a": {"b": [[1]]}}`, `maximum nesting depth exceeded`, 13, limit)
+	assertRepairFailure(t, `f(g(h(i(1))))`, `maximum nesting depth exceeded`, 7, limit)
+
+	// the default limit protects the stack
+	_, err := JSONRepair(strings.Repeat("[", 100000))
+	require.ErrorIs(t, err, ErrMaxDepth)
+	repaired, err := JSONRepair(strings.Repeat("[", 10001)+strings.Repeat("]", 10001), WithMaxDepth(0))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("[", 10001)+strings.Repeat("]", 10001), repaired)
+}
+
+// TestMaxInputSize tests rejecting oversized input.
+func TestMaxInputSize(t *testing.T) {
+	assertRepair(t, `{a: 1}`, `{"a": 1}`, WithMaxInputSize(6))
+
+	_, err := JSONRepair(`{a: 12}`, WithMaxInputSize(6))
+	require.ErrorIs(t, err, ErrInputTooLarge)
+	assert.EqualError(t, err, "input too large: 7 bytes exceed the limit of 6 bytes")
+
+	_, _, _, err = Extract(`text {a: 12}`, WithMaxInputSize(6))
+	require.ErrorIs(t, err, ErrInputTooLarge)
+	_, err = RepairYAMLBlock("a: 12345", WithMaxInputSize(6))
+	require.ErrorIs(t, err, ErrInputTooLarge)
+}
+
+// TestMaxOutputSize tests rejecting an output which the repairs expanded beyond the limit.
+func TestMaxOutputSize(t *testing.T) {
+	assertRepair(t, `{a: 1}`, `{"a": 1}`, WithMaxOutputSize(8))
+
+	_, err := JSONRepair(`{a: 12}`, WithMaxOutputSize(8))
+	require.ErrorIs(t, err, ErrOutputTooLarge)
+	assert.EqualError(t, err, "output too large: 9 bytes exceed the limit of 8 bytes")
+
+	// the input is within the limit, the escaped output is not
+	_, err = JSONRepair("\"\t\t\t\t\"", WithMaxOutputSize(8))
+	require.ErrorIs(t, err, ErrOutputTooLarge)
+
+	_, _, _, err = Extract(`text {a: 12}`, WithMaxOutputSize(8))
+	require.ErrorIs(t, err, ErrOutputTooLarge)
+	_, err = ExtractAll(`[1] and [2]`, WithMaxOutputSize(5))
+	require.ErrorIs(t, err, ErrOutputTooLarge)
+	_, err = RepairQueryString("a=1&b=2", WithMaxOutputSize(8))
+	require.ErrorIs(t, err, ErrOutputTooLarge)
+}
+
+// TestAllErrors tests collecting the errors after the first one.
+func TestAllErrors(t *testing.T) {
+	text := "[1, 2] x] yz\n# !"
+	_, err := JSONRepair(text, WithAllErrors())
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+	assert.EqualError(t, err, "unexpected character: 'x' at position 7\n"+
+		"unexpected character: 'y' at position 10\n"+
+		"unexpected character: '#' at position 13\n"+
+		"unexpected character: '!' at position 15")
+
+	var errs interface{ Unwrap() []error }
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs.Unwrap(), 4)
+	last := errs.Unwrap()[3].(*Error)
+	assert.Equal(t, Error{Err: ErrUnexpectedCharacter, Detail: "'!'", Position: 15, ByteOffset: 15, Line: 2, Column: 3}, *last)
+
+	var first *Error
+	require.ErrorAs(t, err, &first)
+	assert.Equal(t, 7, first.Position)
+
+	// without the option, only the first error is returned
+	_, err = JSONRepair(text)
+	assert.EqualError(t, err, "unexpected character: 'x' at position 7")
+
+	// errors after which the repair can not go on are returned alone
+	_, err = JSONRepair("[1, 2] x", WithAllErrors())
+	assert.EqualError(t, err, "unexpected character: 'x' at position 7")
+	_, err = JSONRepair("[[1]", WithAllErrors(), WithMaxDepth(1))
+	assert.EqualError(t, err, "maximum nesting depth exceeded at position 1")
+}
+
+// cancelingContext is a context which is canceled after its error was checked a number of times.
+type cancelingContext struct {
+	context.Context
+	checks int
+}
+
+func (c *cancelingContext) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+// TestJSONRepairContext tests stopping the repair with a context.
+func TestJSONRepairContext(t *testing.T) {
+	result, err := JSONRepairContext(context.Background(), `{a: 1}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = JSONRepairContext(ctx, `{a: 1}`)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the context is checked while repairing
+	items := "[" + strings.Repeat("1, ", 10000) + "1"
+	_, err = JSONRepairContext(&cancelingContext{Context: context.Background(), checks: 2}, items)
+	require.ErrorIs(t, err, context.Canceled)
+	long := `"` + strings.Repeat("a", 10000)
+	_, err = JSONRepairContext(&cancelingContext{Context: context.Background(), checks: 2}, long)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+// TestNULPolicy tests stripping or escaping NUL bytes.
+func TestNULPolicy(t *testing.T) {
+	strip := WithNULPolicy(NULStrip)
+	assertRepair(t, "{\"a\": \"x\x00y\"}", `{"a": "xy"}`, strip)
+	assertRepair(t, "\x00[1,\x002]\x00", `[1,2]`, strip)
+	assertRepair(t, "[ab\x00c]", `["abc"]`, strip)
+
+	escape := WithNULPolicy(NULEscape)
+	assertRepair(t, "{\"a\x00b\": \"x\x00y\"}", `{"a\u0000b": "x\u0000y"}`, escape)
+	assertRepair(t, "\x00[1,\x002]\x00", `[1,2]`, escape)
+	assertRepair(t, "{\"a\":\x00 'b\x00'}", `{"a": "b\u0000"}`, escape)
+	assertRepair(t, "[ab\x00c]", `["ab\u0000c"]`, escape)
+}
+
+// TestShouldJoinSurrogatePairsOfConcatenatedStrings tests joining a surrogate pair split across concatenated strings.
+func TestShouldJoinSurrogatePairsOfConcatenatedStrings(t *testing.T) {
+	assertRepair(t, `"a\ud83d" + "\ude00b"`, `"a\ud83d\ude00b"`)
+	assertRepair(t, `{"e": '\ud83d' +
+ '\ude00'}`, `{"e": "\ud83d\ude00"}`)
+	assertRepair(t, `"\ud83d" + "\ude00" + "\ud83d"`, `"\ud83d\ude00\ufffd"`)
+
+	// a lone half is still repaired
+	assertRepair(t, `["\ud83d" + "x"]`, `["\ufffdx"]`)
+	assertRepair(t, `"a\\ud83d" + "\ude00b"`, `"a\\ud83d\ufffdb"`)
+
+	result, err := JSONRepair(`"a\ud83d" + "\ude00b"`)
+	require.NoError(t, err)
+	var decoded string
+	require.NoError(t, json.Unmarshal([]byte(result), &decoded))
+	assert.Equal(t, "a😀b", decoded)
+}
+
 // TestShouldRepairMissingCommaBetweenArrayItems tests repairing missing comma between array items in JSON strings.
 func TestShouldRepairMissingCommaBetweenArrayItems(t *testing.T) {
 	assertRepair(t, `{"array": [{}{}]}`, `{"array": [{},{}]}`)
@@ -556,6 +1277,50 @@ func TestShouldRepairMissingCommaBetweenArrayItems(t *testing.T) {
 	assertRepair(t, "[\n{},\n{}\n]", "[\n{},\n{}\n]")
 }
 
+// TestShouldKeepCommasInsideArrayStrings tests that commas inside strings in arrays are kept,
+// and that a missing end quote is only inserted before a comma when the string is not closed.
+func TestShouldKeepCommasInsideArrayStrings(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		// commas inside closed strings are content
+		{`["a,", "b"]`, `["a,", "b"]`},
+		{`["a,"]`, `["a,"]`},
+		{`[","]`, `[","]`},
+		{`[", "]`, `[", "]`},
+		{`[",", ","]`, `[",", ","]`},
+		{`["a", ","]`, `["a", ","]`},
+		{`[",a"]`, `[",a"]`},
+		{`["a,b", "c"]`, `["a,b", "c"]`},
+		{`["a,b,"]`, `["a,b,"]`},
+		{`["a,"  ,  "b,"]`, `["a,"  ,  "b,"]`},
+		{`[["a,"], ","]`, `[["a,"], ","]`},
+		{`["a\",", "b"]`, `["a\",", "b"]`},
+		{`{"a": "b,", "c": ","}`, `{"a": "b,", "c": ","}`},
+
+		// trailing commas after strings ending with a comma
+		{`["a,",]`, `["a,"]`},
+		{`["a,",`, `["a,"]`},
+		{`[1, "a,"`, `[1, "a,"]`},
+		{`["a,", 1`, `["a,", 1]`},
+
+		// missing end quotes
+		{`["a,`, `["a"]`},
+		{`[",`, `[""]`},
+		{`["a, b`, `["a, b"]`},
+		{`["a, "b"]`, `["a", "b"]`},
+		{`["x", "y,]`, `["x", "y"]`},
+		{"[\"a,\n\"b\"]", "[\"a\",\n\"b\"]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			assertRepair(t, test.text, test.expected)
+		})
+	}
+}
+
 // TestShouldRepairMissingCommaBetweenObjectProperties tests repairing missing comma between object properties in JSON strings.
 func TestShouldRepairMissingCommaBetweenObjectProperties(t *testing.T) {
 	assertRepair(t, "{\"a\":2\n\"b\":3\n}", "{\"a\":2,\n\"b\":3\n}")
@@ -645,6 +1410,351 @@ func TestShouldRepairNumberWithLeadingZero(t *testing.T) {
 	assertRepair(t, "{value:0789}", "{\"value\":\"0789\"}")
 }
 
+// TestShouldRepairTripleQuotedStrings tests repairing Python style triple quoted strings.
+func TestShouldRepairTripleQuotedStrings(t *testing.T) {
+	assertRepair(t, "\"\"\"multi\nline\"\"\"", `"multi\nline"`)
+	assertRepair(t, "'''multi\nline'''", `"multi\nline"`)
+	assertRepair(t, "{\"doc\": \"\"\"\n  Say \"hi\"\n\"\"\", \"b\": 1}", `{"doc": "\n  Say \"hi\"\n", "b": 1}`)
+	assertRepair(t, `['''it's''', '''a\tb''']`, `["it's", "a\tb"]`)
+	assertRepair(t, `"""truncated`, `"truncated"`)
+	assertRepair(t, `"""x\`, `"x\\"`)
+	assertRepair(t, `"""a\uzz"""`, `"auzz"`)
+	assertRepair(t, `"""a\u00e9\n"""`, `"a\u00e9\n"`)
+	assertRepair(t, "\"\"\"a\\\nb\"\"\"", `"a\nb"`)
+	assertRepair(t, `{"""key""": 1}`, `{"key": 1}`)
+	assertRepair(t, `{"a": ""}`, `{"a": ""}`)
+	assertRepair(t, `["", ""]`, `["", ""]`)
+	assertRepair(t, `"""a\ud83d"""`, `"a\ufffd"`)
+	assertRepair(t, `"""a\ud83d"""`, `"a"`, WithSurrogatePolicy(SurrogateDrop))
+
+	var report Report
+	assertRepair(t, "'''a\tb'''", `"a\tb"`, WithReport(&report))
+	assert.Equal(t, []Repair{
+		{Position: 0, Kind: RepairNonStandardQuote, Message: "replaced quotes with double quotes"},
+		{Position: 4, Kind: RepairUnescapedControlChar, Message: "escaped control character"},
+	}, report.Repairs)
+}
+
+// TestShouldRepairRubyHashSyntax tests repairing Ruby hashes with symbol keys and hash rockets.
+func TestShouldRepairRubyHashSyntax(t *testing.T) {
+	assertRepair(t, `{:name => "John", "age" => 30}`, `{"name" : "John", "age" : 30}`)
+	assertRepair(t, `{"a"=>1}`, `{"a":1}`)
+	assertRepair(t, `{:a=>:b}`, `{"a":"b"}`)
+	assertRepair(t, `{:"full name" => "John Doe"}`, `{"full name" : "John Doe"}`)
+	assertRepair(t, `{:user_1 => {:tags => ["a", :b]}}`, `{"user_1" : {"tags" : ["a", "b"]}}`)
+	assertRepair(t, `{name: "John"}`, `{"name": "John"}`)
+
+	// an arrow only separates a key from its value in an array or object
+	assertRepair(t, `array('a' =>`, `{"a" :null}`)
+	assertRepair(t, `aray('a' =>`, `"a\" =>"`)
+	assertRepair(t, `{"x": aray('a' =>`, `{"x": "a\" =>"}`)
+}
+
+// TestShouldRepairPHPArraySyntax tests repairing PHP arrays and array(...) constructs.
+func TestShouldRepairPHPArraySyntax(t *testing.T) {
+	assertRepair(t, `array(1, 2, 3)`, `[1, 2, 3]`)
+	assertRepair(t, `array()`, `[]`)
+	assertRepair(t, `array('a' => 1, 'b' => array(2, 3))`, `{"a" : 1, "b" : [2, 3]}`)
+	assertRepair(t, `['a' => 1]`, `{"a" : 1}`)
+	assertRepair(t, `[0=>'x',1=>'y']`, `{"0":"x","1":"y"}`)
+	assertRepair(t, `{"items": array('a' => true)}`, `{"items": {"a" : true}}`)
+	assertRepair(t, "array (\n  'a' => 1,\n  'b' => \n  array (\n    0 => 2,\n  ),\n)",
+		"{\n  \"a\" : 1,\n  \"b\" : \n  {\n    \"0\" : 2\n  }\n}")
+	assertRepair(t, `['a' =>`, `{"a" :null}`)
+	assertRepair(t, `[0 => 'x', 1]`, `{"0" : "x", "1": 1}`)
+	assertRepair(t, `['a' => 1, 2, 3]`, `{"a" : 1, "0": 2, "1": 3}`)
+	assertRepair(t, `[1, 5 => 'x', 2, '7' => 3, 4]`, `{"0": 1, "5" : "x", "6": 2, "7" : 3, "8": 4}`)
+	assertRepair(t, `array(1, 'a' => array(2, 'k' => 3))`, `{"0": 1, "a" : {"0": 2, "k" : 3}}`)
+	assertRepair(t, `[array]`, `["array"]`)
+}
+
+// TestShouldSkipShebang tests skipping a leading shebang line.
+func TestShouldSkipShebang(t *testing.T) {
+	assertRepair(t, "#!/usr/bin/env node\n{a:1}", "{\"a\":1}")
+	assertRepair(t, "#!/bin/sh\r\n[1, 2]", "[1, 2]")
+	assertRepairFailure(t, "#!/bin/sh\n{}x", `unexpected character: 'x'`, 12)
+
+	var report Report
+	_, err := JSONRepair("#!/usr/bin/env node\n{}", WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/usr/bin/env node\n", report.Preamble)
+}
+
+// TestShouldSkipLogPrefixWhenEnabled tests skipping a log line prefix before the JSON document.
+func TestShouldSkipLogPrefixWhenEnabled(t *testing.T) {
+	assertRepair(t, `2024-01-01T10:00:00Z INFO {"a":1}`, `{"a":1}`, WithSkipLogPrefix())
+	assertRepair(t, `level=info msg={a:1}`, `{"a":1}`, WithSkipLogPrefix())
+	assertRepair(t, `[INFO] [main] 12:00 payload=[1,2`, `[1,2]`, WithSkipLogPrefix())
+	assertRepair(t, `[1, 2]`, `[1, 2]`, WithSkipLogPrefix())
+	assertRepair(t, `{"a":1}`, `{"a":1}`, WithSkipLogPrefix())
+
+	var report Report
+	_, err := JSONRepair(`#!/bin/sh`+"\n"+`[WARN] {}`, WithSkipLogPrefix(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n[WARN] ", report.Preamble)
+
+	// positions are relative to the original text
+	assertRepairFailure(t, `INFO {}x`, `unexpected character: 'x'`, 7, WithSkipLogPrefix())
+	assertRepairFailure(t, `[INFO] done`, `unexpected character: 'd'`, 7, WithSkipLogPrefix())
+}
+
+// TestShouldSkipPreambleWhenEnabled tests skipping a shebang line and front-matter before the JSON document.
+func TestShouldSkipPreambleWhenEnabled(t *testing.T) {
+	assertRepair(t, "#!/usr/bin/env node\n{a:1}", "{\"a\":1}", WithSkipPreamble())
+	assertRepair(t, "---\ntitle: test\n---\n{a:1}", "{\"a\":1}", WithSkipPreamble())
+	assertRepair(t, "#!/bin/sh\n---\ntitle: test\n---\n[1,2", "[1,2]", WithSkipPreamble())
+	assertRepair(t, "{a:1}", "{\"a\":1}", WithSkipPreamble())
+
+	var report Report
+	_, err := JSONRepair("#!/bin/sh\n---\nid: 1\n---\n{}", WithSkipPreamble(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n---\nid: 1\n---\n", report.Preamble)
+
+	// positions are relative to the original text
+	assertRepairFailure(t, "---\nid: 1\n---\n{}foo", `unexpected character: 'f'`, 16, WithSkipPreamble())
+
+	// opt-in only
+	assertRepairFailure(t, "---\nid: 1\n---\n{}", `unexpected character: ':'`, 6)
+}
+
+// TestShouldRepairWindows1252QuotesWhenCharsetDetectionEnabled tests repairing smart quotes encoded as Windows-1252.
+func TestShouldRepairWindows1252QuotesWhenCharsetDetectionEnabled(t *testing.T) {
+	assertRepair(t, "{\x93name\x94: \x93John\x94}", `{"name": "John"}`, WithCharsetDetection())
+	assertRepair(t, "{'a': 'caf\xe9'}", `{"a": "café"}`, WithCharsetDetection())
+	assertRepair(t, "{\"a\": \"\xe2\x98\x85\"}", `{"a": "★"}`, WithCharsetDetection())
+
+	var report Report
+	_, err := JSONRepair("[\x93a\x94]", WithCharsetDetection(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, "windows-1252", report.Charset)
+}
+
+// TestShouldRepairGoSyntaxWhenEnabled tests repairing values printed by the Go fmt package.
+func TestShouldRepairGoSyntaxWhenEnabled(t *testing.T) {
+	// %#v
+	assertRepair(t, `map[string]interface {}{"a":1}`, `{"a":1}`, WithGoSyntax())
+	assertRepair(t, `map[string]interface {}{"a":[]interface {}{1, "b"}}`, `{"a":[1, "b"]}`, WithGoSyntax())
+	assertRepair(t, `main.Person{Name:"John", Age:30, Tags:[]string(nil)}`, `{"Name":"John", "Age":30, "Tags":null}`, WithGoSyntax())
+	assertRepair(t, `&main.Person{Name:"John", Next:(*main.Person)(nil)}`, `{"Name":"John", "Next":null}`, WithGoSyntax())
+	assertRepair(t, `[2]int{1, 2}`, `[1, 2]`, WithGoSyntax())
+	assertRepair(t, `map[string][]int{"a":[]int{1}}`, `{"a":[1]}`, WithGoSyntax())
+
+	// %+v
+	assertRepair(t, `{Name:John Age:30}`, `{"Name":"John", "Age":30}`, WithGoSyntax())
+	assertRepair(t, `{Name:John Address:{City:NYC Zip:10001} Tags:[a b]}`,
+		`{"Name":"John", "Address":{"City":"NYC", "Zip":10001}, "Tags":["a", "b"]}`, WithGoSyntax())
+	assertRepair(t, `{Err:<nil> Ptr:nil}`, `{"Err":null, "Ptr":null}`, WithGoSyntax())
+	assertRepair(t, `&{Name:x Ptr:<nil>}`, `{"Name":"x", "Ptr":null}`, WithGoSyntax())
+	assertRepair(t, `[&{Name:x} &{Name:y}]`, `[{"Name":"x"}, {"Name":"y"}]`, WithGoSyntax())
+
+	// %v
+	assertRepair(t, `map[a:1 b:2]`, `{"a":1, "b":2}`, WithGoSyntax())
+	assertRepair(t, `map[a:map[b:true] c:[1 2 3]]`, `{"a":{"b":true}, "c":[1, 2, 3]}`, WithGoSyntax())
+	assertRepair(t, `map[]`, `{}`, WithGoSyntax())
+
+	// not a whole Go literal
+	assertRepair(t, `{a $}`, `{"a $": null}`, WithGoSyntax())
+	assertRepair(t, `callback_123({);`, `null`, WithGoSyntax())
+	assertRepair(t, `{"a": new Date(`, `{"a": null}`, WithGoSyntax())
+	assertRepair(t, `nilly`, `"nilly"`, WithGoSyntax())
+	assertRepairFailure(t, `map[- $]`, "unexpected character: '['", 3, WithGoSyntax())
+
+	// opt-in only
+	assertRepairFailure(t, `{Name:John Age:30}`, "unexpected end of json string", 18)
+}
+
+// TestShouldRepairEqualsSeparatorWhenEnabled tests repairing Java toString output with key=value pairs.
+func TestShouldRepairEqualsSeparatorWhenEnabled(t *testing.T) {
+	assertRepair(t, `{name=John, age=30, tags=[a, b]}`, `{"name":"John", "age":30, "tags":["a", "b"]}`, WithEqualsSeparator())
+	assertRepair(t, `{"name"="John"}`, `{"name":"John"}`, WithEqualsSeparator())
+	assertRepair(t, `{query=a=b}`, `{"query":"a=b"}`, WithEqualsSeparator())
+	assertRepair(t, `Person{name=John, address=Address{city=NYC}}`, `{"name":"John", "address":{"city":"NYC"}}`, WithEqualsSeparator())
+	assertRepair(t, `Person(name=John, age=30, friend=null)`, `{"name":"John", "age":30, "friend":null}`, WithEqualsSeparator())
+	assertRepair(t, `com.example.Empty()`, `{}`, WithEqualsSeparator())
+	assertRepair(t, `callback({"a":1})`, `{"a":1}`, WithEqualsSeparator())
+
+	// opt-in only: without the option the pair is a key without value
+	assertRepairFailure(t, `Person{name=John}`, "unexpected character: '{'", 6)
+	assertRepair(t, `{name=John}`, `{"name=John": null}`)
+	assertRepair(t, `{"a":1,="b"}`, `{"a":1,"=":"b"}`)
+}
+
+// TestShouldRepairEqualsSeparator tests repairing "=" and ":=" between keys and values.
+func TestShouldRepairEqualsSeparator(t *testing.T) {
+	equals := WithEqualsSeparator()
+	assertRepair(t, `{a = 1, b = "x"}`, `{"a" : 1, "b" : "x"}`, equals)
+	assertRepair(t, `{name=John}`, `{"name":"John"}`, equals)
+	assertRepair(t, `{"a" = 1}`, `{"a" : 1}`, equals)
+	assertRepair(t, `{a := 1, "b":=2}`, `{"a" : 1, "b":2}`, equals)
+	assertRepair(t, `{url = https://example.com/?a=b}`, `{"url" : "https://example.com/?a=b"}`, equals)
+	assertRepair(t, "{\n  host = localhost\n  port = 8080\n}", "{\n  \"host\" : \"localhost\",\n  \"port\" : 8080\n}", equals)
+
+	// a key which is empty or starts with an equals sign is no key=value pair
+	assertRepair(t, `{"a": 1, = 2}`, `{"a": 1, "= 2": null}`, equals)
+	assertRepair(t, `{=abc`, `{"=abc":null}`, equals)
+	assertRepair(t, `{"a":1,="b"}`, `{"a":1,"=":"b"}`, equals)
+}
+
+// TestShouldRepairReplacementCharQuotesWhenEnabled tests treating U+FFFD as a lost smart quote.
+func TestShouldRepairReplacementCharQuotesWhenEnabled(t *testing.T) {
+	assertRepair(t, "{\uFFFDname\uFFFD: \uFFFDJohn\uFFFD}", `{"name": "John"}`, WithReplacementCharQuotes())
+	assertRepair(t, "[\uFFFDa\uFFFD, \uFFFDb\u201D]", `["a", "b"]`, WithReplacementCharQuotes())
+	assertRepair(t, "{\"a\": \"caf\uFFFD\"}", "{\"a\": \"caf\uFFFD\"}", WithReplacementCharQuotes())
+
+	// opt-in only
+	assertRepair(t, "[\uFFFDa\uFFFD]", "[\"\uFFFDa\uFFFD\"]")
+}
+
+// TestShouldKeepColonTokensWhole tests that times and ratios containing colons are turned into a single string.
+func TestShouldKeepColonTokensWhole(t *testing.T) {
+	assertRepair(t, `{time: 12:30:45}`, `{"time": "12:30:45"}`)
+	assertRepair(t, `{time: 12:30, next: 1}`, `{"time": "12:30", "next": 1}`)
+	assertRepair(t, `{"at": 09:15:00.250}`, `{"at": "09:15:00.250"}`)
+	assertRepair(t, `{ratio: 16:9}`, `{"ratio": "16:9"}`)
+	assertRepair(t, `[1:2:3, 4]`, `["1:2:3", 4]`)
+	assertRepair(t, `{"a": 12}`, `{"a": 12}`)
+	assertRepair(t, `{"12": 30}`, `{"12": 30}`)
+
+	// custom pattern, like a timestamp with a date
+	timestamp := regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}`)
+	assertRepair(t, `{at: 2024-01-02T10:00}`, `{"at": "2024-01-02T10:00"}`, WithColonTokens(timestamp))
+}
+
+// TestShouldReportRepairs tests that the report lists the repairs with their position in the input.
+func TestShouldReportRepairs(t *testing.T) {
+	var report Report
+	_, err := JSONRepair(`{name: 'John' age:30,}`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{
+		{Position: 1, Kind: RepairMissingQuote, Message: "added missing quotes"},
+		{Position: 7, Kind: RepairNonStandardQuote, Message: "replaced quotes with double quotes"},
+		{Position: 14, Kind: RepairMissingComma, Message: "added missing comma"},
+		{Position: 14, Kind: RepairMissingQuote, Message: "added missing quotes"},
+		{Position: 21, Kind: RepairTrailingComma, Message: "removed trailing comma"},
+	}, report.Repairs)
+
+	_, err = JSONRepair(`{"a":1}`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Empty(t, report.Repairs)
+
+	// positions are relative to the original text
+	_, err = JSONRepair("---\nid: 1\n---\n[1,2", WithSkipPreamble(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 18, Kind: RepairMissingBracket, Message: "added missing closing bracket"}}, report.Repairs)
+}
+
+// TestShouldReportStatistics tests counting the repairs and comments, and the size and duration.
+func TestShouldReportStatistics(t *testing.T) {
+	var report Report
+	input := "{a: 1, /* one */ b: 'x', // two\n c: [1, 2,],}"
+	_, err := JSONRepair(input, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"added missing quotes":               3,
+		"replaced quotes with double quotes": 1,
+		"removed trailing comma":             2,
+		"removed comment":                    2,
+	}, report.Counts())
+	assert.Equal(t, 2, report.Comments)
+	assert.Equal(t, len(input), report.Bytes)
+	assert.Positive(t, report.Duration)
+}
+
+// TestShouldReportRepairKinds tests reporting the kind of the repairs.
+func TestShouldReportRepairKinds(t *testing.T) {
+	var report Report
+	_, err := JSONRepair("[\"a\nb\", 'c' /* d */, 1,]", WithReport(&report))
+	require.NoError(t, err)
+	kinds := make([]RepairKind, 0, len(report.Repairs))
+	for _, repair := range report.Repairs {
+		kinds = append(kinds, repair.Kind)
+	}
+	assert.Equal(t, []RepairKind{RepairUnescapedControlChar, RepairStrippedComment, RepairNonStandardQuote, RepairTrailingComma}, kinds)
+	assert.Equal(t, Repair{Position: 3, Kind: RepairUnescapedControlChar, Message: "escaped control character"}, report.Repairs[0])
+
+	// the control characters of a string which is parsed again are reported once
+	_, err = JSONRepair("[\"a\tb, \"c\"]", WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Counts()["escaped control character"])
+
+	assert.Equal(t, "missing comma", RepairMissingComma.String())
+	assert.Equal(t, "other", RepairKind(-1).String())
+}
+
+// TestRepairKindsCoverMessages tests that every repair message has a kind.
+func TestRepairKindsCoverMessages(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	regexMessage := regexp.MustCompile(`logRepair\([^,]+, [^,]+, "([^"]+)", `)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		for _, match := range regexMessage.FindAllStringSubmatch(string(data), -1) {
+			assert.NotEqual(t, RepairOther, repairKind(match[1]), "%s: %s", file, match[1])
+		}
+	}
+	assert.Equal(t, RepairToken, repairKind("replaced url token"))
+}
+
+// TestShouldReportChanged tests reporting whether the output differs from the input.
+func TestShouldReportChanged(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     []Option
+		expected bool
+	}{
+		{`{"a": 1}`, nil, false},
+		{`{a: 1}`, nil, true},
+		{"[1, 2] // comment", nil, true},
+		{"\ufeff[1]", nil, true},
+		{`"\ud83d\ude00"`, nil, false},
+		{`[1,  2]`, []Option{WithWhitespacePolicy(WhitespaceCompact)}, true},
+		{`[1,2]`, []Option{WithWhitespacePolicy(WhitespaceCompact)}, false},
+		{`[1, 2`, []Option{WithSkipPreamble()}, true},
+	}
+	for _, tt := range tests {
+		var report Report
+		_, err := JSONRepair(tt.input, append(tt.opts, WithReport(&report))...)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, report.Changed, tt.input)
+	}
+
+	var report Report
+	_, err := JSONRepair(`{"a": 1} x`, WithReport(&report))
+	require.Error(t, err)
+	assert.False(t, report.Changed)
+}
+
+// TestShouldAnnotateRepairsWhenEnabled tests adding a comment at every repair site.
+func TestShouldAnnotateRepairsWhenEnabled(t *testing.T) {
+	assertRepair(t, `{"name": John}`, `{"name": "John" /* jsonrepair: added missing quotes */}`, WithAnnotations())
+	assertRepair(t, `[1,2`, `[1,2] /* jsonrepair: added missing closing bracket */`, WithAnnotations())
+	assertRepair(t, `["a" "b",]`,
+		`["a", /* jsonrepair: added missing comma */ "b" /* jsonrepair: removed trailing comma */]`, WithAnnotations())
+	assertRepair(t, "{\"a\": None,\n\"b\": \"x}",
+		"{\"a\": null /* jsonrepair: replaced keyword */,\n\"b\": \"x\" /* jsonrepair: added missing end quote */}", WithAnnotations())
+	assertRepair(t, `{"a":1}`, `{"a":1}`, WithAnnotations())
+
+	// the annotated output is the repaired output plus comments
+	for _, text := range []string{
+		`{name: 'John', tags: [a b], "c": undefined,}`,
+		"{\"a\":1}\n{\"b\":2}",
+		`callback({"a": "b" + "c"});`,
+		`{"a": [1, 2, {"b": 0023, "c": 2.`,
+		`{"a": 1}}]`,
+	} {
+		repaired, err := JSONRepair(text)
+		require.NoError(t, err)
+		annotated, err := JSONRepair(text, WithAnnotations())
+		require.NoError(t, err)
+		assert.Contains(t, annotated, "/* jsonrepair: ", text)
+
+		stripped, err := JSONRepair(annotated)
+		require.NoError(t, err)
+		assert.JSONEq(t, repaired, stripped, text)
+	}
+}
+
 // TestShouldThrowExceptionInCaseOfNonRepairableIssues tests that the JSON repair throws an exception for non-repairable issues.
 func TestShouldThrowExceptionInCaseOfNonRepairableIssues(t *testing.T) {
 	assertRepairFailure(t, "", "unexpected end of json string", 0)
@@ -654,6 +1764,9 @@ func TestShouldThrowExceptionInCaseOfNonRepairableIssues(t *testing.T) {
 	// assertRepairFailure(t, `{"a" ]`, "colon expected", 5)
 	assertRepairFailure(t, `{"a":2}foo`, `unexpected character: 'f'`, 7)
 	assertRepairFailure(t, `foo [`, `unexpected character: '['`, 4)
+	// a lone trailing backslash must not read past the end of the text
+	assertRepairFailure(t, `\`, "unexpected end of json string", 1)
+	assertRepairFailure(t, `{a"\`, "unexpected end of json string", 4)
 	assertRepairEqual(t, `"\\u26"`)
 	// assertRepairFailure(t, `"\\u26"`, `invalid unicode character '\\u26'`, 1)
 	assertRepairEqual(t, `"\\uZ000"`)
@@ -662,9 +1775,44 @@ func TestShouldThrowExceptionInCaseOfNonRepairableIssues(t *testing.T) {
 	// assertRepairFailure(t, `"\\uZ000`, `invalid unicode character '\\uZ000'`, 1)
 }
 
+// TestRepairStringLiteral tests repairing standalone string literals.
+func TestRepairStringLiteral(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`"hello"`, `"hello"`},
+		{`'hello'`, `"hello"`},
+		{`“smart”`, `"smart"`},
+		{"\"line\nbreak\"", `"line\nbreak"`},
+		{`"truncated`, `"truncated"`},
+		{`"\a"`, `"a"`},
+		{`\"escaped\"`, `"escaped"`},
+		{`"a" + "b"`, `"ab"`},
+		{`  "padded"  `, `"padded"`},
+		{`hello world`, `"hello world"`},
+		{`it's "quoted"`, `"it's \"quoted\""`},
+		{"tab\there", `"tab\there"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			result, err := RepairStringLiteral(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+
+	_, err := RepairStringLiteral("")
+	require.ErrorIs(t, err, ErrUnexpectedEnd)
+
+	_, err = RepairStringLiteral(`"a", "b"`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
+
 // assertRepairFailure is a helper function to check the JSON repair failure.
-func assertRepairFailure(t *testing.T, text, expectedErrMsg string, expectedPos int) {
-	result, err := JSONRepair(text)
+func assertRepairFailure(t *testing.T, text, expectedErrMsg string, expectedPos int, opts ...Option) {
+	result, err := JSONRepair(text, opts...)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), expectedErrMsg)
 	assert.Contains(t, err.Error(), fmt.Sprintf("%d", expectedPos))
@@ -677,8 +1825,8 @@ func assertRepairEqual(t *testing.T, text string) {
 	assert.Equal(t, text, result)
 }
 
-func assertRepair(t *testing.T, text string, expected string) {
-	result, err := JSONRepair(text)
+func assertRepair(t *testing.T, text string, expected string, opts ...Option) {
+	result, err := JSONRepair(text, opts...)
 	require.NoError(t, err)
 	assert.Equal(t, expected, result)
 }
@@ -0,0 +1,241 @@
+// Package jsonrepairtest provides utilities for testing and benchmarking JSON repair, like
+// the deterministic corruption of valid JSON documents.
+package jsonrepairtest
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// Corruption is a kind of realistic damage done to a JSON document.
+type Corruption int
+
+const (
+	// DropQuote removes a quote of a key or string, like {"a: 1}.
+	DropQuote Corruption = iota
+
+	// SwapBracket replaces a bracket or brace with its counterpart, like {"a": [1}}.
+	SwapBracket
+
+	// Truncate cuts the document off, like a stream which was interrupted: {"a": [1, 2.
+	Truncate
+
+	// SmartQuotes replaces the quotes of a key or string with typographic quotes, like {“a”: 1}.
+	SmartQuotes
+
+	// SingleQuotes replaces the quotes of a key or string with single quotes, like {'a': 1}.
+	SingleQuotes
+
+	// DropComma removes a comma between items or members, like [1 2].
+	DropComma
+
+	// TrailingComma adds a comma after the last item or member, like [1, 2,].
+	TrailingComma
+
+	// UnquoteKey removes both quotes of a key, like {a: 1}.
+	UnquoteKey
+)
+
+// corruptions lists all kinds of corruption, in order of their constants.
+var corruptions = []Corruption{DropQuote, SwapBracket, Truncate, SmartQuotes, SingleQuotes, DropComma, TrailingComma, UnquoteKey}
+
+// maxAttempts is the number of times a kind of corruption is picked for a single corruption,
+// before giving up because the document has no place for it.
+const maxAttempts = 20
+
+// Corrupt deterministically introduces n realistic corruptions into the valid JSON document,
+// picked by the given seed: dropped quotes, swapped brackets, truncation, smart and single
+// quotes, dropped and trailing commas and unquoted keys. The same arguments always give the
+// same result, so a corrupted document can be reproduced from its seed. Fewer corruptions are
+// made when the document has no place left for them, like after it was truncated to nothing.
+func Corrupt(valid string, seed int64, n int) string {
+	return CorruptWith(valid, seed, n, corruptions...)
+}
+
+// CorruptWith is like Corrupt, but picks from the given kinds of corruption only.
+func CorruptWith(valid string, seed int64, n int, kinds ...Corruption) string {
+	if len(kinds) == 0 {
+		return valid
+	}
+	random := rand.New(rand.NewSource(seed))
+	text := []rune(valid)
+	for k := 0; k < n; k++ {
+		for attempt := 0; attempt < maxAttempts; attempt++ {
+			if corrupted, ok := corrupt(text, kinds[random.Intn(len(kinds))], random); ok {
+				text = corrupted
+				break
+			}
+		}
+	}
+	return string(text)
+}
+
+// corrupt applies a corruption of the given kind at a random place, and returns false when
+// there is no place for it.
+func corrupt(text []rune, kind Corruption, random *rand.Rand) ([]rune, bool) {
+	structure := scan(text)
+	switch kind {
+	case DropQuote:
+		quotes := structure.filter(func(t token) bool { return t.char == '"' })
+		if len(quotes) == 0 {
+			return text, false
+		}
+		return remove(text, quotes[random.Intn(len(quotes))].pos), true
+	case SwapBracket:
+		brackets := structure.filter(func(t token) bool { return strings.ContainsRune("{}[]", t.char) })
+		if len(brackets) == 0 {
+			return text, false
+		}
+		t := brackets[random.Intn(len(brackets))]
+		return replace(text, t.pos, map[rune]rune{'{': '[', '}': ']', '[': '{', ']': '}'}[t.char]), true
+	case Truncate:
+		if len(text) < 2 {
+			return text, false
+		}
+		return text[:1+random.Intn(len(text)-1)], true
+	case SmartQuotes, SingleQuotes:
+		candidates := structure.strings(text)
+		if len(candidates) == 0 {
+			return text, false
+		}
+		s := candidates[random.Intn(len(candidates))]
+		opening, closing := '\'', '\''
+		if kind == SmartQuotes {
+			opening, closing = '“', '”'
+		}
+		return replace(replace(text, s.start, opening), s.end, closing), true
+	case DropComma:
+		commas := structure.filter(func(t token) bool { return t.char == ',' })
+		if len(commas) == 0 {
+			return text, false
+		}
+		return remove(text, commas[random.Intn(len(commas))].pos), true
+	case TrailingComma:
+		closings := structure.filter(func(t token) bool {
+			return (t.char == '}' || t.char == ']') && !strings.ContainsRune("{[,", t.previous)
+		})
+		if len(closings) == 0 {
+			return text, false
+		}
+		return insert(text, closings[random.Intn(len(closings))].pos, ','), true
+	case UnquoteKey:
+		keys := structure.strings(text).filter(func(s str) bool { return s.key && s.plain })
+		if len(keys) == 0 {
+			return text, false
+		}
+		s := keys[random.Intn(len(keys))]
+		return remove(remove(text, s.end), s.start), true
+	}
+	return text, false
+}
+
+// token is a structural character of a document: a quote, bracket, brace, comma or colon
+// outside of a string.
+type token struct {
+	char rune
+	pos  int
+	// previous is the structural character before, or 0 at the first one.
+	previous rune
+}
+
+type tokens []token
+
+// str is a quoted key or string, from its opening to its closing quote.
+type str struct {
+	start, end int
+	// key is set when the string is followed by a colon.
+	key bool
+	// plain is set when the string consists of letters, digits and underscores only.
+	plain bool
+}
+
+type strs []str
+
+// scan returns the structural characters of the text. The closing quote of an unterminated
+// string is missing.
+func scan(text []rune) tokens {
+	var result tokens
+	inString := false
+	for k := 0; k < len(text); k++ {
+		char := text[k]
+		switch {
+		case inString && char == '\\':
+			k++
+		case char == '"':
+			inString = !inString
+			result = append(result, token{char: char, pos: k})
+		case !inString && strings.ContainsRune("{}[],:", char):
+			var previous rune
+			if len(result) > 0 {
+				previous = result[len(result)-1].char
+			}
+			result = append(result, token{char: char, pos: k, previous: previous})
+		}
+	}
+	return result
+}
+
+func (t tokens) filter(keep func(token) bool) tokens {
+	var result tokens
+	for _, item := range t {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// strings returns the quoted keys and strings of the text.
+func (t tokens) strings(text []rune) strs {
+	var result strs
+	for k := 0; k+1 < len(t); k++ {
+		if t[k].char != '"' || t[k+1].char != '"' {
+			continue
+		}
+		result = append(result, str{
+			start: t[k].pos,
+			end:   t[k+1].pos,
+			key:   k+2 < len(t) && t[k+2].char == ':',
+			plain: isPlain(text[t[k].pos+1 : t[k+1].pos]),
+		})
+		k++
+	}
+	return result
+}
+
+func (s strs) filter(keep func(str) bool) strs {
+	var result strs
+	for _, item := range s {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// isPlain checks if the content of a string consists of letters, digits and underscores only.
+func isPlain(content []rune) bool {
+	for _, char := range content {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && char != '_' {
+			return false
+		}
+	}
+	return len(content) > 0
+}
+
+func remove(text []rune, pos int) []rune {
+	return append(append([]rune{}, text[:pos]...), text[pos+1:]...)
+}
+
+func replace(text []rune, pos int, char rune) []rune {
+	result := append([]rune{}, text...)
+	result[pos] = char
+	return result
+}
+
+func insert(text []rune, pos int, char rune) []rune {
+	result := append([]rune{}, text[:pos]...)
+	result = append(result, char)
+	return append(result, text[pos:]...)
+}
@@ -0,0 +1,61 @@
+package jsonrepairtest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kaptinlin/jsonrepair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const document = `{"name": "John", "age": 30, "tags": ["a", "b"], "address": {"city": "Paris", "zip": "75001"}, "empty": []}`
+
+func TestCorruptIsDeterministic(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		assert.Equal(t, Corrupt(document, seed, 3), Corrupt(document, seed, 3))
+	}
+	assert.NotEqual(t, Corrupt(document, 1, 3), Corrupt(document, 2, 3))
+	assert.Equal(t, document, Corrupt(document, 1, 0))
+	assert.Equal(t, "", Corrupt("", 1, 5))
+}
+
+func TestCorruptWith(t *testing.T) {
+	tests := []struct {
+		kind     Corruption
+		valid    string
+		expected string
+	}{
+		{DropQuote, `["a"]`, `["a]`},
+		{SwapBracket, `[]`, `[}`},
+		{SmartQuotes, `{"a": 1}`, `{“a”: 1}`},
+		{SingleQuotes, `["a"]`, `['a']`},
+		{DropComma, `[1, 2]`, `[1 2]`},
+		{TrailingComma, `{"a": [], "b": [1]}`, `{"a": [], "b": [1,]}`},
+		{UnquoteKey, `{"a b": 1, "c": "d"}`, `{"a b": 1, c: "d"}`},
+	}
+
+	for _, test := range tests {
+		corrupted := CorruptWith(test.valid, 3, 1, test.kind)
+		if test.kind == SwapBracket || test.kind == TrailingComma {
+			assert.Len(t, []rune(corrupted), len([]rune(test.expected)))
+			assert.NotEqual(t, test.valid, corrupted)
+			continue
+		}
+		assert.Equal(t, test.expected, corrupted)
+	}
+
+	truncated := CorruptWith(document, 1, 1, Truncate)
+	assert.Less(t, len(truncated), len(document))
+	assert.Equal(t, document[:len(truncated)], truncated)
+	assert.Equal(t, `["a b"]`, CorruptWith(`["a b"]`, 1, 1, UnquoteKey, DropComma))
+}
+
+func TestCorruptedDocumentsAreRepaired(t *testing.T) {
+	for seed := int64(0); seed < 200; seed++ {
+		corrupted := CorruptWith(document, seed, 2, DropQuote, SmartQuotes, SingleQuotes, DropComma, TrailingComma, UnquoteKey, Truncate)
+		repaired, err := jsonrepair.JSONRepair(corrupted)
+		require.NoError(t, err, corrupted)
+		require.True(t, json.Valid([]byte(repaired)), "%s => %s", corrupted, repaired)
+	}
+}
@@ -0,0 +1,99 @@
+package jsonrepairtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Similarity computes how well a repaired document matches the original document before it
+// was corrupted, as a score between 0 and 1. The score is structural: objects score the mean
+// of their members over the keys of both, where a missing key scores 0, arrays score the mean
+// of their items over the length of the longer array, and other values score 1 when they are
+// equal and 0 otherwise. The order of keys does not matter. An error is returned when either
+// document is not valid JSON.
+func Similarity(original, repaired string) (float64, error) {
+	originalValue, err := decode(original)
+	if err != nil {
+		return 0, fmt.Errorf("original: %w", err)
+	}
+	repairedValue, err := decode(repaired)
+	if err != nil {
+		return 0, fmt.Errorf("repaired: %w", err)
+	}
+	return similarity(originalValue, repairedValue), nil
+}
+
+// decode decodes a document, keeping numbers as written.
+func decode(text string) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the document at offset %d", decoder.InputOffset())
+	}
+	return value, nil
+}
+
+// similarity computes the similarity score of two decoded values.
+func similarity(a, b any) float64 {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			return 0
+		}
+		keys := len(a)
+		score := 0.0
+		for key, value := range a {
+			if other, ok := b[key]; ok {
+				score += similarity(value, other)
+			}
+		}
+		for key := range b {
+			if _, ok := a[key]; !ok {
+				keys++
+			}
+		}
+		if keys == 0 {
+			return 1
+		}
+		return score / float64(keys)
+	case []any:
+		b, ok := b.([]any)
+		if !ok {
+			return 0
+		}
+		length := max(len(a), len(b))
+		if length == 0 {
+			return 1
+		}
+		score := 0.0
+		for k := 0; k < min(len(a), len(b)); k++ {
+			score += similarity(a[k], b[k])
+		}
+		return score / float64(length)
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return 0
+		}
+		if a == b {
+			return 1
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		if errA == nil && errB == nil && x == y {
+			return 1
+		}
+		return 0
+	default:
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+}
@@ -0,0 +1,58 @@
+package jsonrepairtest
+
+import (
+	"testing"
+
+	"github.com/kaptinlin/jsonrepair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		repaired string
+		expected float64
+	}{
+		{"equal", document, document, 1},
+		{"key order", `{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, 1},
+		{"number notation", `[1.0, 100]`, `[1, 1e2]`, 1},
+		{"missing key", `{"a": 1, "b": 2}`, `{"a": 1}`, 0.5},
+		{"extra key", `{"a": 1}`, `{"a": 1, "b": 2, "c": 3}`, 1.0 / 3},
+		{"changed value", `{"a": "x", "b": true}`, `{"a": "y", "b": true}`, 0.5},
+		{"nested", `{"a": {"b": 1, "c": 2}, "d": 3}`, `{"a": {"b": 1}, "d": 3}`, 0.75},
+		{"truncated array", `[1, 2, 3, 4]`, `[1, 2]`, 0.5},
+		{"type mismatch", `{"a": [1]}`, `{"a": {"0": 1}}`, 0},
+		{"string for number", `[1]`, `["1"]`, 0},
+		{"empty", `{}`, `{}`, 1},
+		{"null", `null`, `null`, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			score, err := Similarity(test.original, test.repaired)
+			require.NoError(t, err)
+			assert.InDelta(t, test.expected, score, 1e-9)
+		})
+	}
+
+	_, err := Similarity(`{"a": 1}`, `{"a": 1`)
+	assert.ErrorContains(t, err, "repaired")
+	_, err = Similarity(`[1] [2]`, `[1]`)
+	assert.ErrorContains(t, err, "original")
+}
+
+func TestRepairQuality(t *testing.T) {
+	total := 0.0
+	const runs = 200
+	for seed := int64(0); seed < runs; seed++ {
+		corrupted := CorruptWith(document, seed, 1, DropQuote, SmartQuotes, SingleQuotes, DropComma, TrailingComma, UnquoteKey)
+		repaired, err := jsonrepair.JSONRepair(corrupted)
+		require.NoError(t, err)
+		score, err := Similarity(document, repaired)
+		require.NoError(t, err)
+		total += score
+	}
+	assert.Greater(t, total/runs, 0.9)
+}
@@ -0,0 +1,81 @@
+package jsonrepair
+
+import "strings"
+
+// Issue is a problem found in the text by Lint.
+type Issue struct {
+	// Kind is the kind of problem, like "missing comma" or "trailing comma".
+	Kind string
+
+	// Fix is the suggested fix, like "add missing comma". It is the repair which JSONRepair
+	// makes, see Repair.Message.
+	Fix string
+
+	// Position is the offset of the problem in the text in runes, like Repair.Position.
+	Position int
+
+	// ByteOffset is the offset of the problem in the text in bytes.
+	ByteOffset int
+
+	// Line and Column are the line and the column in runes, both starting at 1.
+	Line, Column int
+}
+
+// Lint returns every problem in the text which JSONRepair would repair with the given options,
+// in the order they are found, without returning the repaired text. It is meant for showing
+// diagnostics, like in an editor, before deciding to repair. A text without problems returns
+// no issues. When the text can not be repaired, the issues found up to there are returned
+// with the error which stopped the repair.
+func Lint(text string, opts ...Option) ([]Issue, error) {
+	o := newOptions(opts...)
+	o.report = &Report{}
+	o.annotate = false
+	_, err := repair(text, o)
+
+	issues := make([]Issue, 0, len(o.report.Repairs))
+	for _, r := range o.report.Repairs {
+		issues = append(issues, Issue{Kind: issueKind(r.Message), Fix: issueFix(r.Message), Position: r.Position})
+	}
+	locateIssues(issues, text)
+	return issues, err
+}
+
+// repairVerbs maps the verbs which start the messages of the repairs to the imperative of the fix.
+var repairVerbs = map[string]string{
+	"added":        "add",
+	"completed":    "complete",
+	"concatenated": "concatenate",
+	"converted":    "convert",
+	"decoded":      "decode",
+	"escaped":      "escape",
+	"quoted":       "quote",
+	"removed":      "remove",
+	"repaired":     "repair",
+	"replaced":     "replace",
+	"reunited":     "reunite",
+	"truncated":    "truncate",
+	"wrapped":      "wrap",
+}
+
+// issueKind returns the kind of problem a repair fixes, which is its message without the verb:
+// "added missing comma" fixes a missing comma, and "replaced arrow with colon" an arrow instead
+// of a colon.
+func issueKind(message string) string {
+	verb, kind, ok := strings.Cut(message, " ")
+	if !ok {
+		return message
+	}
+	if verb == "replaced" {
+		kind = strings.Replace(kind, " with ", " instead of ", 1)
+	}
+	return kind
+}
+
+// issueFix returns the fix of a repair, which is its message with the verb in the imperative.
+func issueFix(message string) string {
+	verb, rest, _ := strings.Cut(message, " ")
+	if imperative, ok := repairVerbs[verb]; ok {
+		return imperative + " " + rest
+	}
+	return message
+}
@@ -0,0 +1,67 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLint tests finding the problems of a text without repairing it.
+func TestLint(t *testing.T) {
+	issues, err := Lint("{\n  'a': 1,\n  b: 2,\n}")
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{
+		{Kind: "quotes instead of double quotes", Fix: "replace quotes with double quotes", Position: 4, ByteOffset: 4, Line: 2, Column: 3},
+		{Kind: "missing quotes", Fix: "add missing quotes", Position: 14, ByteOffset: 14, Line: 3, Column: 3},
+		{Kind: "trailing comma", Fix: "remove trailing comma", Position: 20, ByteOffset: 20, Line: 4, Column: 1},
+	}, issues)
+
+	issues, err = Lint(`{"a": [1, 2]}`)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+// TestLintRepairsWithoutChangedValue tests finding the problems whose repair keeps the value,
+// like an invalid escape, special whitespace or a byte order mark.
+func TestLintRepairsWithoutChangedValue(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []Issue
+	}{
+		{`{"a":"\x"}`, []Issue{{Kind: "invalid escape", Fix: "remove invalid escape", Position: 6, ByteOffset: 6, Line: 1, Column: 7}}},
+		{"{\"a\":\u00a01}", []Issue{{Kind: "special whitespace", Fix: "replace special whitespace", Position: 5, ByteOffset: 5, Line: 1, Column: 6}}},
+		{"\ufeff[1]", []Issue{{Kind: "byte order mark", Fix: "remove byte order mark", Position: 0, ByteOffset: 0, Line: 1, Column: 1}}},
+		{"[1,\ufeff2]", []Issue{{Kind: "byte order mark", Fix: "remove byte order mark", Position: 3, ByteOffset: 3, Line: 1, Column: 4}}},
+	}
+	for _, tt := range tests {
+		issues, err := Lint(tt.text)
+		require.NoError(t, err, tt.text)
+		assert.Equal(t, tt.expected, issues, tt.text)
+	}
+}
+
+// TestLintFailure tests returning the problems found before the text could not be repaired.
+func TestLintFailure(t *testing.T) {
+	issues, err := Lint(`{'a': 1} x`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "replace quotes with double quotes", issues[0].Fix)
+}
+
+// TestIssueKindAndFix tests deriving the kind of problem and its fix from a repair message.
+func TestIssueKindAndFix(t *testing.T) {
+	tests := []struct {
+		message, kind, fix string
+	}{
+		{"added missing comma", "missing comma", "add missing comma"},
+		{"replaced arrow with colon", "arrow instead of colon", "replace arrow with colon"},
+		{"removed markdown fence", "markdown fence", "remove markdown fence"},
+		{"replaced url token", "url token", "replace url token"},
+		{"unknown", "unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.kind, issueKind(tt.message), tt.message)
+		assert.Equal(t, tt.fix, issueFix(tt.message), tt.message)
+	}
+}
@@ -0,0 +1,194 @@
+package jsonrepair
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// MergeStrategy defines how MergeRepaired combines two documents.
+type MergeStrategy int
+
+const (
+	// MergePatch merges according to RFC 7386 (JSON Merge Patch): objects are merged
+	// recursively, null values in the patch remove members, and other values replace.
+	MergePatch MergeStrategy = iota
+
+	// MergeDeep merges objects recursively like MergePatch, but null values in the
+	// patch are kept as values instead of removing members.
+	MergeDeep
+)
+
+// MergeRepaired repairs both base and patch, merges patch into base and returns the merged
+// document. The order of the members of base is kept, new members of patch are appended.
+// By default the documents are merged according to RFC 7386, use WithMergeStrategy to change that.
+func MergeRepaired(base, patch string, opts ...Option) (string, error) {
+	o := newOptions(opts...)
+
+	baseValue, err := repairAndDecode(base, opts...)
+	if err != nil {
+		return "", err
+	}
+	patchValue, err := repairAndDecode(patch, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	var output bytes.Buffer
+	if err := encodeOrdered(&output, mergeValues(baseValue, patchValue, o.mergeStrategy)); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// orderedObject is a decoded JSON object which keeps the order of its members.
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// set sets the value of a member, appending the key when it is new.
+func (obj *orderedObject) set(key string, value any) {
+	if _, exists := obj.values[key]; !exists {
+		obj.keys = append(obj.keys, key)
+	}
+	obj.values[key] = value
+}
+
+// remove removes a member.
+func (obj *orderedObject) remove(key string) {
+	if _, exists := obj.values[key]; !exists {
+		return
+	}
+	delete(obj.values, key)
+	for index, k := range obj.keys {
+		if k == key {
+			obj.keys = append(obj.keys[:index], obj.keys[index+1:]...)
+			break
+		}
+	}
+}
+
+// mergeValues merges patch into target and returns the result.
+func mergeValues(target, patch any, strategy MergeStrategy) any {
+	patchObject, ok := patch.(*orderedObject)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(*orderedObject)
+	if !ok {
+		targetObject = &orderedObject{values: map[string]any{}}
+	}
+
+	for _, key := range patchObject.keys {
+		value := patchObject.values[key]
+		if value == nil && strategy == MergePatch {
+			targetObject.remove(key)
+			continue
+		}
+		targetObject.set(key, mergeValues(targetObject.values[key], value, strategy))
+	}
+	return targetObject
+}
+
+// repairAndDecode repairs the text and decodes it, keeping the order of object members.
+func repairAndDecode(text string, opts ...Option) (any, error) {
+	repaired, err := JSONRepair(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(repaired))
+	decoder.UseNumber()
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, ErrUnexpectedCharacter
+	}
+	return value, nil
+}
+
+// decodeOrdered decodes the next JSON value from the decoder, keeping the order of object members.
+func decodeOrdered(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := &orderedObject{values: map[string]any{}}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string)
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, value)
+		}
+		_, err := decoder.Token() // closing brace
+		return obj, err
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := decoder.Token() // closing bracket
+		return array, err
+	default:
+		return token, nil
+	}
+}
+
+// encodeOrdered writes a value decoded with decodeOrdered as compact JSON.
+func encodeOrdered(output *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case *orderedObject:
+		output.WriteByte('{')
+		for index, key := range v.keys {
+			if index > 0 {
+				output.WriteByte(',')
+			}
+			if err := encodeOrdered(output, key); err != nil {
+				return err
+			}
+			output.WriteByte(':')
+			if err := encodeOrdered(output, v.values[key]); err != nil {
+				return err
+			}
+		}
+		output.WriteByte('}')
+	case []any:
+		output.WriteByte('[')
+		for index, item := range v {
+			if index > 0 {
+				output.WriteByte(',')
+			}
+			if err := encodeOrdered(output, item); err != nil {
+				return err
+			}
+		}
+		output.WriteByte(']')
+	default:
+		encoder := json.NewEncoder(output)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		output.Truncate(output.Len() - 1) // remove the newline added by Encode
+	}
+	return nil
+}
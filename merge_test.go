@@ -0,0 +1,72 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRepaired(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		patch    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "merge patch",
+			base:     `{name: 'app', debug: true, limits: {cpu: 1, memory: 512}}`,
+			patch:    `{debug: null, limits: {memory: 1024,}, tags: ['a']`,
+			expected: `{"name":"app","limits":{"cpu":1,"memory":1024},"tags":["a"]}`,
+		},
+		{
+			name:     "deep merge keeps null values",
+			base:     `{"a": 1, "b": {"c": 2}}`,
+			patch:    `{"a": null, "b": {"d": 3}}`,
+			opts:     []Option{WithMergeStrategy(MergeDeep)},
+			expected: `{"a":null,"b":{"c":2,"d":3}}`,
+		},
+		{
+			name:     "arrays are replaced",
+			base:     `{"a": [1, 2, 3]}`,
+			patch:    `{"a": [4]}`,
+			expected: `{"a":[4]}`,
+		},
+		{
+			name:     "patch which is not an object replaces the base",
+			base:     `{"a": 1}`,
+			patch:    `[1, 2`,
+			expected: `[1,2]`,
+		},
+		{
+			name:     "base which is not an object",
+			base:     `"text"`,
+			patch:    `{"a": {"b": null, "c": 1}}`,
+			expected: `{"a":{"c":1}}`,
+		},
+		{
+			name:     "numbers and html characters are kept as is",
+			base:     `{"big": 12345678901234567890, "html": "<b>&</b>"}`,
+			patch:    `{}`,
+			expected: `{"big":12345678901234567890,"html":"<b>&</b>"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := MergeRepaired(test.base, test.patch, test.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestMergeRepairedFailure(t *testing.T) {
+	_, err := MergeRepaired(``, `{}`)
+	require.ErrorIs(t, err, ErrUnexpectedEnd)
+
+	_, err = MergeRepaired(`{}`, `{"a":2}foo`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
@@ -0,0 +1,152 @@
+package jsonrepair
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinFunctionNames are the MongoDB shell types and JavaScript constructors whose calls are
+// stripped regardless of WithFunctionCallPolicy.
+var builtinFunctionNames = []string{
+	"ObjectId", "ISODate", "NumberLong", "NumberInt", "NumberDecimal", "Timestamp", "BinData",
+	"DBRef", "UUID", "Date", "Number", "Boolean", "String",
+}
+
+// isAllowedFunction checks if a call of the named function is stripped.
+func isAllowedFunction(name string, opts *options) bool {
+	return opts.functionCallPolicy == FunctionCallStrip ||
+		slices.Contains(builtinFunctionNames, name) || slices.Contains(opts.functionNames, name)
+}
+
+// skipParenthesized skips a parenthesized text like (1, ")") including nested parentheses and
+// the strings inside, up to and including the matching closing parenthesis.
+func skipParenthesized(c *cursor) {
+	depth := 0
+	for !c.done() {
+		char := c.next()
+		switch {
+		case isQuote(char):
+			// a string ends at a quote of the same kind, like a right after a left smart quote
+			for !c.done() && !(isQuote(c.peek(0)) && isDoubleQuoteLike(c.peek(0)) == isDoubleQuoteLike(char)) {
+				if c.next() == codeBackslash {
+					c.next()
+				}
+			}
+			c.next()
+		case char == codeOpenParenthesis:
+			depth++
+		case char == codeCloseParenthesis:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// parseCallArguments parses the arguments of a function call like Timestamp(162, 1) after the
+// opening parenthesis, up to and including the closing parenthesis, and returns them repaired.
+// The whitespace around the arguments is kept, so a single argument keeps its formatting.
+// When the arguments are not a list ending at the closing parenthesis or the end of the text,
+// only the first value is the argument, like the call of a mistyped name in NumberLo(ng("2"), 3.
+func parseCallArguments(c *cursor, opts *options) []string {
+	if !opts.enter(c.pos - 1) {
+		return nil
+	}
+	defer opts.leave()
+	arrows := opts.arrows
+	opts.arrows = false
+	defer func() { opts.arrows = arrows }()
+
+	var args []string
+	var output strings.Builder
+	if tryParse(c, &output, opts, func() bool {
+		last := -1
+		for !c.done() && progressed(&last, c.pos, opts) {
+			var arg strings.Builder
+			if !parseValue(c, &arg, opts) {
+				break
+			}
+			parseWhitespaceAndSkipComments(c, &arg, opts)
+			args = append(args, arg.String())
+			if !skipCharacter(c, codeComma) {
+				break
+			}
+		}
+		return skipCharacter(c, codeCloseParenthesis) || c.done()
+	}) {
+		return args
+	}
+
+	args = nil
+	if parseValue(c, &output, opts) {
+		args = append(args, output.String())
+	}
+	skipCharacter(c, codeCloseParenthesis)
+	return args
+}
+
+// writeCall writes the value of a function call like ObjectId("abc") or JSONP callback({...})
+// with its repaired arguments. Without arguments, like Date(), the value is null, and a single
+// argument is the value itself. The MongoDB shell types with more arguments are written as
+// MongoDB Extended JSON: Timestamp(t, i), BinData(subtype, base64) and DBRef(collection, id, db).
+// A date with its components, like new Date(2024, 0, 31), becomes an ISO 8601 string in UTC.
+// The arguments of other functions are kept as an array.
+func writeCall(output *strings.Builder, name string, args []string) {
+	if len(args) == 1 {
+		output.WriteString(args[0])
+		return
+	}
+	for k, arg := range args {
+		args[k] = strings.TrimSpace(arg)
+	}
+	switch {
+	case len(args) == 0:
+		output.WriteString("null")
+	case name == "Timestamp" && len(args) == 2:
+		fmt.Fprintf(output, `{"$timestamp": {"t": %s, "i": %s}}`, args[0], args[1])
+	case name == "BinData" && len(args) == 2:
+		subType := args[0]
+		if n, err := strconv.Atoi(subType); err == nil {
+			subType = fmt.Sprintf(`"%02x"`, n)
+		}
+		fmt.Fprintf(output, `{"$binary": {"base64": %s, "subType": %s}}`, args[1], subType)
+	case name == "DBRef" && (len(args) == 2 || len(args) == 3):
+		fmt.Fprintf(output, `{"$ref": %s, "$id": %s`, args[0], args[1])
+		if len(args) == 3 {
+			fmt.Fprintf(output, `, "$db": %s`, args[2])
+		}
+		output.WriteString("}")
+	case (name == "Date" || name == "ISODate") && len(args) > 1:
+		if date, ok := dateFromComponents(args); ok {
+			writeQuoted(output, date)
+			return
+		}
+		fallthrough
+	default:
+		output.WriteString("[" + strings.Join(args, ", ") + "]")
+	}
+}
+
+// dateFromComponents returns the ISO 8601 string of a JavaScript date created from its
+// components, like new Date(2024, 0, 31, 12, 30): the year, the month starting at 0, and
+// optionally the day, hours, minutes, seconds and milliseconds.
+func dateFromComponents(args []string) (string, bool) {
+	components := []int{0, 0, 1, 0, 0, 0, 0}
+	if len(args) > len(components) {
+		return "", false
+	}
+	for k, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return "", false
+		}
+		components[k] = n
+	}
+	date := time.Date(components[0], time.Month(components[1]+1), components[2],
+		components[3], components[4], components[5], components[6]*int(time.Millisecond), time.UTC)
+	return date.Format("2006-01-02T15:04:05.000Z"), true
+}
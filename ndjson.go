@@ -0,0 +1,31 @@
+package jsonrepair
+
+import "strings"
+
+// LineResult is the result of repairing a single line with RepairNDJSON.
+type LineResult struct {
+	// Line is the number of the line in the input, starting at 1.
+	Line int
+
+	// Output is the repaired line, empty when the line could not be repaired.
+	Output string
+
+	// Err is the error which stopped the repair of the line, or nil.
+	Err error
+}
+
+// RepairNDJSON repairs newline delimited JSON line by line, and returns a result for every
+// line which is not blank. A line which cannot be repaired does not affect the other lines,
+// its result holds the error instead. A report given with WithReport holds the details
+// about the last line.
+func RepairNDJSON(text string, opts ...Option) []LineResult {
+	var results []LineResult
+	for k, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		output, err := JSONRepair(line, opts...)
+		results = append(results, LineResult{Line: k + 1, Output: output, Err: err})
+	}
+	return results
+}
@@ -0,0 +1,33 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairNDJSON(t *testing.T) {
+	text := "{name: 'John'}\n[1, 2\n\n{\"a\": 1}}}x\r\n\"text"
+	results := RepairNDJSON(text)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, LineResult{Line: 1, Output: `{"name": "John"}`}, results[0])
+	assert.Equal(t, LineResult{Line: 2, Output: `[1, 2]`}, results[1])
+	assert.Equal(t, 4, results[2].Line)
+	assert.Empty(t, results[2].Output)
+	require.ErrorIs(t, results[2].Err, ErrUnexpectedCharacter)
+	assert.EqualError(t, results[2].Err, "unexpected character: 'x' at position 10")
+	assert.Equal(t, LineResult{Line: 5, Output: `"text"`}, results[3])
+}
+
+func TestRepairNDJSONWithOptions(t *testing.T) {
+	results := RepairNDJSON("{a: 1} # b\n[2] # c", WithHashComments())
+	assert.Equal(t, []LineResult{{Line: 1, Output: `{"a": 1} `}, {Line: 2, Output: `[2] `}}, results)
+}
+
+func TestRepairNDJSONWithLogPrefix(t *testing.T) {
+	text := "2024-01-01 INFO {a: 1}\n[DEBUG] worker=2 [1, 2]"
+	results := RepairNDJSON(text, WithSkipLogPrefix())
+	assert.Equal(t, []LineResult{{Line: 1, Output: `{"a": 1}`}, {Line: 2, Output: `[1, 2]`}}, results)
+}
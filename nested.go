@@ -0,0 +1,46 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// repairNestedString repairs the JSON embedded in the string value written to the output at
+// valueStart, when the key of the member matches the pattern of WithNestedStringRepair. The
+// repaired JSON replaces the string, escaped as a string again, or inlined as a value with
+// WithInlineNestedStrings. A string which does not contain an object or array, or whose
+// content can not be repaired, is left as it is. The position is where the value starts in
+// the text.
+func repairNestedString(output *strings.Builder, key string, valueStart, position int, opts *options) {
+	var name string
+	if opts.nestedKeys == nil || json.Unmarshal([]byte(key), &name) != nil || !opts.nestedKeys.MatchString(name) {
+		return
+	}
+
+	outputStr := output.String()
+	value := strings.TrimRightFunc(outputStr[valueStart:], isWhitespace)
+	var content string
+	if json.Unmarshal([]byte(value), &content) != nil {
+		return
+	}
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return
+	}
+
+	// the content is repaired with the same options, so nested strings in it are repaired too
+	repaired, ok := repairEmbedded(trimmed, opts)
+	if !ok || repaired == trimmed && !opts.inlineNested {
+		return
+	}
+
+	output.Reset()
+	output.WriteString(outputStr[:valueStart])
+	if opts.inlineNested {
+		output.WriteString(repaired)
+	} else {
+		writeQuoted(output, repaired)
+	}
+	output.WriteString(outputStr[valueStart+len(value):])
+	logRepair(position, output, "repaired nested json string", opts)
+}
@@ -0,0 +1,36 @@
+package jsonrepair
+
+import "strings"
+
+// NewlineStyle defines the line breaks of the repaired output.
+type NewlineStyle int
+
+const (
+	// NewlinePreserve keeps the line breaks of the input as they are.
+	NewlinePreserve NewlineStyle = iota
+
+	// NewlineLF writes every line break as \n, including \r\n and a lone \r.
+	NewlineLF
+
+	// NewlineCRLF writes every line break as \r\n, including \n and a lone \r.
+	NewlineCRLF
+
+	// NewlineStripCR removes every \r, so \r\n becomes \n.
+	NewlineStripCR
+)
+
+// newlineReplacers rewrite the line breaks of an output for each style but NewlinePreserve.
+// Line breaks in strings are escaped, so all raw line breaks in an output are whitespace.
+var newlineReplacers = map[NewlineStyle]*strings.Replacer{
+	NewlineLF:      strings.NewReplacer("\r\n", "\n", "\r", "\n"),
+	NewlineCRLF:    strings.NewReplacer("\r\n", "\r\n", "\r", "\r\n", "\n", "\r\n"),
+	NewlineStripCR: strings.NewReplacer("\r", ""),
+}
+
+// applyNewlineStyle rewrites the line breaks of the output in the given style.
+func applyNewlineStyle(output string, style NewlineStyle) string {
+	if replacer, ok := newlineReplacers[style]; ok {
+		return replacer.Replace(output)
+	}
+	return output
+}
@@ -0,0 +1,29 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithNewlineStyle(t *testing.T) {
+	input := "{\r\n  \"a\": \"x\r\ny\",\r\n  \"b\": [1,\r2]\n}"
+	tests := []struct {
+		name     string
+		style    NewlineStyle
+		expected string
+	}{
+		{"preserve", NewlinePreserve, "{\r\n  \"a\": \"x\\r\\ny\",\r\n  \"b\": [1,\r2]\n}"},
+		{"lf", NewlineLF, "{\n  \"a\": \"x\\r\\ny\",\n  \"b\": [1,\n2]\n}"},
+		{"crlf", NewlineCRLF, "{\r\n  \"a\": \"x\\r\\ny\",\r\n  \"b\": [1,\r\n2]\r\n}"},
+		{"strip cr", NewlineStripCR, "{\n  \"a\": \"x\\r\\ny\",\n  \"b\": [1,2]\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := JSONRepair(input, WithNewlineStyle(tt.style))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
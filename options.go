@@ -0,0 +1,696 @@
+package jsonrepair
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/kaptinlin/jsonrepair/rule"
+)
+
+// Option configures how a JSON document is repaired.
+type Option func(*options)
+
+// options holds the configuration of a single repair.
+type options struct {
+	skipPreamble        bool
+	skipLogPrefix       bool
+	detectCharset       bool
+	urlDecoding         bool
+	base64Decoding      bool
+	goSyntax            bool
+	equalsSeparator     bool
+	replacementQuotes   bool
+	annotate            bool
+	dropEmptySlots      bool
+	dropBareKeys        bool
+	hashComments        bool
+	colonsInKeys        bool
+	htmlEntities        bool
+	inlineNested        bool
+	yamlBlocks          bool
+	escapeNonASCII      bool
+	escapeHTML          bool
+	markdown            bool
+	tokens              []Token
+	tagWrappers         []string
+	nestedKeys          *regexp.Regexp
+	urlChars            string
+	encoding            string
+	delimiters          string
+	whitespace          string
+	mergeStrategy       MergeStrategy
+	invalidNumberPolicy InvalidNumberPolicy
+	preserveBigNumbers  bool
+	functionCallPolicy  FunctionCallPolicy
+	ellipsisPolicy      EllipsisPolicy
+	nulPolicy           NULPolicy
+	stringNewlinePolicy StringNewlinePolicy
+	newlineStyle        NewlineStyle
+	whitespacePolicy    WhitespacePolicy
+	surrogatePolicy     SurrogatePolicy
+	invalidUTF8Policy   InvalidUTF8Policy
+	ellipsisPlaceholder string
+	functionNames       []string
+	maxStringScan       int
+	maxDepth            int
+	maxInputSize        int
+	maxOutputSize       int
+	allErrors           bool
+	rules               map[rule.Rule]bool
+	aggressiveness      Aggressiveness
+	customRules         []Rule
+	stringTransformer   func(key, value string) string
+	keyTransformer      func(key string) string
+	schemaErr           error
+	report              *Report
+	warnings            *[]Issue
+
+	// offset is the position of the parsed text in the input, added to reported positions.
+	offset int
+	// err is the error which stopped the repair, set with fail.
+	err error
+	// depth is the number of arrays, objects and function calls the parser is in.
+	depth int
+	// ctx stops the repair when it is done, set by JSONRepairContext.
+	ctx context.Context
+	// key is the key of the member whose value is parsed, see WithStringTransformer.
+	key string
+	// schema is the schema of the value which is parsed, see WithSchema.
+	schema *schemaNode
+	// goLiteral is set while the parser is in a Go literal, where whitespace separates values.
+	goLiteral bool
+	// arrows is set while the parser is in an array or object, where "=>" may separate a key
+	// from its value, like in array('a' => 1).
+	arrows bool
+	// steps counts the calls of canceled, which checks ctx every contextCheckInterval steps.
+	steps int
+}
+
+// fail stops the repair with the given error, keeping the first error when called more than once.
+func (o *options) fail(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// enter increments the nesting depth when the parser enters an array, object or function call
+// opened at the given position. It fails the repair with ErrMaxDepth when the depth exceeds the limit.
+func (o *options) enter(position int) bool {
+	if o.maxDepth > 0 && o.depth >= o.maxDepth {
+		o.fail(newError(ErrMaxDepth, "", o.offset+position))
+		return false
+	}
+	o.depth++
+	return true
+}
+
+// leave decrements the nesting depth when the parser leaves an array, object or function call.
+func (o *options) leave() {
+	o.depth--
+}
+
+// contextCheckInterval is the number of parser steps between two checks of the context.
+const contextCheckInterval = 1024
+
+// canceled fails the repair with the error of the context when the context is done. The context
+// is checked once every contextCheckInterval calls, so calling it in the loops of the parser
+// is cheap.
+func (o *options) canceled() bool {
+	if o.ctx == nil {
+		return false
+	}
+	o.steps++
+	if o.steps%contextCheckInterval == 1 {
+		if err := o.ctx.Err(); err != nil {
+			o.fail(err)
+		}
+	}
+	return o.err != nil
+}
+
+// checkInputSize fails with ErrInputTooLarge when the input of the given size in bytes exceeds
+// the limit of WithMaxInputSize.
+func (o *options) checkInputSize(size int) {
+	if o.maxInputSize > 0 && size > o.maxInputSize {
+		o.fail(fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", ErrInputTooLarge, size, o.maxInputSize))
+	}
+}
+
+// checkOutputSize returns ErrOutputTooLarge when the output of the given size in bytes exceeds
+// the limit of WithMaxOutputSize.
+func (o *options) checkOutputSize(size int) error {
+	if o.maxOutputSize > 0 && size > o.maxOutputSize {
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", ErrOutputTooLarge, size, o.maxOutputSize)
+	}
+	return nil
+}
+
+// limitOutput returns the output, or ErrOutputTooLarge when it exceeds the limit of
+// WithMaxOutputSize.
+func (o *options) limitOutput(output string) (string, error) {
+	if err := o.checkOutputSize(len(output)); err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// result returns the repaired output of the original text, or ErrOutputTooLarge when it exceeds
+// the limit of WithMaxOutputSize, and reports whether the output differs from the text.
+func (o *options) result(original, output string) (string, error) {
+	output, err := o.limitOutput(output)
+	if err == nil && o.report != nil {
+		o.report.Changed = output != original
+	}
+	return output, err
+}
+
+// defaultMaxDepth is the default nesting depth limit, like the one of encoding/json.
+const defaultMaxDepth = 10000
+
+// newOptions applies the given options on top of the defaults.
+func newOptions(opts ...Option) *options {
+	o := &options{tagWrappers: defaultTagWrappers, ellipsisPlaceholder: "null", maxDepth: defaultMaxDepth}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := o.validate(); err != nil {
+		o.fail(err)
+	}
+	o.tokens = append(o.tokens, builtinTokens(o)...)
+	return o
+}
+
+// WithSkipPreamble skips a "---" delimited front-matter block before repairing, next to
+// a leading shebang line (#!/usr/bin/env ...) which is always skipped. The skipped text
+// is available as Report.Preamble.
+func WithSkipPreamble() Option {
+	return func(o *options) {
+		o.skipPreamble = true
+	}
+}
+
+// WithSkipLogPrefix skips a log line prefix like a timestamp, a log level or level=info msg=
+// before the first opening brace or bracket, so the document must be an object or array.
+// A bracketed word followed by more text, like [INFO], is part of the prefix. The skipped
+// text is added to Report.Preamble. With RepairNDJSON the prefix of every line is skipped.
+func WithSkipLogPrefix() Option {
+	return func(o *options) {
+		o.skipLogPrefix = true
+	}
+}
+
+// WithReport fills the given report with details about the repair.
+func WithReport(report *Report) Option {
+	return func(o *options) {
+		o.report = report
+	}
+}
+
+// WithAnnotations adds a comment to the output at every repair site, like
+// {"name": "John" /* jsonrepair: added missing quotes */}, for human review.
+// The output is JSONC then, and needs a parser which accepts comments.
+func WithAnnotations() Option {
+	return func(o *options) {
+		o.annotate = true
+	}
+}
+
+// WithCharsetDetection transcodes input which is not valid UTF-8 from Windows-1252
+// (and thus Latin-1) to UTF-8 before repairing, so that for example smart quotes
+// copied from Word are recognized. The detected charset is available as Report.Charset.
+func WithCharsetDetection() Option {
+	return func(o *options) {
+		o.detectCharset = true
+	}
+}
+
+// WithEncoding sets the encoding of the input: "utf-8", "utf-16le", "utf-16be", "utf-32le" or
+// "utf-32be". UTF-16 and UTF-32 input is transcoded to UTF-8 before repairing. By default the
+// encoding is detected by the byte order mark, or by the zero bytes of the first character,
+// so that for example UTF-16 files written by PowerShell are transcoded automatically. The
+// transcoded encoding is available as Report.Charset.
+func WithEncoding(encoding string) Option {
+	return func(o *options) {
+		o.encoding = encoding
+	}
+}
+
+// WithURLDecoding decodes percent-encoded (URL-encoded) input before repairing, like
+// %7B%22a%22%3A1%7D from a query string, or a partially encoded body like {%22a%22:1}.
+// Input is only decoded when it encodes quotes, colons, commas, brackets or braces, and is
+// not valid JSON already. A plus is decoded as a space only when the input is encoded as
+// a whole. Report.URLDecoded tells whether the input was decoded.
+func WithURLDecoding() Option {
+	return func(o *options) {
+		o.urlDecoding = true
+	}
+}
+
+// WithBase64Decoding decodes input which is base64 encoded as a whole, like eyJhIjoxfQ==,
+// before repairing, when the decoded text starts with an opening brace or bracket. Both the
+// standard and the URL-safe alphabet are accepted, with or without padding and line breaks.
+// Report.Base64Decoded tells whether the input was decoded.
+func WithBase64Decoding() Option {
+	return func(o *options) {
+		o.base64Decoding = true
+	}
+}
+
+// WithGoSyntax repairs values printed by the Go fmt package, like map[string]int{"a":1}
+// (%#v), {Name:John Age:30} (%+v) and map[a:1 b:2] (%v). In this mode unquoted strings
+// end at whitespace, and nil and <nil> are replaced with null.
+func WithGoSyntax() Option {
+	return func(o *options) {
+		o.goSyntax = true
+	}
+}
+
+// WithEqualsSeparator accepts "=" and ":=" as separators between object keys and values, like
+// in {a = 1, b := 2} and in the output of Java toString methods: {name=John, age=30, tags=[a, b]}.
+// Class names in front of objects, like Person{name=John} or Person(name=John), are removed.
+func WithEqualsSeparator() Option {
+	return func(o *options) {
+		o.equalsSeparator = true
+	}
+}
+
+// WithReplacementCharQuotes treats the Unicode replacement character U+FFFD as a quote
+// when it is found where a string starts. This recovers strings whose smart quotes were
+// lost in a broken encoding, like \uFFFDJohn\uFFFD.
+func WithReplacementCharQuotes() Option {
+	return func(o *options) {
+		o.replacementQuotes = true
+	}
+}
+
+// WithTokens adds well-known tokens which are kept whole when they appear unquoted in value
+// position. They are recognized before the built-in tokens: endpoints (10.0.0.1:9200), URLs,
+// dates (2024-01-01T10:00:00Z), UUIDs, times (12:30:45), ratios (16:9), mailto links,
+// emails (bob@example.com) and mentions (@handle).
+func WithTokens(tokens ...Token) Option {
+	return func(o *options) {
+		o.tokens = append(o.tokens, tokens...)
+	}
+}
+
+// WithColonTokens adds patterns of values which contain colons but must be kept whole as a
+// string, next to the built-in time (12:30:45) and ratio (16:9) tokens. The patterns should
+// be anchored with ^. It is a shorthand for WithTokens.
+func WithColonTokens(patterns ...*regexp.Regexp) Option {
+	return WithTokens(patternTokens("colon", patterns)...)
+}
+
+// WithTokenShapes adds patterns of well-known token shapes which are kept whole as a string
+// when they appear unquoted in value position, next to the built-in emails (bob@example.com),
+// mentions (@handle) and mailto links. The patterns should be anchored with ^. It is a
+// shorthand for WithTokens.
+func WithTokenShapes(patterns ...*regexp.Regexp) Option {
+	return WithTokens(patternTokens("shape", patterns)...)
+}
+
+// patternTokens creates string tokens with the given name for the patterns.
+func patternTokens(name string, patterns []*regexp.Regexp) []Token {
+	tokens := make([]Token, len(patterns))
+	for k, pattern := range patterns {
+		tokens[k] = Token{Name: name, Pattern: pattern}
+	}
+	return tokens
+}
+
+// WithURLChars adds characters which are allowed in unquoted URLs, next to the characters of
+// RFC 3986. For example WithURLChars("[]|") keeps http://example.com/a[0]|b whole. Like a
+// closing parenthesis, a closing bracket is only part of the URL when it was opened in the URL.
+func WithURLChars(chars string) Option {
+	return func(o *options) {
+		o.urlChars += chars
+	}
+}
+
+// InvalidNumberPolicy defines how malformed numeric tokens like 0.0.1 or 2e3.4 are repaired.
+// Tokens consisting of digits, dots, exponents and signs only are malformed numeric tokens.
+// Dates like 2024-01-01 are recognized as tokens, see WithTokens, and kept whole.
+type InvalidNumberPolicy int
+
+const (
+	// InvalidNumberQuote turns a malformed numeric token into a string: 0.0.1 becomes "0.0.1".
+	InvalidNumberQuote InvalidNumberPolicy = iota
+
+	// InvalidNumberTruncate keeps the valid number at the start of the token: 0.0.1 becomes 0.0.
+	InvalidNumberTruncate
+
+	// InvalidNumberError fails the repair with ErrInvalidNumber.
+	InvalidNumberError
+)
+
+// WithInvalidNumberPolicy sets how malformed numeric tokens are repaired. The default is InvalidNumberQuote.
+func WithInvalidNumberPolicy(policy InvalidNumberPolicy) Option {
+	return func(o *options) {
+		o.invalidNumberPolicy = policy
+	}
+}
+
+// WithPreserveBigNumbers quotes integers beyond the safe range of a float64, ±2^53, like
+// 12345678901234567890 which becomes "12345678901234567890", so they do not silently lose their
+// precision when the repaired JSON is decoded into a float64, as JavaScript and encoding/json
+// into an any do. An integer whose schema expects a number, see WithSchema, stays a number.
+func WithPreserveBigNumbers() Option {
+	return func(o *options) {
+		o.preserveBigNumbers = true
+	}
+}
+
+// EllipsisPolicy defines how an ellipsis in an array or object, like [1, 2, ...] where the
+// data was truncated, is repaired.
+type EllipsisPolicy int
+
+const (
+	// EllipsisRemove removes the ellipsis: [1, 2, ...] becomes [1, 2].
+	EllipsisRemove EllipsisPolicy = iota
+
+	// EllipsisPlaceholder replaces the ellipsis with a placeholder, see WithEllipsisPlaceholder:
+	// [1, 2, ...] becomes [1, 2, null], and {"a": 1, ...} becomes {"a": 1, "...": null}.
+	EllipsisPlaceholder
+
+	// EllipsisError fails the repair with ErrTruncated.
+	EllipsisError
+)
+
+// WithEllipsisPolicy sets how an ellipsis in an array or object is repaired. The default is EllipsisRemove.
+func WithEllipsisPolicy(policy EllipsisPolicy) Option {
+	return func(o *options) {
+		o.ellipsisPolicy = policy
+	}
+}
+
+// WithEllipsisPlaceholder sets the JSON value which replaces an ellipsis with EllipsisPlaceholder,
+// like `"…"`. The default is null. A value which is not valid JSON fails the repair with ErrInvalidOption.
+func WithEllipsisPlaceholder(value string) Option {
+	return func(o *options) {
+		o.ellipsisPlaceholder = value
+	}
+}
+
+// NULPolicy defines how NUL bytes (U+0000) in the input, like in log lines polluted with binary
+// data, are repaired.
+type NULPolicy int
+
+const (
+	// NULKeep keeps NUL bytes like other characters, which may fail the repair or leave them
+	// unescaped in the output.
+	NULKeep NULPolicy = iota
+
+	// NULStrip removes all NUL bytes before repairing. Positions are in the text without them.
+	NULStrip
+
+	// NULEscape escapes NUL bytes inside strings as \u0000, and removes them elsewhere.
+	NULEscape
+)
+
+// WithNULPolicy sets how NUL bytes in the input are repaired. The default is NULKeep.
+func WithNULPolicy(policy NULPolicy) Option {
+	return func(o *options) {
+		o.nulPolicy = policy
+	}
+}
+
+// StringNewlinePolicy defines how a raw newline inside a quoted string is repaired.
+type StringNewlinePolicy int
+
+const (
+	// StringNewlineAuto escapes a newline as \n, unless the string misses its end quote and
+	// ends at the next delimiter, which may be the newline.
+	StringNewlineAuto StringNewlinePolicy = iota
+
+	// StringNewlineEscape always escapes a newline as \n and continues the string, for
+	// multi-line text pasted into JSON: {"a": "line 1<newline>line 2"} keeps both lines.
+	StringNewlineEscape
+
+	// StringNewlineEnd ends the string at a newline, adding the missing end quote, for line
+	// based data: ["a<newline>"b"] becomes ["a",<newline>"b"].
+	StringNewlineEnd
+)
+
+// WithStringNewlinePolicy sets how a raw newline inside a quoted string is repaired. The default
+// is StringNewlineAuto.
+func WithStringNewlinePolicy(policy StringNewlinePolicy) Option {
+	return func(o *options) {
+		o.stringNewlinePolicy = policy
+	}
+}
+
+// FunctionCallPolicy defines how function calls like callback({...}) and ObjectId("abc") are
+// repaired, when the function is not allowed with WithFunctionNames.
+type FunctionCallPolicy int
+
+const (
+	// FunctionCallStrip replaces a function call with its arguments: JSONP like callback({})
+	// becomes {}, and foo (1) becomes 1.
+	FunctionCallStrip FunctionCallPolicy = iota
+
+	// FunctionCallKeep keeps a function call as a string: foo (1) becomes "foo (1)".
+	FunctionCallKeep
+
+	// FunctionCallError fails the repair with ErrUnknownFunction.
+	FunctionCallError
+)
+
+// WithFunctionCallPolicy sets how function calls are repaired, like JSONP callbacks. The default
+// is FunctionCallStrip. With another policy only the MongoDB shell types like ObjectId, ISODate
+// and NumberLong, the JavaScript constructors Date, Number, Boolean and String, and the names
+// added with WithFunctionNames are still stripped.
+func WithFunctionCallPolicy(policy FunctionCallPolicy) Option {
+	return func(o *options) {
+		o.functionCallPolicy = policy
+	}
+}
+
+// WithFunctionNames adds names of functions, like JSONP callbacks, whose calls are stripped
+// regardless of WithFunctionCallPolicy.
+func WithFunctionNames(names ...string) Option {
+	return func(o *options) {
+		o.functionNames = append(o.functionNames, names...)
+	}
+}
+
+// WithDropEmptyArraySlots drops the empty slots of a sparse array instead of
+// replacing them with null, so [1,,2] is repaired into [1,2] rather than [1,null,2].
+func WithDropEmptyArraySlots() Option {
+	return func(o *options) {
+		o.dropEmptySlots = true
+	}
+}
+
+// WithDropBareKeys drops object keys without a value, like a and b in the ES6 shorthand
+// {a, b, c: 1}, instead of giving them the value null.
+func WithDropBareKeys() Option {
+	return func(o *options) {
+		o.dropBareKeys = true
+	}
+}
+
+// WithHashComments removes line comments starting with a hash, like in Python, YAML and shell
+// scripts: {"a": 1 # comment}. Inside an unquoted value, a hash only starts a comment after
+// whitespace, so a value like C# is kept.
+func WithHashComments() Option {
+	return func(o *options) {
+		o.hashComments = true
+	}
+}
+
+// defaultTagWrappers are the names of the tags removed around the value by default.
+var defaultTagWrappers = []string{"json", "tool_call", "function_call", "tool_use"}
+
+// WithTagWrappers sets the names of the XML or HTML tags which are removed around the value,
+// like <tool_call>{...}</tool_call>, replacing the default list of json, tool_call,
+// function_call and tool_use. Other tags, like <output> or <answer>, are only removed
+// when named. Without names no tags are removed.
+func WithTagWrappers(names ...string) Option {
+	return func(o *options) {
+		o.tagWrappers = names
+	}
+}
+
+// WithHTMLEntities decodes HTML entities like &quot;, &amp; and &#34; inside strings, as
+// found in JSON scraped from web pages. Entities for double quotes may also form the quotes
+// of a string, like in {&quot;a&quot;: 1}. A double encoded entity like &amp;quot; is decoded
+// twice.
+func WithHTMLEntities() Option {
+	return func(o *options) {
+		o.htmlEntities = true
+	}
+}
+
+// WithColonsInKeys keeps colons inside unquoted namespaced keys, like {db:host: "x"} which
+// becomes {"db:host": "x"}: the last colon before the value separates the key from the value.
+// By default an unquoted key ends at the first colon.
+func WithColonsInKeys() Option {
+	return func(o *options) {
+		o.colonsInKeys = true
+	}
+}
+
+// WithDelimiters adds characters which separate the items of arrays and the members of objects
+// like a comma, for formats like [1|2|3] which becomes [1,2,3]. Unquoted strings and numbers
+// end at them. Characters with a meaning of their own, like quotes, brackets, colons, commas,
+// letters, digits and whitespace, can not be delimiters: the repair fails with ErrInvalidOption.
+func WithDelimiters(chars string) Option {
+	return func(o *options) {
+		o.delimiters += chars
+	}
+}
+
+// WithWhitespace adds characters which are whitespace like a space, for formats like
+// {a:~1,~b:~2} which becomes {"a": 1, "b": 2}. They are replaced with a space, and are trimmed
+// from the end of unquoted strings. The same characters as for WithDelimiters are not accepted.
+func WithWhitespace(chars string) Option {
+	return func(o *options) {
+		o.whitespace += chars
+	}
+}
+
+// WithNestedStringRepair repairs the JSON embedded in string values of members whose key
+// matches the pattern, like {"payload": "{\"a\": 1,}"} which becomes {"payload": "{\"a\": 1}"}.
+// Only strings containing an object or array are repaired, with the same options, so strings
+// nested deeper are repaired too. A string whose content can not be repaired is kept.
+func WithNestedStringRepair(keyPattern *regexp.Regexp) Option {
+	return func(o *options) {
+		o.nestedKeys = keyPattern
+	}
+}
+
+// WithInlineNestedStrings inlines the JSON repaired with WithNestedStringRepair as a value
+// instead of a string, so {"payload": "{\"a\": 1,}"} becomes {"payload": {"a": 1}}.
+func WithInlineNestedStrings() Option {
+	return func(o *options) {
+		o.inlineNested = true
+	}
+}
+
+// WithYAMLBlocks converts a document written in YAML block style, like name: John lines or
+// - item lines nested by indentation, to JSON with RepairYAMLBlock, instead of failing on it.
+// A document is converted when its first line which is not blank or a comment is a key: value
+// pair or a - item.
+func WithYAMLBlocks() Option {
+	return func(o *options) {
+		o.yamlBlocks = true
+	}
+}
+
+// WithMarkdown removes the Markdown markup which chat answers wrap around JSON, next to the code
+// fences which are always removed: headings like ### Result and list bullets like - and * at
+// the start of a line, and **bold** markers. A list of values, one per bullet, becomes an array.
+// A minus followed by a space at the start of a line is a bullet then, not a negative sign.
+func WithMarkdown() Option {
+	return func(o *options) {
+		o.markdown = true
+	}
+}
+
+// WithNewlineStyle sets the line breaks of the repaired output, like NewlineLF to turn the
+// \r\n line breaks of input from Windows into \n. The default is NewlinePreserve.
+func WithNewlineStyle(style NewlineStyle) Option {
+	return func(o *options) {
+		o.newlineStyle = style
+	}
+}
+
+// WithWhitespacePolicy sets the whitespace of the repaired output: the whitespace of the input
+// is kept with WhitespacePreserve, the default, or the output is reformatted with
+// WhitespaceIndent or WhitespaceCompact. Reformatting can not be combined with WithAnnotations.
+func WithWhitespacePolicy(policy WhitespacePolicy) Option {
+	return func(o *options) {
+		o.whitespacePolicy = policy
+	}
+}
+
+// WithEscapeNonASCII escapes all characters above U+007F in the output as \uXXXX, with a
+// surrogate pair like \ud83d\ude00 for characters above U+FFFF, so the output is pure ASCII
+// for transports which are not 8-bit clean.
+func WithEscapeNonASCII() Option {
+	return func(o *options) {
+		o.escapeNonASCII = true
+	}
+}
+
+// WithEscapeHTML escapes <, >, & and the line and paragraph separators U+2028 and U+2029 in the
+// output as \u003c, \u003e, \u0026, \u2028 and \u2029, like encoding/json does by default,
+// so the output can be embedded in an HTML <script> tag.
+func WithEscapeHTML() Option {
+	return func(o *options) {
+		o.escapeHTML = true
+	}
+}
+
+// WithSurrogatePolicy sets how an escaped surrogate without its other half, like \ud83d at the
+// end of a truncated string, is repaired. The default is SurrogateReplace.
+func WithSurrogatePolicy(policy SurrogatePolicy) Option {
+	return func(o *options) {
+		o.surrogatePolicy = policy
+	}
+}
+
+// WithInvalidUTF8Policy sets how bytes of the input which are not valid UTF-8 are repaired.
+// The default is InvalidUTF8Replace. It has no effect with WithCharsetDetection, which
+// transcodes such input. Report.InvalidUTF8 counts the invalid bytes.
+func WithInvalidUTF8Policy(policy InvalidUTF8Policy) Option {
+	return func(o *options) {
+		o.invalidUTF8Policy = policy
+	}
+}
+
+// WithMaxStringScan limits the scan for the end quote of a string past the first delimiter in
+// it, like a comma or a newline, where the string ends when its end quote is missing. When no
+// end quote follows within n characters after that delimiter, the end quote is considered
+// missing, so an unterminated string in a large document is not scanned up to the end of the
+// document. A string without delimiters is never cut. By default there is no limit.
+func WithMaxStringScan(n int) Option {
+	return func(o *options) {
+		o.maxStringScan = n
+	}
+}
+
+// WithMaxDepth limits the nesting depth of arrays, objects and function calls, so deeply nested
+// input like [[[[... can not exhaust the stack. A deeper input fails the repair with ErrMaxDepth.
+// The default is 10000, like encoding/json, and 0 removes the limit.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxInputSize limits the size of the input in bytes, so oversized input from untrusted
+// clients is rejected with ErrInputTooLarge before it is processed. By default there is no limit.
+func WithMaxInputSize(bytes int) Option {
+	return func(o *options) {
+		o.maxInputSize = bytes
+	}
+}
+
+// WithMaxOutputSize limits the size of the repaired output in bytes, so a pathological input,
+// which is expanded by the repairs like a text with many backslashes to escape, can not grow the
+// buffers of the caller beyond it. A larger output fails with ErrOutputTooLarge. By default
+// there is no limit.
+func WithMaxOutputSize(bytes int) Option {
+	return func(o *options) {
+		o.maxOutputSize = bytes
+	}
+}
+
+// WithAllErrors makes a failed repair go on after the first error and return all the errors of
+// the text joined with errors.Join, so a text fixed by hand is fixed in one pass. After an error,
+// the unexpected text where the repair stopped is skipped and the rest is repaired again, up to
+// maxJoinedErrors errors. errors.Is matches any of the errors, and errors.As finds the first.
+// The errors of a text which was transcoded or decoded are not collected.
+func WithAllErrors() Option {
+	return func(o *options) {
+		o.allErrors = true
+	}
+}
+
+// WithMergeStrategy sets how MergeRepaired combines the documents. The default is MergePatch.
+func WithMergeStrategy(strategy MergeStrategy) Option {
+	return func(o *options) {
+		o.mergeStrategy = strategy
+	}
+}
@@ -0,0 +1,56 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// regexEncodedStructuralChar matches the percent-encoding of a character which structures JSON:
+// a double quote, comma, colon, bracket or brace.
+var regexEncodedStructuralChar = regexp.MustCompile(`%(22|2[Cc]|3[Aa]|5[BbDd]|7[BbDd])`)
+
+// decodePercentEncoding decodes text which is percent-encoded (URL-encoded) as a whole, like
+// %7B%22a%22%3A1%7D, or in parts, like {%22a%22:1}. The text is only decoded when it encodes
+// a character which structures JSON and is not valid JSON already. When no structural
+// character is left unencoded, the text was form-encoded as a whole and a plus is a space.
+// Percent signs which do not start an escape, like in 100%, are kept. It returns whether
+// the text was decoded.
+func decodePercentEncoding(text string) (string, bool) {
+	if !regexEncodedStructuralChar.MatchString(text) || json.Valid([]byte(text)) {
+		return text, false
+	}
+	formEncoded := !strings.ContainsAny(text, `"{}[]:,`)
+	return percentDecode(text, formEncoded), true
+}
+
+// percentDecode decodes the escapes like %22 of percent-encoded text, and a plus as a space
+// when plusAsSpace is set. Percent signs which do not start an escape are kept.
+func percentDecode(text string, plusAsSpace bool) string {
+	var output strings.Builder
+	output.Grow(len(text))
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '%' && i+2 < len(text) && isHex(rune(text[i+1])) && isHex(rune(text[i+2])):
+			output.WriteByte(hexValue(text[i+1])<<4 | hexValue(text[i+2]))
+			i += 2
+		case text[i] == '+' && plusAsSpace:
+			output.WriteByte(' ')
+		default:
+			output.WriteByte(text[i])
+		}
+	}
+	return output.String()
+}
+
+// hexValue returns the value of a hexadecimal digit.
+func hexValue(b byte) byte {
+	switch {
+	case b >= 'a':
+		return b - 'a' + 10
+	case b >= 'A':
+		return b - 'A' + 10
+	default:
+		return b - '0'
+	}
+}
@@ -0,0 +1,47 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePercentEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		decoded  bool
+	}{
+		{"encoded", "%7B%22a%22%3A1%7D", `{"a":1}`, true},
+		{"lowercase", "%7b%22a%22%3a%5b1%2c2%5d%7d", `{"a":[1,2]}`, true},
+		{"form encoded", "%7B%22a%22%3A%22b+c%22%7D", `{"a":"b c"}`, true},
+		{"partially encoded", `{%22a%22:"b+c"}`, `{"a":"b+c"}`, true},
+		{"utf-8", "%22caf%C3%A9%22", `"café"`, true},
+		{"invalid escape", "%7B%22a%22:%22100%%22%7D", `{"a":"100%"}`, true},
+		{"escape at end", "%5B1%2C2%2", "[1,2%2", true},
+		{"no structural escapes", `"caf%C3%A9"`, `"caf%C3%A9"`, false},
+		{"valid json", `{"url":"?q=%22a%22"}`, `{"url":"?q=%22a%22"}`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, decoded := decodePercentEncoding(test.text)
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, test.decoded, decoded)
+		})
+	}
+}
+
+func TestURLDecoding(t *testing.T) {
+	var report Report
+	result, err := JSONRepair("%7Ba%3A%22b%22%2C%22c%22%3A%5B1%2C2%5D%7D", WithURLDecoding(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":"b","c":[1,2]}`, result)
+	assert.True(t, report.URLDecoded)
+
+	result, err = JSONRepair("%7B%22a%22%3A1%7D")
+	require.NoError(t, err)
+	assert.Equal(t, `"%7B%22a%22%3A1%7D"`, result)
+}
@@ -0,0 +1,91 @@
+package jsonrepair
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule is a custom repair, plugged in with WithCustomRules, for damage which is specific to an
+// application, like the broken timestamps of a vendor. Custom rules are tried in value
+// position before the built-in repairs, in the order they were added.
+type Rule interface {
+	// Match checks if the rule applies to the value at the position of the context. It must
+	// not skip input or write output.
+	Match(ctx *ParseContext) bool
+
+	// Apply repairs the value at the position of the context: it skips the input of the value
+	// and writes a single valid JSON value instead. An error stops the repair and is returned
+	// by JSONRepair as it is.
+	Apply(ctx *ParseContext) error
+}
+
+// ParseContext is the state of the repair at the start of a value, which is given to a Rule.
+// It is only valid during the call of Match or Apply.
+type ParseContext struct {
+	c      *cursor
+	output *strings.Builder
+	opts   *options
+}
+
+// Position returns the offset of the value in the text in runes, like Repair.Position.
+func (ctx *ParseContext) Position() int {
+	return ctx.opts.offset + ctx.c.pos
+}
+
+// Peek returns the next n runes of the text, or less at the end of the text.
+func (ctx *ParseContext) Peek(n int) string {
+	return string(ctx.c.slice(ctx.c.pos, min(ctx.c.pos+max(n, 0), ctx.c.len())))
+}
+
+// HasPrefix checks if the text continues with the prefix.
+func (ctx *ParseContext) HasPrefix(prefix string) bool {
+	return ctx.c.hasPrefix(prefix)
+}
+
+// Skip skips the next n runes of the text, and returns the skipped text.
+func (ctx *ParseContext) Skip(n int) string {
+	skipped := ctx.Peek(n)
+	ctx.c.skip(utf8.RuneCountInString(skipped))
+	return skipped
+}
+
+// Write writes JSON to the output.
+func (ctx *ParseContext) Write(json string) {
+	ctx.output.WriteString(json)
+}
+
+// WithCustomRules adds custom repairs, see Rule. Valid JSON is parsed when custom rules are
+// added, so that they apply to it too.
+func WithCustomRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.customRules = append(o.customRules, rules...)
+	}
+}
+
+// parseCustomRule repairs the value at the cursor with the first custom rule which matches it.
+func parseCustomRule(c *cursor, output *strings.Builder, opts *options) bool {
+	if len(opts.customRules) == 0 || c.done() {
+		return false
+	}
+	ctx := &ParseContext{c: c, output: output, opts: opts}
+	start := c.pos
+	for _, r := range opts.customRules {
+		if !r.Match(ctx) {
+			continue
+		}
+		c.pos = start
+		if err := r.Apply(ctx); err != nil {
+			opts.fail(err)
+			return false
+		}
+		if c.pos == start {
+			// a rule which skips nothing would be applied again and again
+			opts.fail(newError(ErrNoProgress, "", opts.offset+start))
+			return false
+		}
+		logRepair(start, output, "applied custom rule", opts)
+		return true
+	}
+	c.pos = start
+	return false
+}
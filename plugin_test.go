@@ -0,0 +1,67 @@
+package jsonrepair
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vendorTimestamp repairs timestamps like 2024/01/02-10h30 into "2024-01-02T10:30:00Z".
+type vendorTimestamp struct{}
+
+var regexVendorTimestamp = regexp.MustCompile(`^(\d{4})/(\d{2})/(\d{2})-(\d{2})h(\d{2})$`)
+
+func (vendorTimestamp) Match(ctx *ParseContext) bool {
+	return regexVendorTimestamp.MatchString(ctx.Peek(16))
+}
+
+func (vendorTimestamp) Apply(ctx *ParseContext) error {
+	m := regexVendorTimestamp.FindStringSubmatch(ctx.Skip(16))
+	ctx.Write(fmt.Sprintf(`"%s-%s-%sT%s:%s:00Z"`, m[1], m[2], m[3], m[4], m[5]))
+	return nil
+}
+
+// notAvailable replaces the string "N/A" with null, or fails on "N/A!".
+type notAvailable struct{}
+
+func (notAvailable) Match(ctx *ParseContext) bool {
+	return ctx.HasPrefix(`"N/A`)
+}
+
+func (notAvailable) Apply(ctx *ParseContext) error {
+	if ctx.HasPrefix(`"N/A!`) {
+		return errors.New("not available")
+	}
+	ctx.Skip(len(`"N/A"`))
+	ctx.Write("null")
+	return nil
+}
+
+// stuck matches everything and skips nothing.
+type stuck struct{}
+
+func (stuck) Match(*ParseContext) bool  { return true }
+func (stuck) Apply(*ParseContext) error { return nil }
+
+func TestCustomRules(t *testing.T) {
+	rules := WithCustomRules(vendorTimestamp{}, notAvailable{})
+	assertRepair(t, `{at: 2024/01/02-10h30, price: "N/A"}`, `{"at": "2024-01-02T10:30:00Z", "price": null}`, rules)
+
+	// valid JSON is repaired too
+	assertRepair(t, `["N/A", "n/a"]`, `[null, "n/a"]`, rules)
+
+	var report Report
+	_, err := JSONRepair(`[2024/01/02-10h30]`, rules, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 1, Kind: RepairCustom, Message: "applied custom rule"}}, report.Repairs)
+
+	_, err = JSONRepair(`{"price": "N/A!"}`, rules)
+	require.EqualError(t, err, "not available")
+
+	_, err = JSONRepair(`[1]`, WithCustomRules(stuck{}))
+	require.ErrorIs(t, err, ErrNoProgress)
+}
@@ -0,0 +1,46 @@
+package jsonrepair
+
+import "unicode/utf8"
+
+// RuneToByteOffset converts an offset in runes in text, like Error.Position or
+// Repair.Position, to an offset in bytes. An offset past the end of the text is
+// clamped to the length of the text.
+func RuneToByteOffset(text string, position int) int {
+	offset := 0
+	for k := 0; k < position && offset < len(text); k++ {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// ByteToRuneOffset converts an offset in bytes in text, like the Start and End of Extracted,
+// to an offset in runes. An offset inside of a multi-byte rune counts that rune.
+func ByteToRuneOffset(text string, offset int) int {
+	offset = min(max(offset, 0), len(text))
+	return utf8.RuneCountInString(text[:offset])
+}
+
+// LineColumn returns the line and the column in runes, both starting at 1, of an offset in runes in text.
+func LineColumn(text string, position int) (line, column int) {
+	return lineColumn([]rune(text), position)
+}
+
+// lineColumn returns the line and column of a position in the text.
+func lineColumn(text []rune, position int) (line, column int) {
+	line, column = 1, 1
+	for _, char := range text[:min(max(position, 0), len(text))] {
+		if char == codeNewline {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// byteOffset returns the offset in bytes of a position in the text, clamped to the text.
+func byteOffset(text []rune, pos int) int {
+	return len(string(text[:min(max(pos, 0), len(text))]))
+}
@@ -0,0 +1,64 @@
+package jsonrepair
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorPosition(t *testing.T) {
+	text := "{\n  \"naïve\": 1,\n  \"café\": x}y"
+	_, err := JSONRepair(text)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+	assert.EqualError(t, err, "unexpected character: 'y' at position 28")
+
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, 28, e.Position)
+	assert.Equal(t, 30, e.ByteOffset)
+	assert.Equal(t, "y", text[e.ByteOffset:])
+	assert.Equal(t, 'y', []rune(text)[e.Position])
+	assert.Equal(t, 3, e.Line)
+	assert.Equal(t, 13, e.Column)
+
+	_, err = JSONRepair("#!/bin/sh\n{\"é\": 1}}x")
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "'x'", e.Detail)
+	assert.Equal(t, 19, e.Position)
+	assert.Equal(t, 20, e.ByteOffset)
+	assert.Equal(t, 2, e.Line)
+	assert.Equal(t, 10, e.Column)
+
+	_, err = JSONRepair("")
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrUnexpectedEnd, e.Err)
+	assert.Equal(t, 1, e.Line)
+	assert.Equal(t, 1, e.Column)
+}
+
+func TestPositionConversion(t *testing.T) {
+	text := "a★\nbé"
+	assert.Equal(t, 0, RuneToByteOffset(text, 0))
+	assert.Equal(t, 1, RuneToByteOffset(text, 1))
+	assert.Equal(t, 4, RuneToByteOffset(text, 2))
+	assert.Equal(t, 8, RuneToByteOffset(text, 5))
+	assert.Equal(t, 8, RuneToByteOffset(text, 9))
+
+	assert.Equal(t, 0, ByteToRuneOffset(text, 0))
+	assert.Equal(t, 2, ByteToRuneOffset(text, 4))
+	assert.Equal(t, 5, ByteToRuneOffset(text, 8))
+	assert.Equal(t, 5, ByteToRuneOffset(text, 20))
+
+	for position := 0; position <= 5; position++ {
+		assert.Equal(t, position, ByteToRuneOffset(text, RuneToByteOffset(text, position)))
+	}
+
+	line, column := LineColumn(text, 4)
+	assert.Equal(t, 2, line)
+	assert.Equal(t, 2, column)
+	line, column = LineColumn(text, 2)
+	assert.Equal(t, 1, line)
+	assert.Equal(t, 3, column)
+}
@@ -0,0 +1,103 @@
+package jsonrepair
+
+import "github.com/kaptinlin/jsonrepair/rule"
+
+// Presets combine the options for a source of input, like the chat answers of an LLM or the
+// output of the MongoDB shell. A preset enables the rules for its source and disables the
+// others, so damage its source does not produce fails instead of being guessed at. Options
+// after a preset override it, like PresetPython(), WithRules(rule.MissingCommas).
+
+// PresetLLM repairs the chat answers of language models, which can have any damage: all rules
+// are enabled, and Markdown markup around the JSON is removed, see WithMarkdown.
+func PresetLLM() Option {
+	return presetOptions(rule.All(), WithMarkdown())
+}
+
+// PresetJavaScript repairs JavaScript object literals and JSON5, like a config file or code
+// copied from a script: comments, trailing commas, unquoted keys, single quotes, undefined,
+// sparse arrays, concatenated strings and a variable assignment or return statement around
+// the value.
+func PresetJavaScript() Option {
+	return presetOptions([]rule.Rule{
+		rule.StripComments, rule.TrailingCommas, rule.MissingQuotes, rule.NormalizeQuotes,
+		rule.Keywords, rule.Numbers, rule.EmptySlots, rule.ConcatenateStrings,
+		rule.StripWrappers, rule.FunctionCalls, rule.ControlCharacters,
+	})
+}
+
+// PresetPython repairs the repr of Python dicts and lists, and Python literals: single quotes,
+// True, False and None, trailing commas, # comments and strings concatenated with +.
+func PresetPython() Option {
+	return presetOptions([]rule.Rule{
+		rule.StripComments, rule.TrailingCommas, rule.NormalizeQuotes, rule.Keywords,
+		rule.ConcatenateStrings, rule.FunctionCalls, rule.ControlCharacters,
+	}, WithHashComments())
+}
+
+// PresetMongoDB repairs the output of the MongoDB shell, like { _id: ObjectId("..."),
+// n: NumberLong(5) }: shell types, unquoted keys, single quotes, trailing commas, comments and
+// one document after the other.
+func PresetMongoDB() Option {
+	return presetOptions([]rule.Rule{
+		rule.FunctionCalls, rule.MissingQuotes, rule.NormalizeQuotes, rule.TrailingCommas,
+		rule.StripComments, rule.Keywords, rule.NewlineDelimited,
+	})
+}
+
+// PresetStrict disables all rules, so only a text which needs no repair of a rule, like valid
+// JSON, is accepted. Add the repairs to accept with WithRules, like PresetStrict(),
+// WithRules(rule.TrailingCommas).
+func PresetStrict() Option {
+	return presetOptions(nil)
+}
+
+// presetOptions returns an option which enables only the given rules, and applies the options.
+func presetOptions(rules []rule.Rule, opts ...Option) Option {
+	return func(o *options) {
+		o.setRules(rule.All(), false)
+		o.setRules(rules, true)
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
+
+// Aggressiveness defines how far the repair goes in guessing the intent of damaged input.
+type Aggressiveness int
+
+const (
+	// AggressivenessDefault applies all rules, including the ones which guess, like the quotes
+	// added around unquoted values and the end quote which splits ["a, b"] into ["a", "b"].
+	AggressivenessDefault Aggressiveness = iota
+
+	// AggressivenessConservative only applies the repairs which lose nothing and guess nothing:
+	// trailing commas and comments are removed, control characters escaped and the brackets of
+	// a truncated text closed. Any other damage fails the repair with ErrRuleDisabled, which
+	// suits data like financial records, where a wrong guess is worse than an error.
+	AggressivenessConservative
+
+	// AggressivenessAggressive applies all rules, and the heuristics which are disabled by
+	// default since they can misfire: HTML entities are decoded, # starts a comment, replacement
+	// characters are taken as lost quotes, and Markdown markup is removed.
+	AggressivenessAggressive
+)
+
+// conservativeRules are the rules applied with AggressivenessConservative.
+var conservativeRules = []rule.Rule{rule.TrailingCommas, rule.CloseBrackets, rule.StripComments, rule.ControlCharacters}
+
+// WithAggressiveness sets how far the repair goes in guessing, by enabling and disabling rules
+// and heuristics. The default is AggressivenessDefault. Like a preset, options after it
+// override it.
+func WithAggressiveness(aggressiveness Aggressiveness) Option {
+	return func(o *options) {
+		o.aggressiveness = aggressiveness
+		switch aggressiveness {
+		case AggressivenessDefault:
+			o.setRules(rule.All(), true)
+		case AggressivenessConservative:
+			presetOptions(conservativeRules)(o)
+		case AggressivenessAggressive:
+			presetOptions(rule.All(), WithHTMLEntities(), WithHashComments(), WithReplacementCharQuotes(), WithMarkdown())(o)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/jsonrepair/rule"
+)
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   Option
+		text     string
+		expected string
+	}{
+		{"llm", PresetLLM(), "### Result\n```json\n{\"a\": [1, 2\n```", "\n\n{\"a\": [1, 2]}\n"},
+		{"javascript", PresetJavaScript(), "const x = {a: 'b', c: undefined, d: [1,,2], // c\n};", "{\"a\": \"b\", \"c\": null, \"d\": [1,null,2] \n}"},
+		{"python", PresetPython(), "{'a': True, 'b': None,} # c", `{"a": true, "b": null} `},
+		{"mongodb", PresetMongoDB(), "{_id: ObjectId('5f'), n: NumberLong(5)}\n{_id: ObjectId('60')}", "[\n{\"_id\": \"5f\", \"n\": 5},\n{\"_id\": \"60\"}\n]"},
+		{"strict", PresetStrict(), `{"a": [1, 2]}`, `{"a": [1, 2]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRepair(t, tt.text, tt.expected, tt.preset)
+		})
+	}
+}
+
+func TestPresetsReject(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   Option
+		text     string
+		expected string
+	}{
+		{"javascript", PresetJavaScript(), `{"a": [1, 2`, "repair rule disabled: close-brackets at position 11"},
+		{"python", PresetPython(), `{"a" 1}`, "repair rule disabled: missing-colons at position 5"},
+		{"mongodb", PresetMongoDB(), `[1 2]`, "repair rule disabled: missing-commas at position 3"},
+		{"strict", PresetStrict(), `[1, 2,]`, "repair rule disabled: trailing-commas at position 6"},
+		{"strict", PresetStrict(), `{"a":"\x"}`, "repair rule disabled: invalid-escapes at position 6"},
+		{"conservative", WithAggressiveness(AggressivenessConservative), `array(1,2)`, "repair rule disabled: separators at position 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := JSONRepair(tt.text, tt.preset)
+			require.ErrorIs(t, err, ErrRuleDisabled)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestPresetOverride(t *testing.T) {
+	assertRepair(t, `[1, 2,]`, `[1, 2]`, PresetStrict(), WithRules(rule.TrailingCommas))
+
+	// a preset overrides the rules before it
+	assertRepair(t, `[1, 2,]`, `[1, 2]`, WithoutRules(rule.TrailingCommas), PresetLLM())
+}
+
+func TestAggressiveness(t *testing.T) {
+	conservative := WithAggressiveness(AggressivenessConservative)
+	assertRepair(t, "{\"a\": [1, 2,], // c\n\"b\": \"x\ty\"", "{\"a\": [1, 2], \n\"b\": \"x\\ty\"}", conservative)
+	for text, expected := range map[string]string{
+		`{a: 1}`:          "repair rule disabled: missing-quotes at position 1",
+		`["a, "b"]`:       "repair rule disabled: missing-end-quotes at position 3",
+		`{"a": 2.}`:       "repair rule disabled: numbers at position 6",
+		`[1, 2, ...]`:     "repair rule disabled: ellipsis at position 7",
+		`callback([1])`:   "repair rule disabled: function-calls at position 0",
+		`{"a": 1 "b": 2}`: "repair rule disabled: missing-commas at position 8",
+	} {
+		_, err := JSONRepair(text, conservative)
+		assert.EqualError(t, err, expected, text)
+	}
+
+	assertRepair(t, `["a, "b"]`, `["a", "b"]`, WithAggressiveness(AggressivenessDefault))
+	assertRepair(t, `["a, "b"]`, `["a", "b"]`, conservative, WithAggressiveness(AggressivenessDefault))
+
+	aggressive := WithAggressiveness(AggressivenessAggressive)
+	assertRepair(t, `{"a": "&lt;b&gt;"} # c`, `{"a": "<b>"} `, aggressive)
+	assertRepair(t, `{"a": "&lt;b&gt;"}`, `{"a": "&lt;b&gt;"}`)
+}
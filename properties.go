@@ -0,0 +1,125 @@
+package jsonrepair
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RepairProperties converts KEY=value lines, like a .env file or a Java properties file, to a
+// flat JSON object, with the keys in order of appearance. A key may be separated from its value
+// by an equals sign, a colon or whitespace, and may be preceded by export. Lines starting with
+// # or ! are comments, and an unquoted value ends at a # after whitespace. A line ending with a
+// backslash continues on the next line. Values in double or single quotes are strings, other
+// values are typed like the values of RepairQueryString: PORT=8080 becomes a number, DEBUG=true
+// a boolean and NAME=app a string. A key which is repeated takes its last value.
+func RepairProperties(text string, opts ...Option) (string, error) {
+	o := newOptions(opts...)
+	o.checkInputSize(len(text))
+	if o.err != nil {
+		return "", o.err
+	}
+
+	root := &orderedObject{values: map[string]any{}}
+	for _, line := range propertyLines(text) {
+		line = strings.TrimLeft(line, " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		key, value := splitProperty(strings.TrimPrefix(line, "export "))
+		root.set(key, propertyValue(value, o))
+	}
+	output, err := encodeValue(root)
+	if err != nil {
+		return "", err
+	}
+	return o.limitOutput(output)
+}
+
+// propertyLines splits the text into lines, joining a line ending with an odd number of
+// backslashes with the next line without its leading whitespace.
+func propertyLines(text string) []string {
+	var lines []string
+	var continued strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if continued.Len() > 0 {
+			line = strings.TrimLeft(line, " \t\f")
+		}
+		trimmed := strings.TrimRight(line, "\\")
+		if (len(line)-len(trimmed))%2 == 1 {
+			continued.WriteString(line[:len(line)-1])
+			continue
+		}
+		continued.WriteString(line)
+		lines = append(lines, continued.String())
+		continued.Reset()
+	}
+	if continued.Len() > 0 {
+		lines = append(lines, continued.String())
+	}
+	return lines
+}
+
+// splitProperty splits a line into its key and value, at the first equals sign or colon which
+// is not escaped with a backslash, or else at the first whitespace. Escapes in the key like
+// \= are removed.
+func splitProperty(line string) (string, string) {
+	var key strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch char := line[i]; {
+		case char == '\\' && i+1 < len(line):
+			i++
+			key.WriteByte(line[i])
+		case char == '=' || char == ':':
+			return strings.TrimSpace(key.String()), line[i+1:]
+		case char == ' ' || char == '\t':
+			// whitespace separates the key only when no equals sign or colon follows
+			rest := strings.TrimLeft(line[i:], " \t")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				return key.String(), rest[1:]
+			}
+			return key.String(), rest
+		default:
+			key.WriteByte(char)
+		}
+	}
+	return key.String(), ""
+}
+
+// regexUnicodeEscape matches an escaped character of a properties file like \u00e9.
+var regexUnicodeEscape = regexp.MustCompile(`\\u[0-9a-fA-F]{4}`)
+
+// propertyEscapes maps the escapes decoded in a value in double quotes to their character.
+var propertyEscapes = map[byte]byte{'n': '\n', 't': '\t', 'r': '\r'}
+
+// propertyValue types the value of a property. A value in quotes is a string, where the escapes
+// of double quotes are decoded and an unterminated quote is repaired. Other values end at a #
+// after whitespace, and are typed with inferValue.
+func propertyValue(value string, opts *options) any {
+	value = strings.TrimSpace(value)
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		quote := value[0]
+		var result strings.Builder
+		for i := 1; i < len(value) && value[i] != quote; i++ {
+			if quote == '"' && value[i] == '\\' && i+1 < len(value) {
+				i++
+				if escaped, ok := propertyEscapes[value[i]]; ok {
+					result.WriteByte(escaped)
+					continue
+				}
+			}
+			result.WriteByte(value[i])
+		}
+		return result.String()
+	}
+
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+	value = regexUnicodeEscape.ReplaceAllStringFunc(value, func(escape string) string {
+		code, _ := strconv.ParseUint(escape[2:], 16, 32)
+		return string(rune(code))
+	})
+	return inferValue(value, opts)
+}
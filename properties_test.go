@@ -0,0 +1,41 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairProperties(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"dotenv", "# database\nexport PORT=8080\nDEBUG=true\nNAME=app\nEMPTY=\n", `{"PORT":8080,"DEBUG":true,"NAME":"app","EMPTY":""}`},
+		{"inline comment", "NAME=app # the name\nURL=http://a.com/#frag", `{"NAME":"app","URL":"http://a.com/#frag"}`},
+		{"double quotes", `Q="a \"b\"\nc # d"`, `{"Q":"a \"b\"\nc # d"}`},
+		{"single quotes", `S='x \n 1'`, `{"S":"x \\n 1"}`},
+		{"quoted number", `V="8080"`, `{"V":"8080"}`},
+		{"unterminated quote", `S="abc`, `{"S":"abc"}`},
+		{"properties", "! comment\nkey1 = value one\nkey2: 2\nkey3 value three", `{"key1":"value one","key2":2,"key3":"value three"}`},
+		{"continuation", "long = a, \\\n    b, \\\n    c\nnext=1", `{"long":"a, b, c","next":1}`},
+		{"escaped backslash", "path=C:\\\\\nnext=1", `{"path":"C:\\\\","next":1}`},
+		{"unicode escape", `name=caf\u00e9`, `{"name":"café"}`},
+		{"escaped separator", `k\=x=1`, `{"k=x":1}`},
+		{"repeated key", "k=1\r\nk=2\r\n", `{"k":2}`},
+		{"strings which look like numbers", "zip=007\nversion=1.2.3", `{"zip":"007","version":"1.2.3"}`},
+		{"json value", "JSON={a:1, b:[1 2]}", `{"JSON":{"a":1,"b":[1,2]}}`},
+		{"key without value", "flag", `{"flag":""}`},
+		{"empty", "\n\n", `{}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := RepairProperties(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package jsonrepair
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxQueryIndex is the number of digits of the largest array index in a query string key.
+const maxQueryIndex = 9
+
+// RepairQueryString converts a query string or form body, like a=1&b=hello&c[0]=x, to a JSON
+// object, for form data which was sent as JSON. Keys and values are percent-decoded, with a
+// plus as a space. Brackets in keys nest values: c[0]=x and c[]=x build arrays, a[b]=1 builds
+// objects. A key which is repeated collects its values in an array. Values are typed by the
+// repair: numbers, booleans and null are kept, values like {a:1} or ['x'] are repaired with the
+// given options, and other values become strings, so that a value like 007 is kept as "007".
+func RepairQueryString(text string, opts ...Option) (string, error) {
+	o := newOptions(opts...)
+	o.checkInputSize(len(text))
+	if o.err != nil {
+		return "", o.err
+	}
+
+	root := &orderedObject{values: map[string]any{}}
+	text = strings.TrimPrefix(strings.TrimSpace(text), "?")
+	for _, pair := range strings.Split(text, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		name, path := parseQueryKey(percentDecode(key, true))
+		current, exists := root.values[name]
+		root.set(name, setQueryValue(current, exists, path, inferValue(percentDecode(value, true), o)))
+	}
+
+	output, err := encodeValue(root)
+	if err != nil {
+		return "", err
+	}
+	return o.limitOutput(output)
+}
+
+// parseQueryKey splits a key like a[b][0][] into its name a and the path [b 0 ""]. A key with
+// unbalanced brackets is a name as a whole.
+func parseQueryKey(key string) (string, []string) {
+	start := strings.IndexByte(key, '[')
+	if start <= 0 || !strings.HasSuffix(key, "]") {
+		return key, nil
+	}
+	var path []string
+	for rest := key[start:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 || strings.IndexByte(rest[1:end], '[') >= 0 {
+			return key, nil
+		}
+		path = append(path, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return key[:start], path
+}
+
+// setQueryValue sets the value at the path in the current value, which exists when it was set
+// before, and returns the updated value. An empty or numeric segment of the path is an index
+// in an array, where an empty or too large index appends. Setting a value which exists already
+// collects the values in an array.
+func setQueryValue(current any, exists bool, path []string, value any) any {
+	if len(path) == 0 {
+		if !exists {
+			return value
+		}
+		if array, ok := current.([]any); ok {
+			return append(array, value)
+		}
+		return []any{current, value}
+	}
+
+	segment, rest := path[0], path[1:]
+	if segment == "" || isQueryIndex(segment) {
+		array, _ := current.([]any)
+		if index, err := strconv.Atoi(segment); err == nil && index < len(array) {
+			array[index] = setQueryValue(array[index], true, rest, value)
+			return array
+		}
+		return append(array, setQueryValue(nil, false, rest, value))
+	}
+
+	obj, ok := current.(*orderedObject)
+	if !ok {
+		obj = &orderedObject{values: map[string]any{}}
+	}
+	member, exists := obj.values[segment]
+	obj.set(segment, setQueryValue(member, exists, rest, value))
+	return obj
+}
+
+// isQueryIndex checks if a segment of a key is an array index like 0.
+func isQueryIndex(segment string) bool {
+	if len(segment) > maxQueryIndex {
+		return false
+	}
+	for _, char := range segment {
+		if !isDigit(char) {
+			return false
+		}
+	}
+	return segment != ""
+}
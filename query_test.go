@@ -0,0 +1,43 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairQueryString(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"pairs", "a=1&b=hello&c=true&d=null", `{"a":1,"b":"hello","c":true,"d":null}`},
+		{"question mark", "?a=1", `{"a":1}`},
+		{"empty", "", `{}`},
+		{"percent-encoded", "b=hello+world&q=a%26b%3Dc&k%20ey=%E2%98%85", `{"b":"hello world","q":"a&b=c","k ey":"★"}`},
+		{"indexed array", "c[0]=x&c[1]=y", `{"c":["x","y"]}`},
+		{"appended array", "c[]=x&c[]=2", `{"c":["x",2]}`},
+		{"nested objects", "d[e][f]=true&d[e][g]=1", `{"d":{"e":{"f":true,"g":1}}}`},
+		{"array of objects", "u[0][name]=a&u[0][age]=3&u[1][name]=b", `{"u":[{"name":"a","age":3},{"name":"b"}]}`},
+		{"repeated key", "a=1&a=2&a=3", `{"a":[1,2,3]}`},
+		{"strings which look like numbers", "zip=007&t=2024-01-01&v=1.2.3", `{"zip":"007","t":"2024-01-01","v":"1.2.3"}`},
+		{"repaired values", "j=%7Ba%3A1%7D&s='q'&m=[1 2", `{"j":{"a":1},"s":"q","m":[1,2]}`},
+		{"keys without value", "flag&x=&&=5", `{"flag":"","x":"","":5}`},
+		{"invalid escape", "x=100%", `{"x":"100%"}`},
+		{"unbalanced brackets", "a[b=1&c]=2", `{"a[b":1,"c]":2}`},
+		{"numeric name", "0=z&1[]=y", `{"0":"z","1":["y"]}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := RepairQueryString(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+
+	_, err := RepairQueryString("a=1", WithDelimiters(":"))
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -0,0 +1,323 @@
+package jsonrepair
+
+import (
+	"strings"
+	"time"
+)
+
+// Report contains details about a repair, filled when using WithReport.
+type Report struct {
+	// Preamble is the text skipped before the JSON document, like a shebang line or front-matter.
+	Preamble string
+
+	// ByteOrderMark is the encoding of the byte order mark removed from the start of the input,
+	// like "utf-8", or empty when there was none. Positions are in the text after it then.
+	ByteOrderMark string
+
+	// Charset is the charset the input was transcoded from, like "utf-16le" or "windows-1252",
+	// or empty when it was UTF-8.
+	Charset string
+
+	// InvalidUTF8 is the number of bytes of the input which were not valid UTF-8, replaced or
+	// removed with the InvalidUTF8Policy.
+	InvalidUTF8 int
+
+	// Base64Decoded tells whether the input was base64 encoded and decoded, see WithBase64Decoding.
+	// Positions are in the decoded text then.
+	Base64Decoded bool
+
+	// URLDecoded tells whether the input was percent-encoded and decoded, see WithURLDecoding.
+	// Positions are in the decoded text then.
+	URLDecoded bool
+
+	// Repairs lists the repairs which were made, in the order they were made.
+	Repairs []Repair
+
+	// Changed tells whether the output differs from the input, so the input was not valid JSON
+	// already, or was reformatted. It is also true for changes which are not listed in Repairs,
+	// like a removed byte order mark.
+	Changed bool
+
+	// Comments is the number of comments which were removed.
+	Comments int
+
+	// Bytes is the size of the input in bytes.
+	Bytes int
+
+	// Duration is the time the repair took.
+	Duration time.Duration
+}
+
+// Counts returns the number of repairs per message, like "added missing comma", for example to
+// monitor the health of the producer of the input over time.
+func (r *Report) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, repair := range r.Repairs {
+		counts[repair.Message]++
+	}
+	return counts
+}
+
+// Repair describes a single repair made to the input.
+type Repair struct {
+	// Position is the position in the input where the repair was made.
+	Position int
+
+	// Kind is the kind of the repair, like RepairMissingComma.
+	Kind RepairKind
+
+	// Message describes the repair, like "added missing comma".
+	Message string
+}
+
+// RepairKind is the kind of a Repair, to switch on repairs without parsing their message.
+type RepairKind int
+
+const (
+	// RepairOther is a repair of none of the kinds below.
+	RepairOther RepairKind = iota
+
+	// RepairMissingQuote adds a missing quote, like around a key or at the end of a string.
+	RepairMissingQuote
+
+	// RepairNonStandardQuote replaces single, smart or other quotes with double quotes.
+	RepairNonStandardQuote
+
+	// RepairMissingComma adds a missing comma between items.
+	RepairMissingComma
+
+	// RepairTrailingComma removes a trailing comma.
+	RepairTrailingComma
+
+	// RepairMissingColon adds a missing colon between a key and its value.
+	RepairMissingColon
+
+	// RepairSeparator replaces a separator like => or ; with a colon or a comma.
+	RepairSeparator
+
+	// RepairMissingBracket adds a missing closing bracket or brace.
+	RepairMissingBracket
+
+	// RepairRedundantBracket removes a redundant closing bracket or brace.
+	RepairRedundantBracket
+
+	// RepairMissingValue adds a missing value, or removes a key without value.
+	RepairMissingValue
+
+	// RepairKeyword replaces a keyword like None or undefined with its JSON counterpart.
+	RepairKeyword
+
+	// RepairFunctionCall replaces or removes a function call, like a JSONP callback.
+	RepairFunctionCall
+
+	// RepairNumber repairs a number, like a truncated number or one with leading zeros.
+	RepairNumber
+
+	// RepairEllipsis removes or replaces an ellipsis like ... which truncated the data.
+	RepairEllipsis
+
+	// RepairEmptySlot removes or replaces an empty array slot like in [1,,2].
+	RepairEmptySlot
+
+	// RepairStrippedWrapper removes what wraps the JSON, like a markdown fence or a variable
+	// assignment.
+	RepairStrippedWrapper
+
+	// RepairConcatenation concatenates strings like "a" + "b".
+	RepairConcatenation
+
+	// RepairHTMLEntity decodes an HTML entity.
+	RepairHTMLEntity
+
+	// RepairLoneSurrogate replaces or removes an escaped surrogate without its other half.
+	RepairLoneSurrogate
+
+	// RepairNestedString repairs JSON embedded in a string, see WithNestedStringRepair.
+	RepairNestedString
+
+	// RepairToken writes a token like a URL or a date, see WithTokens.
+	RepairToken
+
+	// RepairConversion converts another format, like YAML or newline delimited JSON.
+	RepairConversion
+
+	// RepairStrippedComment removes a comment.
+	RepairStrippedComment
+
+	// RepairUnescapedControlChar escapes the control characters of a string, like a newline.
+	RepairUnescapedControlChar
+
+	// RepairUnescapedBackslash escapes the backslashes of a file path, like C:\Users.
+	RepairUnescapedBackslash
+
+	// RepairCustom is a repair of a custom rule, see WithCustomRules.
+	RepairCustom
+
+	// RepairSchemaCoercion coerces a value to the type its schema expects, see WithSchema.
+	RepairSchemaCoercion
+
+	// RepairSpecialWhitespace replaces whitespace which JSON does not have, like a non-breaking
+	// space, with a space.
+	RepairSpecialWhitespace
+
+	// RepairInvalidEscape removes the backslash of an escape which JSON does not have, like \x.
+	RepairInvalidEscape
+)
+
+// repairKindNames are the names of the repair kinds, returned by String.
+var repairKindNames = [...]string{
+	RepairOther:                "other",
+	RepairMissingQuote:         "missing quote",
+	RepairNonStandardQuote:     "non-standard quote",
+	RepairMissingComma:         "missing comma",
+	RepairTrailingComma:        "trailing comma",
+	RepairMissingColon:         "missing colon",
+	RepairSeparator:            "separator",
+	RepairMissingBracket:       "missing bracket",
+	RepairRedundantBracket:     "redundant bracket",
+	RepairMissingValue:         "missing value",
+	RepairKeyword:              "keyword",
+	RepairFunctionCall:         "function call",
+	RepairNumber:               "number",
+	RepairEllipsis:             "ellipsis",
+	RepairEmptySlot:            "empty slot",
+	RepairStrippedWrapper:      "stripped wrapper",
+	RepairConcatenation:        "concatenation",
+	RepairHTMLEntity:           "html entity",
+	RepairLoneSurrogate:        "lone surrogate",
+	RepairNestedString:         "nested string",
+	RepairToken:                "token",
+	RepairConversion:           "conversion",
+	RepairStrippedComment:      "stripped comment",
+	RepairUnescapedControlChar: "unescaped control character",
+	RepairUnescapedBackslash:   "unescaped backslash",
+	RepairCustom:               "custom",
+	RepairSchemaCoercion:       "schema coercion",
+	RepairSpecialWhitespace:    "special whitespace",
+	RepairInvalidEscape:        "invalid escape",
+}
+
+// String returns the name of the kind, like "missing comma".
+func (k RepairKind) String() string {
+	if k < 0 || int(k) >= len(repairKindNames) {
+		return repairKindNames[RepairOther]
+	}
+	return repairKindNames[k]
+}
+
+// repairKinds are the kinds of the repairs by message.
+var repairKinds = map[string]RepairKind{
+	"added missing quotes":                         RepairMissingQuote,
+	"added missing end quote":                      RepairMissingQuote,
+	"reunited partially quoted key":                RepairMissingQuote,
+	"replaced quotes with double quotes":           RepairNonStandardQuote,
+	"replaced symbol with string":                  RepairNonStandardQuote,
+	"added missing comma":                          RepairMissingComma,
+	"removed trailing comma":                       RepairTrailingComma,
+	"added missing colon":                          RepairMissingColon,
+	"replaced arrow with colon":                    RepairSeparator,
+	"added array index key":                        RepairSeparator,
+	"replaced equals sign with colon":              RepairSeparator,
+	"replaced colon equals with colon":             RepairSeparator,
+	"replaced semicolon with comma":                RepairSeparator,
+	"replaced delimiter with comma":                RepairSeparator,
+	"replaced php array with brackets":             RepairSeparator,
+	"added missing closing bracket":                RepairMissingBracket,
+	"added missing closing brace":                  RepairMissingBracket,
+	"removed redundant closing bracket":            RepairRedundantBracket,
+	"added missing value":                          RepairMissingValue,
+	"removed key without value":                    RepairMissingValue,
+	"replaced keyword":                             RepairKeyword,
+	"replaced undefined with null":                 RepairKeyword,
+	"replaced function with null":                  RepairFunctionCall,
+	"removed function call":                        RepairFunctionCall,
+	"completed truncated number":                   RepairNumber,
+	"truncated invalid number":                     RepairNumber,
+	"quoted number with leading zero":              RepairNumber,
+	"quoted big number":                            RepairNumber,
+	"removed ellipsis":                             RepairEllipsis,
+	"replaced ellipsis with placeholder":           RepairEllipsis,
+	"replaced empty array slot with null":          RepairEmptySlot,
+	"removed empty array slot":                     RepairEmptySlot,
+	"removed tag wrapper":                          RepairStrippedWrapper,
+	"removed markdown fence":                       RepairStrippedWrapper,
+	"removed markdown heading":                     RepairStrippedWrapper,
+	"removed markdown bullet":                      RepairStrippedWrapper,
+	"removed markdown bold marker":                 RepairStrippedWrapper,
+	"removed return keyword":                       RepairStrippedWrapper,
+	"removed variable assignment":                  RepairStrippedWrapper,
+	"removed semicolon":                            RepairStrippedWrapper,
+	"removed parentheses":                          RepairStrippedWrapper,
+	"removed shebang line":                         RepairStrippedWrapper,
+	"removed byte order mark":                      RepairStrippedWrapper,
+	"concatenated strings":                         RepairConcatenation,
+	"decoded html entity":                          RepairHTMLEntity,
+	"replaced lone surrogate":                      RepairLoneSurrogate,
+	"removed lone surrogate":                       RepairLoneSurrogate,
+	"repaired nested json string":                  RepairNestedString,
+	"converted yaml block":                         RepairConversion,
+	"wrapped newline delimited values in an array": RepairConversion,
+	"removed comment":                              RepairStrippedComment,
+	"escaped control character":                    RepairUnescapedControlChar,
+	"escaped backslash":                            RepairUnescapedBackslash,
+	"replaced special whitespace":                  RepairSpecialWhitespace,
+	"removed invalid escape":                       RepairInvalidEscape,
+	"applied custom rule":                          RepairCustom,
+	"coerced value to schema type":                 RepairSchemaCoercion,
+	"added schema default":                         RepairMissingValue,
+}
+
+// repairKind returns the kind of the repair with the given message. The message of a token is
+// like "replaced url token".
+func repairKind(message string) RepairKind {
+	if kind, ok := repairKinds[message]; ok {
+		return kind
+	}
+	if strings.HasPrefix(message, "replaced ") && strings.HasSuffix(message, " token") {
+		return RepairToken
+	}
+	return RepairOther
+}
+
+// startReport resets the report and the warnings, if any, for the repair of the text, and
+// returns a function which records the duration of the repair and locates the warnings in the
+// text, to be deferred.
+func startReport(text string, opts *options) func() {
+	if opts.warnings != nil {
+		*opts.warnings = nil
+	}
+	if opts.report == nil && opts.warnings == nil {
+		return func() {}
+	}
+	if opts.report != nil {
+		*opts.report = Report{Bytes: len(text)}
+	}
+	start := time.Now()
+	return func() {
+		if opts.report != nil {
+			opts.report.Duration = time.Since(start)
+		}
+		if opts.warnings != nil {
+			locateIssues(*opts.warnings, text)
+		}
+	}
+}
+
+// logRepair records a repair at the given position in the report. When annotating,
+// a comment describing the repair is added to the output, before trailing whitespace.
+// A repair of a disabled rule fails the repair instead.
+// Messages must not contain commas or double quotes, since the output is searched for those.
+func logRepair(position int, output *strings.Builder, message string, opts *options) {
+	if !applyRule(position, message, opts) {
+		return
+	}
+	warnLossyRepair(position, message, opts)
+	if opts.report != nil {
+		opts.report.Repairs = append(opts.report.Repairs, Repair{Position: opts.offset + position, Kind: repairKind(message), Message: message})
+	}
+	if opts.annotate {
+		outputStr := insertBeforeLastWhitespace(output.String(), " /* jsonrepair: "+message+" */")
+		output.Reset()
+		output.WriteString(outputStr)
+	}
+}
@@ -0,0 +1,90 @@
+// Package rule names the repair rules of jsonrepair, the heuristics which repair a kind of
+// damage, like the comments removed by StripComments. Rules are enabled by default, and can be
+// disabled with jsonrepair.WithoutRules when they misfire on some input.
+package rule
+
+// Rule is the name of a repair rule.
+type Rule string
+
+const (
+	// StripComments removes comments like // c, /* c */, <!-- c --> and # c.
+	StripComments Rule = "strip-comments"
+
+	// CloseBrackets adds the missing closing brackets and braces of a truncated text, like [1, 2.
+	CloseBrackets Rule = "close-brackets"
+
+	// TrailingCommas removes a comma after the last item or member, like [1, 2,].
+	TrailingCommas Rule = "trailing-commas"
+
+	// MissingCommas adds a missing comma between items or members, like [1 2].
+	MissingCommas Rule = "missing-commas"
+
+	// MissingColons adds a missing colon between a key and its value, like {"a" 1}.
+	MissingColons Rule = "missing-colons"
+
+	// MissingValues adds null for a missing value, like {"a":}, or a key without value, like {a, b}.
+	MissingValues Rule = "missing-values"
+
+	// MissingQuotes quotes unquoted keys and values, like {a: hello}.
+	MissingQuotes Rule = "missing-quotes"
+
+	// MissingEndQuotes ends a string without end quote at the end of the text, or at the next
+	// delimiter, which splits ["a, b"] into ["a", "b"].
+	MissingEndQuotes Rule = "missing-end-quotes"
+
+	// NormalizeQuotes replaces single quotes and typographic quotes with double quotes, like 'a'.
+	NormalizeQuotes Rule = "normalize-quotes"
+
+	// Keywords replaces the keywords of other languages, like True, None and undefined.
+	Keywords Rule = "keywords"
+
+	// FunctionCalls repairs function calls, like ObjectId("...") or a JSONP callback(...).
+	FunctionCalls Rule = "function-calls"
+
+	// Numbers repairs numbers, like the truncated 2. or the leading zero of 007.
+	Numbers Rule = "numbers"
+
+	// Separators replaces the separators of other languages, like => and = in {a => 1, b = 2}
+	// or the semicolon of {a: 1; b: 2}, with colons and commas.
+	Separators Rule = "separators"
+
+	// RedundantBrackets removes closing brackets and braces without opening one, like {"a": 1}}.
+	RedundantBrackets Rule = "redundant-brackets"
+
+	// EmptySlots repairs the empty slots of a sparse array, like [1,,2].
+	EmptySlots Rule = "empty-slots"
+
+	// Ellipsis repairs an ellipsis which marks truncated data, like [1, 2, ...].
+	Ellipsis Rule = "ellipsis"
+
+	// StripWrappers removes the text around the value, like a Markdown fence, a tag like
+	// <json>, a return keyword, a variable assignment, parentheses or a byte order mark.
+	StripWrappers Rule = "strip-wrappers"
+
+	// ConcatenateStrings joins concatenated strings, like "a" + "b".
+	ConcatenateStrings Rule = "concatenate-strings"
+
+	// NewlineDelimited wraps newline delimited values, like NDJSON, in an array.
+	NewlineDelimited Rule = "newline-delimited"
+
+	// ControlCharacters escapes the control characters in strings, like a tab or a newline.
+	ControlCharacters Rule = "control-characters"
+
+	// FilePathEscaping escapes the backslashes of a Windows file path, like "C:\Users\docs",
+	// where a backslash is followed by a character which can not be escaped. Without it, such a
+	// backslash is removed.
+	FilePathEscaping Rule = "file-path-escaping"
+
+	// InvalidEscapes removes the backslash of an escape which JSON does not have, like "\x".
+	InvalidEscapes Rule = "invalid-escapes"
+)
+
+// All returns all the rules.
+func All() []Rule {
+	return []Rule{
+		StripComments, CloseBrackets, TrailingCommas, MissingCommas, MissingColons, MissingValues,
+		MissingQuotes, MissingEndQuotes, NormalizeQuotes, Keywords, FunctionCalls, Numbers,
+		Separators, RedundantBrackets, EmptySlots, Ellipsis, StripWrappers, ConcatenateStrings,
+		NewlineDelimited, ControlCharacters, FilePathEscaping, InvalidEscapes,
+	}
+}
@@ -0,0 +1,124 @@
+package jsonrepair
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kaptinlin/jsonrepair/rule"
+)
+
+// WithRules enables the given repair rules, which were disabled with WithoutRules. All rules
+// are enabled by default.
+func WithRules(rules ...rule.Rule) Option {
+	return func(o *options) {
+		o.setRules(rules, true)
+	}
+}
+
+// WithoutRules disables the given repair rules, as an escape hatch for a heuristic which
+// misfires on some input. A text which needs a repair of a disabled rule fails with
+// ErrRuleDisabled, which names the rule, except for rule.FilePathEscaping: without it, the
+// backslash of a file path is removed like any other backslash which can not escape the next
+// character, with rule.InvalidEscapes.
+func WithoutRules(rules ...rule.Rule) Option {
+	return func(o *options) {
+		o.setRules(rules, false)
+	}
+}
+
+// setRules enables or disables the rules.
+func (o *options) setRules(rules []rule.Rule, enabled bool) {
+	if o.rules == nil {
+		o.rules = make(map[rule.Rule]bool)
+	}
+	for _, r := range rules {
+		o.rules[r] = enabled
+	}
+}
+
+// enabled checks if the rule is enabled.
+func (o *options) enabled(r rule.Rule) bool {
+	enabled, ok := o.rules[r]
+	return !ok || enabled
+}
+
+// repairRules are the rules of the repairs, by message. The repairs which are enabled with an
+// option, like decoded HTML entities, belong to no rule.
+var repairRules = map[string]rule.Rule{
+	"removed comment":                              rule.StripComments,
+	"added missing closing bracket":                rule.CloseBrackets,
+	"added missing closing brace":                  rule.CloseBrackets,
+	"removed trailing comma":                       rule.TrailingCommas,
+	"added missing comma":                          rule.MissingCommas,
+	"added missing colon":                          rule.MissingColons,
+	"added missing value":                          rule.MissingValues,
+	"removed key without value":                    rule.MissingValues,
+	"added missing quotes":                         rule.MissingQuotes,
+	"reunited partially quoted key":                rule.MissingQuotes,
+	"added missing end quote":                      rule.MissingEndQuotes,
+	"replaced quotes with double quotes":           rule.NormalizeQuotes,
+	"replaced symbol with string":                  rule.NormalizeQuotes,
+	"replaced keyword":                             rule.Keywords,
+	"replaced undefined with null":                 rule.Keywords,
+	"replaced function with null":                  rule.FunctionCalls,
+	"removed function call":                        rule.FunctionCalls,
+	"completed truncated number":                   rule.Numbers,
+	"truncated invalid number":                     rule.Numbers,
+	"quoted number with leading zero":              rule.Numbers,
+	"quoted big number":                            rule.Numbers,
+	"replaced arrow with colon":                    rule.Separators,
+	"added array index key":                        rule.Separators,
+	"replaced equals sign with colon":              rule.Separators,
+	"replaced colon equals with colon":             rule.Separators,
+	"replaced semicolon with comma":                rule.Separators,
+	"replaced delimiter with comma":                rule.Separators,
+	"replaced php array with brackets":             rule.Separators,
+	"removed redundant closing bracket":            rule.RedundantBrackets,
+	"replaced empty array slot with null":          rule.EmptySlots,
+	"removed empty array slot":                     rule.EmptySlots,
+	"removed ellipsis":                             rule.Ellipsis,
+	"replaced ellipsis with placeholder":           rule.Ellipsis,
+	"removed tag wrapper":                          rule.StripWrappers,
+	"removed markdown fence":                       rule.StripWrappers,
+	"removed markdown heading":                     rule.StripWrappers,
+	"removed markdown bullet":                      rule.StripWrappers,
+	"removed markdown bold marker":                 rule.StripWrappers,
+	"removed return keyword":                       rule.StripWrappers,
+	"removed variable assignment":                  rule.StripWrappers,
+	"removed semicolon":                            rule.StripWrappers,
+	"removed parentheses":                          rule.StripWrappers,
+	"removed shebang line":                         rule.StripWrappers,
+	"removed byte order mark":                      rule.StripWrappers,
+	"concatenated strings":                         rule.ConcatenateStrings,
+	"wrapped newline delimited values in an array": rule.NewlineDelimited,
+	"escaped control character":                    rule.ControlCharacters,
+	"replaced special whitespace":                  rule.ControlCharacters,
+	"escaped backslash":                            rule.FilePathEscaping,
+	"removed invalid escape":                       rule.InvalidEscapes,
+}
+
+// applyRule fails the repair with ErrRuleDisabled when the repair with the given message, at the
+// given position, belongs to a disabled rule.
+func applyRule(position int, message string, opts *options) bool {
+	if r, ok := repairRules[message]; ok && !opts.enabled(r) {
+		opts.fail(newError(ErrRuleDisabled, string(r), opts.offset+position))
+		return false
+	}
+	return true
+}
+
+// regexFilePath matches the start of a string, including its opening quote, which is a Windows
+// file path like C: or \\server.
+var regexFilePath = regexp.MustCompile(`^"([A-Za-z]:|\\\\)`)
+
+// escapesFilePath escapes the backslash at the cursor, which can not escape the next character,
+// when the string is a Windows file path and rule.FilePathEscaping is enabled.
+func escapesFilePath(c *cursor, str, output *strings.Builder, opts *options) bool {
+	if !opts.enabled(rule.FilePathEscaping) || !regexFilePath.MatchString(str.String()) {
+		return false
+	}
+	str.WriteString(`\\`)
+	logRepair(c.pos, output, "escaped backslash", opts)
+	c.next()
+	return true
+}
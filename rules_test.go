@@ -0,0 +1,76 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kaptinlin/jsonrepair/rule"
+)
+
+func TestWithoutRules(t *testing.T) {
+	tests := []struct {
+		rule     rule.Rule
+		text     string
+		expected string
+	}{
+		{rule.StripComments, "[1, 2 // two\n]", "repair rule disabled: strip-comments at position 6"},
+		{rule.CloseBrackets, `{"a": [1, 2`, "repair rule disabled: close-brackets at position 11"},
+		{rule.TrailingCommas, `{"a": 1,}`, "repair rule disabled: trailing-commas at position 8"},
+		{rule.MissingQuotes, `{a: 1}`, "repair rule disabled: missing-quotes at position 1"},
+		{rule.Keywords, `[True]`, "repair rule disabled: keywords at position 1"},
+		{rule.NormalizeQuotes, `"""abc"""`, "repair rule disabled: normalize-quotes at position 0"},
+		{rule.NormalizeQuotes, `{"a": :sym}`, "repair rule disabled: normalize-quotes at position 6"},
+		{rule.Separators, `array(1,2)`, "repair rule disabled: separators at position 0"},
+		{rule.ControlCharacters, "{\"a\":\u00a01}", "repair rule disabled: control-characters at position 5"},
+		{rule.StripWrappers, "#!/usr/bin/env node\n[1]", "repair rule disabled: strip-wrappers at position 0"},
+		{rule.StripWrappers, "\ufeff[1]", "repair rule disabled: strip-wrappers at position 0"},
+		{rule.InvalidEscapes, `{"a":"\x"}`, "repair rule disabled: invalid-escapes at position 6"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.rule), func(t *testing.T) {
+			_, err := JSONRepair(tt.text, WithoutRules(tt.rule))
+			require.ErrorIs(t, err, ErrRuleDisabled)
+			assert.EqualError(t, err, tt.expected)
+
+			// the other rules still apply, and the rule can be enabled again
+			_, err = JSONRepair(tt.text, WithoutRules(rule.FilePathEscaping))
+			require.NoError(t, err)
+			_, err = JSONRepair(tt.text, WithoutRules(tt.rule), WithRules(tt.rule))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFilePathEscaping(t *testing.T) {
+	assertRepair(t, `{"path": "C:\Users\docs\a.txt"}`, `{"path": "C:\\Users\\docs\\a.txt"}`)
+	assertRepair(t, `{"path": 'C:\users'}`, `{"path": "C:\\users"}`)
+	assertRepair(t, `["\\server\share"]`, `["\\server\\share"]`)
+
+	// only the backslashes which can not escape the next character are escaped
+	assertRepair(t, `{'path': "C:\new\Docs"}`, `{"path": "C:\new\\Docs"}`)
+
+	// a string which is no file path is unchanged
+	assertRepair(t, `"\a"`, `"a"`)
+
+	// without the rule, the backslashes are removed
+	assertRepair(t, `{"path": "C:\Users\docs"}`, `{"path": "C:Usersdocs"}`, WithoutRules(rule.FilePathEscaping))
+
+	var report Report
+	_, err := JSONRepair(`"C:\Users"`, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{{Position: 3, Kind: RepairUnescapedBackslash, Message: "escaped backslash"}}, report.Repairs)
+}
+
+func TestRulesCoverMessages(t *testing.T) {
+	covered := make(map[rule.Rule]bool)
+	for message, r := range repairRules {
+		assert.Contains(t, rule.All(), r, message)
+		assert.NotEqual(t, RepairOther, repairKind(message), message)
+		covered[r] = true
+	}
+	for _, r := range rule.All() {
+		assert.True(t, covered[r], "%s has no repair", r)
+	}
+}
@@ -0,0 +1,17 @@
+package jsonrepair
+
+import "fmt"
+
+// RepairSafe is like JSONRepair, but recovers from a panic during the repair and returns it as
+// an error wrapping ErrInternal instead. No input is expected to make the repair panic: the
+// parser reads the text through a bounds-checked cursor, and FuzzJSONRepairPanics looks for
+// inputs which do. RepairSafe is a backstop for services which repair untrusted input and can
+// not afford to crash on a bug, including one in a Token given with WithTokens.
+func RepairSafe(text string, opts ...Option) (repaired string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			repaired, err = "", fmt.Errorf("%w: %v", ErrInternal, r)
+		}
+	}()
+	return JSONRepair(text, opts...)
+}
@@ -0,0 +1,30 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRepairSafe tests repairing like JSONRepair.
+func TestRepairSafe(t *testing.T) {
+	result, err := RepairSafe(`{a: 1,}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, result)
+
+	_, err = RepairSafe(`{"a": 1} x`)
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}
+
+// TestRepairSafeRecovers tests returning a panic during the repair as ErrInternal.
+func TestRepairSafeRecovers(t *testing.T) {
+	token := Token{
+		Name:      "broken",
+		Match:     func(text []rune) int { return len(text) },
+		Transform: func(token string) string { panic("broken transform") },
+	}
+	_, err := RepairSafe(`[abc]`, WithTokens(token))
+	require.ErrorIs(t, err, ErrInternal)
+	assert.EqualError(t, err, "internal error: broken transform")
+}
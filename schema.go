@@ -0,0 +1,269 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// WithSchema guides the repair with a JSON Schema, of which type, properties,
+// additionalProperties, items, required and default are used. A value of another type than the
+// schema expects is coerced when it can be, like "42" to 42 where an integer is expected, or 42
+// to "42" where a string is expected.
+// A missing value is replaced with the default of its schema, or with "" where a string is
+// expected, instead of null. The required members with a default which are missing from a
+// truncated object are added. A schema which is not valid fails with ErrInvalidOption.
+func WithSchema(schema string) Option {
+	return func(o *options) {
+		o.schema, o.schemaErr = parseSchema([]byte(schema))
+		if o.schemaErr != nil {
+			o.schemaErr = fmt.Errorf("WithSchema: %w", o.schemaErr)
+		}
+	}
+}
+
+// WithTypes guides the repair like WithSchema with a map of the types of the members of the
+// root object, like {"age": "integer", "tags": []any{"string"}, "address": map[string]any{
+// "zip": "string"}}. A type is a JSON Schema type name, a []any with the type of the items of
+// an array, or a map[string]any with the types of the members of an object.
+func WithTypes(types map[string]any) Option {
+	return func(o *options) {
+		o.schema, o.schemaErr = typeMapSchema(types)
+		if o.schemaErr != nil {
+			o.schemaErr = fmt.Errorf("WithTypes: %w", o.schemaErr)
+		}
+	}
+}
+
+// schemaNode is the part of a JSON Schema which guides the repair of a value.
+type schemaNode struct {
+	types      []string
+	properties map[string]*schemaNode
+	additional *schemaNode
+	items      *schemaNode
+	required   []string
+	defaultVal json.RawMessage
+	// foldCase matches the keys to the properties without regard to case, like encoding/json
+	// matches the keys to the fields of a struct.
+	foldCase bool
+}
+
+// schemaTypes are the type names of JSON Schema.
+var schemaTypes = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+
+// parseSchema parses a JSON Schema.
+func parseSchema(data []byte) (*schemaNode, error) {
+	var raw struct {
+		Type       json.RawMessage            `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Additional json.RawMessage            `json:"additionalProperties"`
+		Items      json.RawMessage            `json:"items"`
+		Required   []string                   `json:"required"`
+		Default    json.RawMessage            `json:"default"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	node := &schemaNode{required: raw.Required, defaultVal: raw.Default}
+	if len(raw.Type) > 0 {
+		var name string
+		if json.Unmarshal(raw.Type, &name) == nil {
+			node.types = []string{name}
+		} else if err := json.Unmarshal(raw.Type, &node.types); err != nil {
+			return nil, fmt.Errorf("type %s is neither a string nor an array of strings", raw.Type)
+		}
+		for _, name := range node.types {
+			if !slices.Contains(schemaTypes, name) {
+				return nil, fmt.Errorf("unknown type %q", name)
+			}
+		}
+	}
+	for name, property := range raw.Properties {
+		child, err := parseSchema(property)
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", name, err)
+		}
+		if node.properties == nil {
+			node.properties = make(map[string]*schemaNode)
+		}
+		node.properties[name] = child
+	}
+	if strings.HasPrefix(string(raw.Additional), "{") {
+		additional, err := parseSchema(raw.Additional)
+		if err != nil {
+			return nil, fmt.Errorf("additionalProperties: %w", err)
+		}
+		node.additional = additional
+	}
+	if len(raw.Items) > 0 {
+		items, err := parseSchema(raw.Items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		node.items = items
+	}
+	return node, nil
+}
+
+// typeMapSchema turns a map of types, see WithTypes, into the schema of an object.
+func typeMapSchema(types map[string]any) (*schemaNode, error) {
+	node := &schemaNode{types: []string{"object"}, properties: make(map[string]*schemaNode)}
+	for name, t := range types {
+		child, err := typeSchema(t)
+		if err != nil {
+			return nil, fmt.Errorf("member %q: %w", name, err)
+		}
+		node.properties[name] = child
+	}
+	return node, nil
+}
+
+// typeSchema turns a type of a map of types into a schema.
+func typeSchema(t any) (*schemaNode, error) {
+	switch t := t.(type) {
+	case string:
+		if !slices.Contains(schemaTypes, t) {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+		return &schemaNode{types: []string{t}}, nil
+	case []any:
+		if len(t) != 1 {
+			return nil, errors.New("an array type must have a single item type")
+		}
+		items, err := typeSchema(t[0])
+		if err != nil {
+			return nil, err
+		}
+		return &schemaNode{types: []string{"array"}, items: items}, nil
+	case map[string]any:
+		return typeMapSchema(t)
+	}
+	return nil, fmt.Errorf("unknown type %v", t)
+}
+
+// property returns the schema of the member with the given key, written as a JSON string.
+func (s *schemaNode) property(key string) *schemaNode {
+	if s == nil || s.properties == nil && s.additional == nil {
+		return nil
+	}
+	var name string
+	if json.Unmarshal([]byte(key), &name) != nil {
+		return nil
+	}
+	if property, ok := s.properties[name]; ok {
+		return property
+	}
+	if s.foldCase {
+		for propertyName, property := range s.properties {
+			if strings.EqualFold(propertyName, name) {
+				return property
+			}
+		}
+	}
+	return s.additional
+}
+
+// itemSchema returns the schema of the items of an array.
+func (s *schemaNode) itemSchema() *schemaNode {
+	if s == nil {
+		return nil
+	}
+	return s.items
+}
+
+// expects checks if the schema expects a value of the type.
+func (s *schemaNode) expects(t string) bool {
+	return slices.Contains(s.types, t)
+}
+
+// missingValue returns the value which replaces a missing value: the default of the schema, ""
+// for a string, or null.
+func (s *schemaNode) missingValue() string {
+	switch {
+	case s == nil:
+		return "null"
+	case len(s.defaultVal) > 0:
+		return string(s.defaultVal)
+	case s.expects("string") && !s.expects("null"):
+		return `""`
+	}
+	return "null"
+}
+
+// coerceValue replaces the value written to the output at valueStart with a value of the type
+// which its schema expects, when the value is of another type and can be coerced, like "42" to
+// 42. The position is where the value starts in the text.
+func coerceValue(output *strings.Builder, valueStart, position int, opts *options) {
+	s := opts.schema
+	if s == nil || len(s.types) == 0 {
+		return
+	}
+	outputStr := output.String()
+	decoder := json.NewDecoder(strings.NewReader(outputStr[valueStart:]))
+	decoder.UseNumber()
+	var decoded any
+	if decoder.Decode(&decoded) != nil {
+		return
+	}
+	end := valueStart + int(decoder.InputOffset())
+
+	var coerced string
+	switch value := decoded.(type) {
+	case string:
+		switch {
+		case s.expects("string"):
+		case s.expects("integer") && regexJSONNumber.MatchString(value) && !strings.ContainsAny(value, ".eE"),
+			s.expects("number") && regexJSONNumber.MatchString(value),
+			s.expects("boolean") && (value == "true" || value == "false"):
+			coerced = value
+		}
+	case json.Number:
+		integer := !strings.ContainsAny(string(value), ".eE")
+		if !s.expects("number") && !(integer && s.expects("integer")) && s.expects("string") {
+			coerced = quote(string(value))
+		}
+	case bool:
+		if !s.expects("boolean") && s.expects("string") {
+			coerced = quote(outputStr[valueStart:end])
+		}
+	}
+	if coerced == "" {
+		return
+	}
+
+	output.Reset()
+	output.WriteString(outputStr[:valueStart])
+	output.WriteString(coerced)
+	output.WriteString(outputStr[end:])
+	logRepair(position, output, "coerced value to schema type", opts)
+}
+
+// quote returns the value as a JSON string.
+func quote(value string) string {
+	var quoted strings.Builder
+	writeQuoted(&quoted, value)
+	return quoted.String()
+}
+
+// schemaDefaults returns the members to add to a truncated object of the schema: the required
+// members with a default which are not among the keys, like , "a": 1.
+func schemaDefaults(s *schemaNode, keys map[string]bool, hasMembers bool) string {
+	if s == nil {
+		return ""
+	}
+	var members strings.Builder
+	for _, name := range s.required {
+		property := s.properties[name]
+		if keys[quote(name)] || property == nil || len(property.defaultVal) == 0 {
+			continue
+		}
+		if hasMembers || members.Len() > 0 {
+			members.WriteString(", ")
+		}
+		members.WriteString(quote(name) + ": ")
+		members.Write(property.defaultVal)
+	}
+	return members.String()
+}
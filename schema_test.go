@@ -0,0 +1,78 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer"},
+		"price": {"type": "number"},
+		"active": {"type": "boolean"},
+		"name": {"type": "string"},
+		"note": {"type": ["string", "null"]},
+		"tags": {"type": "array", "items": {"type": "string"}},
+		"status": {"type": "string", "default": "new"},
+		"owner": {"type": "object", "properties": {"zip": {"type": "string"}}}
+	},
+	"required": ["id", "status"]
+}`
+
+func TestSchema(t *testing.T) {
+	schema := WithSchema(testSchema)
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"coerces strings", `{"id": "42", "price": '9.95', "active": "true"}`, `{"id": 42, "price": 9.95, "active": true}`},
+		{"coerces numbers", `{"name": 42, "tags": [1, true, "x"], "owner": {"zip": 01234}}`, `{"name": "42", "tags": ["1", "true", "x"], "owner": {"zip": "01234"}}`},
+		{"keeps other values", `{"id": "x", "price": 1, "active": "yes", "other": "42"}`, `{"id": "x", "price": 1, "active": "yes", "other": "42"}`},
+		{"valid json", `{"id": "42"}`, `{"id": 42}`},
+		{"missing values", `{"name": , "note": , "status": , "id"}`, `{"name": "", "note": null, "status": "new", "id": null}`},
+		{"truncated object", `{"id": 1, "name": "a`, `{"id": 1, "name": "a", "status": "new"}`},
+		{"complete object", `{"id": 1}`, `{"id": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertRepair(t, tt.text, tt.expected, schema)
+		})
+	}
+
+	var report Report
+	_, err := JSONRepair(`{"id": "42", "name": "a`, schema, WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, []Repair{
+		{Position: 7, Kind: RepairSchemaCoercion, Message: "coerced value to schema type"},
+		{Position: 23, Kind: RepairMissingQuote, Message: "added missing end quote"},
+		{Position: 23, Kind: RepairMissingValue, Message: "added schema default"},
+		{Position: 23, Kind: RepairMissingBracket, Message: "added missing closing brace"},
+	}, report.Repairs)
+}
+
+func TestTypes(t *testing.T) {
+	types := WithTypes(map[string]any{
+		"age":     "integer",
+		"tags":    []any{"string"},
+		"address": map[string]any{"zip": "string"},
+	})
+	assertRepair(t, `{age: "42", tags: [1, 2], address: {zip: 1234}, name: 5}`,
+		`{"age": 42, "tags": ["1", "2"], "address": {"zip": "1234"}, "name": 5}`, types)
+}
+
+func TestSchemaInvalid(t *testing.T) {
+	for schema, expected := range map[string]string{
+		`{"type": "text"}`:                   `invalid option: WithSchema: unknown type "text"`,
+		`{"properties": {"a": {"type": 1}}}`: `invalid option: WithSchema: property "a": type 1 is neither a string nor an array of strings`,
+		`[`:                                  `invalid option: WithSchema: unexpected end of JSON input`,
+	} {
+		_, err := JSONRepair(`{}`, WithSchema(schema))
+		assert.EqualError(t, err, expected, schema)
+	}
+	_, err := JSONRepair(`{}`, WithTypes(map[string]any{"a": []any{"string", "number"}}))
+	assert.EqualError(t, err, `invalid option: WithTypes: member "a": an array type must have a single item type`)
+}
@@ -0,0 +1,131 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	updateSnapshot  = flag.Bool("update-snapshot", false, "record the current output for the corpus as the new snapshot, and clear the changes")
+	snapshotVersion = flag.String("snapshot-version", "unreleased", "version recorded in the snapshot when updating it")
+	recordChanges   = flag.Bool("record-changes", false, "record the current output which differs from the snapshot as changes")
+)
+
+const (
+	corpusFile   = "testdata/corpus.json"
+	snapshotFile = "testdata/snapshot.json"
+	changesFile  = "testdata/changes.json"
+)
+
+// snapshot holds the output of a released version for every input of the corpus.
+type snapshot struct {
+	Version string           `json:"version"`
+	Results []snapshotResult `json:"results"`
+}
+
+// snapshotResult is the outcome of repairing a single input of the corpus.
+type snapshotResult struct {
+	Input  string `json:"input"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// snapshotChange is an intended change in behavior since the snapshot, with the new outcome
+// for an input and what changed.
+type snapshotChange struct {
+	snapshotResult
+	Change string `json:"change"`
+}
+
+// TestSnapshot compares the output for the corpus with the snapshot recorded for the previous
+// version and the changes made since, so that any change in behavior shows up as a test failure.
+// When a change is intended, record it with: go test -run TestSnapshot -record-changes, and
+// describe it in testdata/changes.json. At a release, the snapshot is recorded again.
+func TestSnapshot(t *testing.T) {
+	var corpus []string
+	readJSONFile(t, corpusFile, &corpus)
+
+	current := snapshot{Version: *snapshotVersion}
+	for _, input := range corpus {
+		result := snapshotResult{Input: input}
+		output, err := JSONRepair(input)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Output = output
+		}
+		current.Results = append(current.Results, result)
+	}
+
+	if *updateSnapshot {
+		writeJSONFile(t, snapshotFile, current)
+		writeJSONFile(t, changesFile, []snapshotChange{})
+		return
+	}
+
+	var previous snapshot
+	readJSONFile(t, snapshotFile, &previous)
+	before := make(map[string]snapshotResult, len(previous.Results))
+	for _, result := range previous.Results {
+		before[result.Input] = result
+	}
+
+	var changes []snapshotChange
+	readJSONFile(t, changesFile, &changes)
+	changed := make(map[string]snapshotChange, len(changes))
+	for _, change := range changes {
+		changed[change.Input] = change
+	}
+
+	if *recordChanges {
+		recorded := []snapshotChange{}
+		for _, result := range current.Results {
+			if expected, ok := before[result.Input]; !ok || result != expected {
+				recorded = append(recorded, snapshotChange{snapshotResult: result, Change: changed[result.Input].Change})
+			}
+		}
+		writeJSONFile(t, changesFile, recorded)
+		return
+	}
+
+	inCorpus := make(map[string]bool, len(corpus))
+	for _, result := range current.Results {
+		inCorpus[result.Input] = true
+		expected, recorded := before[result.Input]
+		if change, ok := changed[result.Input]; ok {
+			assert.NotEmpty(t, change.Change, "no description of the change for input %q", result.Input)
+			if recorded {
+				assert.NotEqual(t, expected, change.snapshotResult, "change without a difference to %s for input %q", previous.Version, result.Input)
+			}
+			expected = change.snapshotResult
+		} else if !recorded {
+			t.Errorf("no snapshot for input %q, record it as a change with -record-changes", result.Input)
+			continue
+		}
+		assert.Equal(t, expected, result, "behavior changed since %s for input %q", previous.Version, result.Input)
+	}
+	for _, change := range changes {
+		assert.True(t, inCorpus[change.Input], "change for input %q which is not in the corpus", change.Input)
+	}
+}
+
+// readJSONFile decodes a JSON file from the testdata directory.
+func readJSONFile(t *testing.T, name string, value any) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, value))
+}
+
+// writeJSONFile encodes a value to a JSON file in the testdata directory.
+func writeJSONFile(t *testing.T, name string, value any) {
+	t.Helper()
+	data, err := json.MarshalIndent(value, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(name, append(data, '\n'), 0o600))
+}
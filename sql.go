@@ -0,0 +1,237 @@
+package jsonrepair
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SQLValue is the result of repairing a JSON value of an INSERT statement with RepairSQLInserts.
+type SQLValue struct {
+	// Table is the name of the table the statement inserts into, without quotes.
+	Table string
+
+	// Statement is the number of the INSERT statement in the dump, starting at 1.
+	Statement int
+
+	// Row is the number of the row in the statement, starting at 1.
+	Row int
+
+	// Column is the name of the column from the column list of the statement, or its
+	// position starting at 1 when the statement has no column list.
+	Column string
+
+	// Output is the repaired value, empty when the value could not be repaired.
+	Output string
+
+	// Err is the error which stopped the repair of the value, or nil.
+	Err error
+}
+
+// RepairSQLInserts repairs the JSON values in the INSERT statements of a database dump, like
+// INSERT INTO users (id, data) VALUES (1, '{"a": 1}'), (2, '{b: 2}');. Every single quoted
+// string which starts with an opening brace or bracket is a JSON value: its doubled and
+// backslash escaped quotes are unescaped, and it is repaired independently. A value which
+// cannot be repaired does not affect the other values, its result holds the error instead.
+func RepairSQLInserts(text string, opts ...Option) []SQLValue {
+	var results []SQLValue
+	c := newCursor([]rune(text))
+	statement := 0
+	for !c.done() {
+		if !skipSQLKeyword(c, "insert") {
+			skipSQLToken(c)
+			continue
+		}
+		statement++
+		results = append(results, parseSQLInsert(c, statement, opts)...)
+	}
+	return results
+}
+
+// parseSQLInsert parses an INSERT statement after the INSERT keyword, and returns the
+// results of repairing its JSON values.
+func parseSQLInsert(c *cursor, statement int, opts []Option) []SQLValue {
+	skipSQLKeyword(c, "into")
+	skipWhitespace(c)
+	table := parseSQLName(c)
+	skipWhitespace(c)
+
+	var columns []string
+	if skipCharacter(c, codeOpenParenthesis) {
+		for {
+			skipWhitespace(c)
+			if c.done() || skipCharacter(c, codeCloseParenthesis) {
+				break
+			}
+			if name := parseSQLName(c); name != "" {
+				columns = append(columns, name)
+			} else {
+				c.next()
+			}
+			skipWhitespace(c)
+			skipCharacter(c, codeComma)
+		}
+	}
+	if !skipSQLKeyword(c, "values") {
+		return nil
+	}
+
+	var results []SQLValue
+	for row := 1; ; row++ {
+		skipWhitespace(c)
+		if !skipCharacter(c, codeOpenParenthesis) {
+			break
+		}
+		for column := 1; !c.done(); column++ {
+			skipWhitespace(c)
+			if value, ok := parseSQLString(c); ok {
+				if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+					output, err := JSONRepair(value, opts...)
+					results = append(results, SQLValue{
+						Table: table, Statement: statement, Row: row, Column: sqlColumnName(columns, column),
+						Output: output, Err: err,
+					})
+				}
+			}
+			skipSQLValue(c)
+			if !skipCharacter(c, codeComma) {
+				break
+			}
+		}
+		skipCharacter(c, codeCloseParenthesis)
+		skipWhitespace(c)
+		if !skipCharacter(c, codeComma) {
+			break
+		}
+	}
+	return results
+}
+
+// sqlColumnName returns the name of the column at the given position starting at 1, or the
+// position itself when there is no column list.
+func sqlColumnName(columns []string, column int) string {
+	if column <= len(columns) {
+		return columns[column-1]
+	}
+	return strconv.Itoa(column)
+}
+
+// parseSQLString parses a single quoted SQL string, and returns its unescaped content. A quote
+// is escaped by doubling it or, like in MySQL dumps, with a backslash. Other backslashes are
+// kept, so escaped JSON like {\"a\": 1} is left to the repair.
+func parseSQLString(c *cursor) (string, bool) {
+	if c.peek(0) != codeQuote {
+		return "", false
+	}
+	c.next()
+	var value strings.Builder
+	for !c.done() {
+		switch {
+		case c.peek(0) == codeQuote && c.peek(1) == codeQuote:
+			value.WriteRune(codeQuote)
+			c.skip(2)
+		case c.peek(0) == codeQuote:
+			c.next()
+			return value.String(), true
+		case c.peek(0) == codeBackslash && c.peek(1) == codeQuote:
+			value.WriteRune(codeQuote)
+			c.skip(2)
+		case c.peek(0) == codeBackslash:
+			// other escapes are kept, an escaped backslash may come right before the end quote
+			value.WriteRune(c.next())
+			value.WriteRune(c.next())
+		default:
+			value.WriteRune(c.next())
+		}
+	}
+	return value.String(), true
+}
+
+// skipSQLValue skips the rest of a value in a row, up to the comma or parenthesis ending it,
+// including nested parentheses of a function call like NOW().
+func skipSQLValue(c *cursor) {
+	depth := 0
+	for !c.done() {
+		switch c.peek(0) {
+		case codeQuote:
+			parseSQLString(c)
+			continue
+		case codeOpenParenthesis:
+			depth++
+		case codeCloseParenthesis:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case codeComma:
+			if depth == 0 {
+				return
+			}
+		}
+		c.next()
+	}
+}
+
+// parseSQLName parses a table or column name, which may be quoted with backticks, double quotes
+// or brackets and qualified like schema.table, and returns it without quotes.
+func parseSQLName(c *cursor) string {
+	var name strings.Builder
+	for {
+		switch c.peek(0) {
+		case codeGraveAccent, codeDoubleQuote, codeOpeningBracket:
+			closing := c.next()
+			if closing == codeOpeningBracket {
+				closing = codeClosingBracket
+			}
+			for !c.done() && c.peek(0) != closing {
+				name.WriteRune(c.next())
+			}
+			c.next()
+		default:
+			for isSymbolChar(c.peek(0)) || c.peek(0) == codeDollar {
+				name.WriteRune(c.next())
+			}
+		}
+		if c.peek(0) != codeDot {
+			return name.String()
+		}
+		name.WriteRune(c.next())
+	}
+}
+
+// skipSQLKeyword skips whitespace and the given keyword, matched case insensitive as a whole
+// word, and returns whether it was there. The cursor is not moved when it was not.
+func skipSQLKeyword(c *cursor, keyword string) bool {
+	j := *c
+	skipWhitespace(&j)
+	end := j.pos + len(keyword)
+	if !strings.EqualFold(string(j.slice(j.pos, end)), keyword) || isSymbolChar(j.at(end)) ||
+		j.pos > 0 && isSymbolChar(j.peek(-1)) {
+		return false
+	}
+	c.pos = end
+	return true
+}
+
+// skipSQLToken skips a token outside of an INSERT statement: a string, a comment, a word,
+// or a single other character.
+func skipSQLToken(c *cursor) {
+	switch {
+	case c.peek(0) == codeQuote:
+		parseSQLString(c)
+	case c.hasPrefix("--"):
+		for !c.done() && c.peek(0) != codeNewline {
+			c.next()
+		}
+	case c.hasPrefix("/*"):
+		for !c.done() && !c.hasPrefix("*/") {
+			c.next()
+		}
+		c.skip(2)
+	case isSymbolChar(c.peek(0)):
+		for isSymbolChar(c.peek(0)) {
+			c.next()
+		}
+	default:
+		c.next()
+	}
+}
@@ -0,0 +1,36 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairSQLInserts(t *testing.T) {
+	dump := "-- don't mind this comment\n" +
+		"CREATE TABLE `users` (id int, data json, note text);\n" +
+		"INSERT INTO `users` (`id`, `data`, `note`) VALUES (1, '{\"a\": 1}', 'x'), (2, '{b: ''x'', c: [1, 2}', NOW()), (3, '{\"e\": \"it\\'s\"}', '');\n" +
+		"/* second table */ insert into public.logs values (3, '[1, 2', 'plain', '{\\\"d\\\": 3}', NULL);\n"
+
+	results := RepairSQLInserts(dump)
+	assert.Equal(t, []SQLValue{
+		{Table: "users", Statement: 1, Row: 1, Column: "data", Output: `{"a": 1}`},
+		{Table: "users", Statement: 1, Row: 2, Column: "data", Output: `{"b": "x", "c": [1, 2]}`},
+		{Table: "users", Statement: 1, Row: 3, Column: "data", Output: `{"e": "it's"}`},
+		{Table: "public.logs", Statement: 2, Row: 1, Column: "2", Output: `[1, 2]`},
+		{Table: "public.logs", Statement: 2, Row: 1, Column: "4", Output: `{"d": 3}`},
+	}, results)
+}
+
+func TestRepairSQLInsertsFailure(t *testing.T) {
+	results := RepairSQLInserts(`INSERT INTO t VALUES ('{"a": 1}}x'), ('[2]');`)
+	require.Len(t, results, 2)
+	assert.Empty(t, results[0].Output)
+	require.ErrorIs(t, results[0].Err, ErrUnexpectedCharacter)
+	assert.Equal(t, SQLValue{Table: "t", Statement: 1, Row: 2, Column: "1", Output: `[2]`}, results[1])
+}
+
+func TestRepairSQLInsertsWithoutValues(t *testing.T) {
+	assert.Empty(t, RepairSQLInserts("SELECT '{\"a\": 1}' FROM t; INSERT INTO t SELECT * FROM u;"))
+}
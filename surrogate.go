@@ -0,0 +1,108 @@
+package jsonrepair
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SurrogatePolicy defines how an escaped UTF-16 surrogate without its other half, like \ud83d
+// at the end of a truncated string, is repaired. Decoders like those of JavaScript reject such
+// a lone surrogate, and encoding/json silently replaces it.
+type SurrogatePolicy int
+
+const (
+	// SurrogateReplace replaces a lone surrogate with the replacement character \ufffd.
+	SurrogateReplace SurrogatePolicy = iota
+
+	// SurrogateDrop removes a lone surrogate.
+	SurrogateDrop
+)
+
+// escapedCode returns the code of the Unicode escape sequence \uXXXX at the given offset from
+// the cursor, or -1 when there is none.
+func escapedCode(c *cursor, offset int) rune {
+	if c.peek(offset) != codeBackslash || c.peek(offset+1) != 'u' {
+		return -1
+	}
+	for k := offset + 2; k < offset+6; k++ {
+		if !isHex(c.peek(k)) {
+			return -1
+		}
+	}
+	code, _ := strconv.ParseUint(string(c.slice(c.pos+offset+2, c.pos+offset+6)), 16, 32)
+	return rune(code)
+}
+
+// isHighSurrogate and isLowSurrogate check if a code is the first or the second half of a
+// UTF-16 surrogate pair.
+func isHighSurrogate(code rune) bool {
+	return code >= 0xD800 && code < 0xDC00
+}
+
+func isLowSurrogate(code rune) bool {
+	return code >= 0xDC00 && code < 0xE000
+}
+
+// parseEscapedSurrogate parses the Unicode escape sequence of a surrogate like \ud83d at the
+// cursor, which must be there. A high surrogate followed by a low surrogate is kept as a pair,
+// a lone surrogate is repaired with the surrogate policy.
+func parseEscapedSurrogate(c *cursor, str, output *strings.Builder, opts *options) {
+	code := escapedCode(c, 0)
+	if isHighSurrogate(code) && isLowSurrogate(escapedCode(c, 6)) {
+		str.WriteString(string(c.slice(c.pos, c.pos+12)))
+		c.skip(12)
+		return
+	}
+	// a pair split across concatenated strings like "\ud83d" + "\ude00" is joined again by
+	// parseConcatenatedString, which removes the quotes and the plus in between
+	if isHighSurrogate(code) && atConcatenatedLowSurrogate(c) ||
+		isLowSurrogate(code) && str.Len() == 1 && endsWithHighSurrogate(output.String()) {
+		str.WriteString(string(c.slice(c.pos, c.pos+6)))
+		c.skip(6)
+		return
+	}
+
+	switch opts.surrogatePolicy {
+	case SurrogateDrop:
+		logRepair(c.pos, output, "removed lone surrogate", opts)
+	default:
+		str.WriteString(`\ufffd`)
+		logRepair(c.pos, output, "replaced lone surrogate", opts)
+	}
+	c.skip(6)
+}
+
+// atConcatenatedLowSurrogate checks if the escaped high surrogate at the cursor ends a string
+// which is concatenated with a string starting with an escaped low surrogate.
+func atConcatenatedLowSurrogate(c *cursor) bool {
+	j := *c
+	j.skip(6)
+	if !isQuote(j.peek(0)) {
+		return false
+	}
+	j.next()
+	skipWhitespace(&j)
+	if !skipCharacter(&j, '+') {
+		return false
+	}
+	skipWhitespace(&j)
+	if !isQuote(j.peek(0)) {
+		return false
+	}
+	j.next()
+	return isLowSurrogate(escapedCode(&j, 0))
+}
+
+// endsWithHighSurrogate checks if the output ends with an escaped high surrogate, like the
+// output of a string concatenated with the next one, without its end quote.
+func endsWithHighSurrogate(output string) bool {
+	if len(output) < 6 {
+		return false
+	}
+	c := newCursor([]rune(output[len(output)-6:]))
+	backslashes := 0
+	for k := len(output) - 7; k >= 0 && output[k] == codeBackslash; k-- {
+		backslashes++
+	}
+	return backslashes%2 == 0 && isHighSurrogate(escapedCode(c, 0))
+}
@@ -0,0 +1,153 @@
+package jsonrepair
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenHandling defines how a recognized token is written to the output.
+type TokenHandling int
+
+const (
+	// TokenString writes the token as a string: 12:30 becomes "12:30".
+	TokenString TokenHandling = iota
+
+	// TokenNumber writes the token as a number, like 1_000 transformed into 1000.
+	// A token which is not a valid JSON number after the transform is written as a string.
+	TokenNumber
+
+	// TokenRaw writes the token as it is. The token, or the result of the transform,
+	// must be valid JSON then.
+	TokenRaw
+)
+
+// Token describes the shape of a well-known unquoted value, like a URL or a date, which is
+// kept whole instead of being split at characters like colons, slashes and dashes.
+// Tokens are recognized in value position, where they must make up the whole value.
+type Token struct {
+	// Name identifies the token in repair messages, like "url" or "date". It must not
+	// contain commas or double quotes.
+	Name string
+
+	// Pattern matches the token at the start of the text, and should be anchored with ^.
+	Pattern *regexp.Regexp
+
+	// Match returns the length in runes of the token at the start of the text, or 0 when
+	// there is none. It is used when Pattern is nil.
+	Match func(text []rune) int
+
+	// Handling defines how the token is written. The default is TokenString.
+	Handling TokenHandling
+
+	// Transform optionally rewrites the token before it is written.
+	Transform func(token string) string
+}
+
+// maxTokenLength is the maximum length of the text matched against the pattern of a token.
+const maxTokenLength = 256
+
+// builtinTokens returns the built-in tokens, which are recognized after the tokens added
+// with WithTokens.
+func builtinTokens(o *options) []Token {
+	tokens := []Token{
+		{Name: "endpoint", Match: func(text []rune) int {
+			c := newCursor(text)
+			if !skipEndpoint(c) {
+				return 0
+			}
+			return c.pos
+		}},
+		{Name: "url", Match: func(text []rune) int {
+			c := newCursor(text)
+			if !skipURL(c, o) {
+				return 0
+			}
+			return c.pos
+		}},
+	}
+	return append(tokens, builtinPatternTokens...)
+}
+
+// builtinPatternTokens are the built-in tokens which are matched with a pattern.
+var builtinPatternTokens = []Token{
+	{Name: "datetime", Pattern: regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?)?`)},
+	{Name: "uuid", Pattern: regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)},
+	{Name: "time", Pattern: regexp.MustCompile(`^\d{1,2}:\d{2}(:\d{2}(\.\d+)?)?`)}, // like 12:30 or 12:30:45.123
+	{Name: "ratio", Pattern: regexp.MustCompile(`^\d+(:\d+)+`)},                    // like 16:9
+	{Name: "mailto", Pattern: regexp.MustCompile(`^mailto:[\w.%+-]+@[\w-]+(\.[\w-]+)+(\?[\w.%+=&-]*)?`)},
+	{Name: "email", Pattern: regexp.MustCompile(`^[\w.%+-]+@[\w-]+(\.[\w-]+)+`)},
+	{Name: "mention", Pattern: regexp.MustCompile(`^@[\w-]+(/[\w.-]+)?`)}, // like @handle or @scope/package
+}
+
+// match returns the length in runes of the token at the start of the text, or 0.
+func (t *Token) match(text []rune) int {
+	if t.Pattern == nil {
+		if t.Match == nil {
+			return 0
+		}
+		return min(max(t.Match(text), 0), len(text))
+	}
+	candidate := string(text[:min(maxTokenLength, len(text))])
+	return utf8.RuneCountInString(t.Pattern.FindString(candidate))
+}
+
+// parseToken parses an unquoted well-known token like a URL, date or email address, using
+// the first of the registered tokens which matches the whole value.
+func parseToken(c *cursor, output *strings.Builder, opts *options) bool {
+	if c.done() || isQuote(c.peek(0)) || isWhitespace(c.peek(0)) ||
+		c.peek(0) != codeOpeningBracket && isDelimiter(c.peek(0)) {
+		return false
+	}
+	for k := range opts.tokens {
+		token := &opts.tokens[k]
+		length := token.match(c.rest())
+		if length == 0 || !atEndOfToken(c, c.pos+length) {
+			continue
+		}
+
+		value := string(c.slice(c.pos, c.pos+length))
+		if token.Transform != nil {
+			value = token.Transform(value)
+		}
+		switch {
+		case token.Handling == TokenRaw:
+			output.WriteString(value)
+			logRepair(c.pos, output, "replaced "+token.Name+" token", opts)
+		case token.Handling == TokenNumber && regexJSONNumber.MatchString(value):
+			output.WriteString(value)
+			logRepair(c.pos, output, "replaced "+token.Name+" token", opts)
+		default:
+			writeQuoted(output, value)
+			logRepair(c.pos, output, "added missing quotes", opts)
+		}
+		c.skip(length)
+		return true
+	}
+	return false
+}
+
+// regexJSONNumber matches a valid JSON number.
+var regexJSONNumber = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?([eE][+-]?\d+)?$`)
+
+// writeQuoted writes the value as a JSON string, escaping quotes, backslashes and control characters.
+func writeQuoted(output *strings.Builder, value string) {
+	output.WriteRune(codeDoubleQuote)
+	writeEscaped(output, value)
+	output.WriteRune(codeDoubleQuote)
+}
+
+// writeEscaped writes the value as the content of a JSON string, escaping quotes, backslashes
+// and control characters.
+func writeEscaped(output *strings.Builder, value string) {
+	for _, char := range value {
+		if escaped, ok := controlCharacters[char]; ok {
+			output.WriteString(escaped)
+		} else if char == codeDoubleQuote || char == codeBackslash {
+			output.WriteRune(codeBackslash)
+			output.WriteRune(char)
+		} else {
+			output.WriteRune(char)
+		}
+	}
+}
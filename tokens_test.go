@@ -0,0 +1,117 @@
+package jsonrepair
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	thousands := Token{
+		Name:      "thousands",
+		Pattern:   regexp.MustCompile(`^\d{1,3}(_\d{3})+`),
+		Handling:  TokenNumber,
+		Transform: func(token string) string { return strings.ReplaceAll(token, "_", "") },
+	}
+	nan := Token{
+		Name: "nan",
+		Match: func(text []rune) int {
+			if strings.HasPrefix(string(text), "NaN") {
+				return len("NaN")
+			}
+			return 0
+		},
+		Handling:  TokenRaw,
+		Transform: func(string) string { return "null" },
+	}
+	version := Token{Name: "version", Pattern: regexp.MustCompile(`^v\d+(\.\d+)*`)}
+
+	tests := []struct {
+		name     string
+		input    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "date and time",
+			input:    `{d: 2024-01-01, t: 2024-01-01T10:00:00.5+02:00}`,
+			expected: `{"d": "2024-01-01", "t": "2024-01-01T10:00:00.5+02:00"}`,
+		},
+		{
+			name:     "dates are kept whole with any invalid number policy",
+			input:    `[2024-01-01]`,
+			opts:     []Option{WithInvalidNumberPolicy(InvalidNumberTruncate)},
+			expected: `["2024-01-01"]`,
+		},
+		{
+			name:     "uuid",
+			input:    `{id: 746de9ad-d4ff-4c66-97d7-00a92ad46967}`,
+			expected: `{"id": "746de9ad-d4ff-4c66-97d7-00a92ad46967"}`,
+		},
+		{
+			name:     "number handling with transform",
+			input:    `{n: 1_000_000}`,
+			opts:     []Option{WithTokens(thousands)},
+			expected: `{"n": 1000000}`,
+		},
+		{
+			name:     "raw handling with match function",
+			input:    `[1, NaN, 2]`,
+			opts:     []Option{WithTokens(nan)},
+			expected: `[1, null, 2]`,
+		},
+		{
+			name:     "string handling",
+			input:    `{v: v1.2.3}`,
+			opts:     []Option{WithTokens(version)},
+			expected: `{"v": "v1.2.3"}`,
+		},
+		{
+			name:     "tokens must make up the whole value",
+			input:    `{v: v1.2.3 beta}`,
+			opts:     []Option{WithTokens(version)},
+			expected: `{"v": "v1.2.3 beta"}`,
+		},
+		{
+			name:     "custom tokens are recognized before the built-in tokens",
+			input:    `[12:30]`,
+			opts:     []Option{WithTokens(Token{Name: "clock", Pattern: regexp.MustCompile(`^12:30`), Handling: TokenRaw, Transform: func(string) string { return "750" }})},
+			expected: `[750]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := JSONRepair(tt.input, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestTokensReportRepairs(t *testing.T) {
+	var report Report
+	thousands := Token{Name: "thousands", Pattern: regexp.MustCompile(`^\d+(_\d{3})+`), Handling: TokenNumber,
+		Transform: func(token string) string { return strings.ReplaceAll(token, "_", "") }}
+
+	result, err := JSONRepair(`[1_000, 12:30]`, WithTokens(thousands), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, `[1000, "12:30"]`, result)
+	assert.Equal(t, []Repair{
+		{Position: 1, Kind: RepairToken, Message: "replaced thousands token"},
+		{Position: 8, Kind: RepairMissingQuote, Message: "added missing quotes"},
+	}, report.Repairs)
+}
+
+// TestTokenMatchOutOfRange tests clamping the length returned by the Match of a token.
+func TestTokenMatchOutOfRange(t *testing.T) {
+	for _, length := range []int{-5, 100} {
+		token := Token{Name: "broken", Match: func(text []rune) int { return length }}
+		assert.NotPanics(t, func() {
+			_, _ = JSONRepair(`[abc]`, WithTokens(token))
+		})
+	}
+}
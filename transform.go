@@ -0,0 +1,146 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// WithStringTransformer calls transform for every string value of the repaired document, and
+// writes the string it returns instead, to trim, redact or normalize the values in the same
+// pass as the repair. The key is the key of the member whose value is the string, or of the
+// member holding the array the string is in, and empty for a string which is not in an
+// object. The key and the value are passed unescaped. Object keys are not transformed.
+func WithStringTransformer(transform func(key, value string) string) Option {
+	return func(o *options) {
+		o.stringTransformer = transform
+	}
+}
+
+// enterMember sets the key of the member whose value is parsed, for WithStringTransformer, and
+// returns the key of the enclosing member, to be restored when the object is done.
+func enterMember(key string, opts *options) string {
+	parent := opts.key
+	if opts.stringTransformer != nil {
+		var name string
+		if json.Unmarshal([]byte(key), &name) == nil {
+			opts.key = name
+		}
+	}
+	return parent
+}
+
+// transformString replaces the string value written to the output at valueStart with the
+// result of the string transformer, if any. A value which is not a string is left as it is.
+func transformString(output *strings.Builder, valueStart int, opts *options) {
+	if opts.stringTransformer != nil {
+		replaceString(output, valueStart, func(value string) string {
+			return opts.stringTransformer(opts.key, value)
+		})
+	}
+}
+
+// transformKey replaces the key written to the output at keyStart with the result of the key
+// transformer, if any.
+func transformKey(output *strings.Builder, keyStart int, opts *options) {
+	if opts.keyTransformer != nil {
+		replaceString(output, keyStart, opts.keyTransformer)
+	}
+}
+
+// replaceString replaces the JSON string written to the output at start with the result of
+// replace, keeping what follows the string, like whitespace or an annotation. Text which is
+// not a string is left as it is.
+func replaceString(output *strings.Builder, start int, replace func(string) string) {
+	outputStr := output.String()
+	value := outputStr[start:]
+	if !strings.HasPrefix(value, `"`) {
+		return
+	}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	var content string
+	if decoder.Decode(&content) != nil {
+		return
+	}
+	end := start + int(decoder.InputOffset())
+
+	output.Reset()
+	output.WriteString(outputStr[:start])
+	writeQuoted(output, replace(content))
+	output.WriteString(outputStr[end:])
+}
+
+// WithKeyTransformer calls transform for every object key of the repaired document, and writes
+// the key it returns instead, to normalize the style of the keys in the same pass as the
+// repair, like with CamelCase or SnakeCase. The key is passed unescaped. Keys which are the same
+// after the transform are repeated keys, see WithWarnings.
+func WithKeyTransformer(transform func(key string) string) Option {
+	return func(o *options) {
+		o.keyTransformer = transform
+	}
+}
+
+// CamelCase converts a key to camel case, like user_name and UserName to userName. Words are
+// separated by underscores, dashes, spaces and changes of case. Leading underscores are kept,
+// so _id stays _id.
+func CamelCase(key string) string {
+	prefix, words := splitWords(key)
+	var result strings.Builder
+	result.WriteString(prefix)
+	for k, word := range words {
+		if k == 0 {
+			result.WriteString(strings.ToLower(word))
+		} else {
+			result.WriteString(capitalize(word))
+		}
+	}
+	return result.String()
+}
+
+// SnakeCase converts a key to snake case, like userName and HTTPServer to user_name and
+// http_server. Words are separated by underscores, dashes, spaces and changes of case. Leading
+// underscores are kept, so _id stays _id.
+func SnakeCase(key string) string {
+	prefix, words := splitWords(key)
+	for k, word := range words {
+		words[k] = strings.ToLower(word)
+	}
+	return prefix + strings.Join(words, "_")
+}
+
+// capitalize returns the word in lower case with an upper case first letter.
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// splitWords splits a key into its leading underscores and its words, which are separated by
+// underscores, dashes, spaces and changes of case: a lower case letter or digit followed by an
+// upper case letter, like userName, or the last upper case letter of an acronym followed by a
+// lower case letter, like HTTPServer.
+func splitWords(key string) (string, []string) {
+	trimmed := strings.TrimLeft(key, "_")
+	prefix := key[:len(key)-len(trimmed)]
+
+	var words []string
+	runes := []rune(trimmed)
+	start := 0
+	for k, char := range runes {
+		switch {
+		case char == '_' || char == '-' || unicode.IsSpace(char):
+			if k > start {
+				words = append(words, string(runes[start:k]))
+			}
+			start = k + 1
+		case k > start && unicode.IsUpper(char) && (unicode.IsLower(runes[k-1]) || unicode.IsDigit(runes[k-1]) ||
+			k+1 < len(runes) && unicode.IsUpper(runes[k-1]) && unicode.IsLower(runes[k+1])):
+			words = append(words, string(runes[start:k]))
+			start = k
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return prefix, words
+}
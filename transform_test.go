@@ -0,0 +1,76 @@
+package jsonrepair
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringTransformer(t *testing.T) {
+	var keys []string
+	transform := WithStringTransformer(func(key, value string) string {
+		keys = append(keys, key)
+		if key == "password" {
+			return "***"
+		}
+		return strings.TrimSpace(value)
+	})
+
+	assertRepair(t, `{"name": " John ", "password": 'secret', "tags": [" a", {"b": "c "}, "d "]}`,
+		`{"name": "John", "password": "***", "tags": ["a", {"b": "c"}, "d"]}`, transform)
+	assert.Equal(t, []string{"name", "password", "tags", "b", "tags"}, keys)
+
+	// repaired strings, valid JSON and the root value are transformed too
+	assertRepair(t, `{password: hunter2, note: "a" + "b"}`, `{"password": "***", "note": "ab"}`, transform)
+	assertRepair(t, `{"password": "x\"y", "n": 1}`, `{"password": "***", "n": 1}`, transform)
+	assertRepair(t, `" x "`, `"x"`, transform)
+
+	// the transformed value is escaped
+	assertRepair(t, `["a"]`, `["\"a\"\n"]`, WithStringTransformer(func(_, value string) string {
+		return `"` + value + "\"\n"
+	}))
+
+	// annotations are kept
+	assertRepair(t, `[' a ']`, `["a" /* jsonrepair: replaced quotes with double quotes */]`, transform, WithAnnotations())
+}
+
+func TestKeyTransformer(t *testing.T) {
+	assertRepair(t, `{user_name: 'John', "Address": {"zip-code": 1}, "_id": 2}`,
+		`{"userName": "John", "address": {"zipCode": 1}, "_id": 2}`, WithKeyTransformer(CamelCase))
+	assertRepair(t, `{"userName": "John", "HTTPServer": [{"userID": 1}]}`,
+		`{"user_name": "John", "http_server": [{"user_id": 1}]}`, WithKeyTransformer(SnakeCase))
+	assertRepair(t, `array('firstName' => 1)`, `{"first_name" : 1}`, WithKeyTransformer(SnakeCase))
+
+	// keys which are the same after the transform are repeated
+	var warnings []Issue
+	assertRepair(t, `{"userName": 1, "user_name": 2}`, `{"user_name": 1, "user_name": 2}`,
+		WithKeyTransformer(SnakeCase), WithWarnings(&warnings))
+	assert.Len(t, warnings, 1)
+
+	// string values are not keys
+	assertRepair(t, `{"a": "userName"}`, `{"A": "userName"}`, WithKeyTransformer(strings.ToUpper))
+}
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		key, camel, snake string
+	}{
+		{"userName", "userName", "user_name"},
+		{"UserName", "userName", "user_name"},
+		{"user_name", "userName", "user_name"},
+		{"user-name", "userName", "user_name"},
+		{"user name", "userName", "user_name"},
+		{"HTTPServer", "httpServer", "http_server"},
+		{"userID", "userId", "user_id"},
+		{"version2Name", "version2Name", "version2_name"},
+		{"_id", "_id", "_id"},
+		{"__private_key", "__privateKey", "__private_key"},
+		{"ÄpfelBirnen", "äpfelBirnen", "äpfel_birnen"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.camel, CamelCase(tt.key), tt.key)
+		assert.Equal(t, tt.snake, SnakeCase(tt.key), tt.key)
+	}
+}
@@ -1,10 +1,60 @@
 package jsonrepair
 
 import (
+	"html"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// splitPreamble splits a leading shebang line and front-matter block from the text.
+func splitPreamble(text string) (string, string) {
+	shebang, rest := splitShebang(text)
+	frontMatter, rest := splitFrontMatter(rest)
+	return shebang + frontMatter, rest
+}
+
+// splitShebang splits a leading shebang line like "#!/usr/bin/env node" from the text.
+func splitShebang(text string) (string, string) {
+	if !strings.HasPrefix(text, "#!") {
+		return "", text
+	}
+	end := strings.IndexByte(text, '\n')
+	if end == -1 {
+		return text, ""
+	}
+	return text[:end+1], text[end+1:]
+}
+
+// splitLogPrefix splits a log line prefix like "2024-01-01 12:00:00 INFO payload=" from the
+// text, up to the first opening brace or bracket. A bracket enclosing a plain word which is
+// followed by more text on the line, like [INFO] or [main], is part of the prefix.
+func splitLogPrefix(text string) (string, string) {
+	for start := 0; start < len(text); start++ {
+		switch text[start] {
+		case '{':
+			return text[:start], text[start:]
+		case '[':
+			if !atBracketedWord(text[start:]) {
+				return text[:start], text[start:]
+			}
+		}
+	}
+	return "", text
+}
+
+// atBracketedWord checks if the text starts with a bracket enclosing text without quotes,
+// commas, brackets or braces, which is followed by more text on the same line.
+func atBracketedWord(text string) bool {
+	end := strings.IndexAny(text[1:], "]\"',[{}\n")
+	if end == -1 || text[1+end] != ']' {
+		return false
+	}
+	rest, _, _ := strings.Cut(text[end+2:], "\n")
+	return strings.TrimSpace(rest) != ""
+}
+
 // prevNonWhitespaceIndex finds the previous non-whitespace index in the string.
 func prevNonWhitespaceIndex(text []rune, startIndex int) int {
 	prev := startIndex
@@ -15,18 +65,210 @@ func prevNonWhitespaceIndex(text []rune, startIndex int) int {
 }
 
 // atEndOfBlockComment checks if the current position is at the end of a block comment.
-func atEndOfBlockComment(text *[]rune, i *int) bool {
-	return *i+1 < len(*text) && (*text)[*i] == codeAsterisk && (*text)[*i+1] == codeSlash
+func atEndOfBlockComment(c *cursor) bool {
+	return c.peek(0) == codeAsterisk && c.peek(1) == codeSlash
+}
+
+// atHTMLCommentStart checks if the current position is at the start of an HTML comment "<!--".
+func atHTMLCommentStart(c *cursor) bool {
+	return c.hasPrefix("<!--")
+}
+
+// progressed records the position at the start of a loop iteration, and fails the repair with
+// ErrNoProgress when the previous iteration did not move the index, instead of looping forever.
+func progressed(last *int, i int, opts *options) bool {
+	if i <= *last {
+		opts.fail(newError(ErrNoProgress, "", opts.offset+i))
+		return false
+	}
+	*last = i
+	return true
+}
+
+// atCommentStart checks if the current position is at the start of a block or line comment.
+// A double slash is only a line comment after whitespace, so that a URL like http://example.com//a
+// is kept whole. The same holds for a hash when hash comments are enabled.
+func atCommentStart(c *cursor, opts *options) bool {
+	if c.done() {
+		return false
+	}
+	afterWhitespace := c.pos == 0 || isWhitespace(c.peek(-1))
+	if opts.hashComments && c.peek(0) == codeHash {
+		return afterWhitespace
+	}
+	if atHTMLCommentStart(c) {
+		return true
+	}
+	if c.peek(0) != codeSlash {
+		return false
+	}
+	next := c.peek(1)
+	return next == codeAsterisk || next == codeSlash && afterWhitespace
 }
 
 // atEndOfNumber checks if the end of a number has been reached in the input text.
-func atEndOfNumber(text *[]rune, i *int) bool {
-	return *i >= len(*text) || isDelimiter((*text)[*i]) || isWhitespace((*text)[*i])
+func atEndOfNumber(c *cursor) bool {
+	return c.done() || isDelimiter(c.peek(0)) || isWhitespace(c.peek(0)) || atArrow(c)
+}
+
+// atEndOfNumberOrCustom checks like atEndOfNumber if the end of a number has been reached,
+// also at the delimiters and whitespace added with WithDelimiters and WithWhitespace.
+func atEndOfNumberOrCustom(c *cursor, opts *options) bool {
+	return atEndOfNumber(c) || isCustomDelimiter(c.peek(0), opts) || isCustomWhitespace(c.peek(0), opts)
+}
+
+// atSemicolonSeparator checks if the current position is at a semicolon separating items like in
+// {a: 1; b: 2}, which is followed by whitespace, a quote, a closing bracket or the end of the text.
+func atSemicolonSeparator(c *cursor) bool {
+	if c.peek(0) != codeSemicolon {
+		return false
+	}
+	next := c.peek(1)
+	return c.remaining() == 1 || isWhitespace(next) || isQuote(next) ||
+		next == codeClosingBracket || next == codeClosingBrace || next == codeCloseParenthesis
+}
+
+// atSemicolonBeforeQuote checks like atSemicolonSeparator if the current position is at a semicolon
+// separating items, which is followed by a quoted item or a closing bracket.
+// A semicolon followed by other text may belong to a string with a misplaced quote, like in
+// "The TV has a 24"; screen".
+func atSemicolonBeforeQuote(c *cursor) bool {
+	if c.peek(0) != codeSemicolon {
+		return false
+	}
+	j := cursor{text: c.text, pos: c.pos + 1}
+	skipWhitespace(&j)
+	next := j.peek(0)
+	return isQuote(next) || next == codeClosingBracket || next == codeClosingBrace
+}
+
+// atCharacterReferenceEnd checks if the current position is at the semicolon ending a character
+// reference like &amp; or &#39; which started after start, so the semicolon is not a separator.
+func atCharacterReferenceEnd(c *cursor, start, i int) bool {
+	if c.at(i) != codeSemicolon {
+		return false
+	}
+	j := i - 1
+	for j > start && (isASCIIAlphanumeric(c.at(j)) || c.at(j) == codeHash) {
+		j--
+	}
+	return j >= start && j < i-1 && c.at(j) == codeAmpersand
+}
+
+// atEndOfToken checks if a token ending at the given position is a whole value: it is followed
+// by the end of the text, a delimiter other than a colon, or whitespace up to the end of the
+// line, the next delimiter or the quote of a next value. Otherwise the token is part of a longer
+// unquoted string, like @abc" with a dangling end quote.
+func atEndOfToken(c *cursor, end int) bool {
+	j := end
+	for isWhitespace(c.at(j)) && c.at(j) != codeNewline {
+		j++
+	}
+	return j >= c.len() || c.at(j) != codeColon && isDelimiter(c.at(j)) || j > end && isQuote(c.at(j))
+}
+
+// atColonInKey checks if the current position is at a colon inside a namespaced key like
+// db:host, which is a colon followed by a name and another colon. The last colon of the key
+// separates the key from the value.
+func atColonInKey(c *cursor) bool {
+	if c.peek(0) != codeColon {
+		return false
+	}
+	j := *c
+	j.next()
+	start := j.pos
+	for isSymbolChar(j.peek(0)) || j.peek(0) == codeMinus || j.peek(0) == codeDot {
+		j.next()
+	}
+	return j.pos > start && j.peek(0) == codeColon
+}
+
+// atQuotedKey checks if the current position is at a quoted key followed by a colon, like "b": 1.
+func atQuotedKey(c *cursor) bool {
+	if c.peek(0) != codeDoubleQuote && c.peek(0) != codeQuote {
+		return false
+	}
+	j := *c
+	skipStringLiteral(&j)
+	skipWhitespace(&j)
+	return j.peek(0) == codeColon
+}
+
+// skipPlaceholder moves the cursor past a named placeholder of a logging template like %{var}
+// or %(name)s, so its brace or parentheses are not taken for delimiters, and returns whether
+// there is one. Placeholders like %s and %05.2f contain no delimiters and need no skipping.
+func skipPlaceholder(c *cursor) bool {
+	if c.peek(0) != codePercent {
+		return false
+	}
+	var closing rune
+	switch c.peek(1) {
+	case codeOpeningBrace:
+		closing = codeClosingBrace
+	case codeOpenParenthesis:
+		closing = codeCloseParenthesis
+	default:
+		return false
+	}
+	j := *c
+	j.skip(2)
+	start := j.pos
+	for isSymbolChar(j.peek(0)) || j.peek(0) == codeDot || j.peek(0) == codeMinus {
+		j.next()
+	}
+	if j.pos == start || !skipCharacter(&j, closing) {
+		return false
+	}
+	c.pos = j.pos
+	return true
+}
+
+// regexHTMLEntity matches an HTML character reference like &amp;, &#34; or &#x22;.
+var regexHTMLEntity = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// maxHTMLEntityLength is the maximum length of an HTML character reference, which is
+// &CounterClockwiseContourIntegral; for named references.
+const maxHTMLEntityLength = 33
+
+// decodeHTMLEntity decodes the HTML entity at the current position when WithHTMLEntities is
+// used, and returns the character with the length of the entity, or a length of 0. A double
+// encoded entity like &amp;quot; is decoded twice.
+func decodeHTMLEntity(c *cursor, opts *options) (string, int) {
+	if !opts.htmlEntities || c.peek(0) != codeAmpersand {
+		return "", 0
+	}
+	decoded, length := "&", 0
+	for decoded == "&" {
+		entity := regexHTMLEntity.FindString("&" + string(c.slice(c.pos+length+1, c.pos+length+maxHTMLEntityLength)))
+		if entity == "" || html.UnescapeString(entity) == entity {
+			break
+		}
+		decoded = html.UnescapeString(entity)
+		length += utf8.RuneCountInString(entity) - 1
+	}
+	if length == 0 {
+		return "", 0
+	}
+	return decoded, length + 1
+}
+
+// atQuoteEntity returns the length of an HTML entity for a double quote like &quot; or &#34;
+// at the current position when WithHTMLEntities is used, or 0.
+func atQuoteEntity(c *cursor, opts *options) int {
+	if decoded, length := decodeHTMLEntity(c, opts); decoded == `"` {
+		return length
+	}
+	return 0
+}
+
+// atArrow checks if the current position is at a "=>" separator.
+func atArrow(c *cursor) bool {
+	return c.peek(0) == codeEqual && c.peek(1) == codeGreaterThan
 }
 
 // repairNumberEndingWithNumericSymbol repairs numbers cut off at the end.
-func repairNumberEndingWithNumericSymbol(text *[]rune, start int, i *int, output *strings.Builder) {
-	output.WriteString(string((*text)[start:*i]) + "0")
+func repairNumberEndingWithNumericSymbol(c *cursor, start int, output *strings.Builder) {
+	output.WriteString(string(c.slice(start, c.pos)) + "0")
 }
 
 // stripLastOccurrence removes the last occurrence of a specific substring from the input text.
@@ -70,6 +312,12 @@ func isDigit(code rune) bool {
 	return code >= codeZero && code <= codeNine
 }
 
+// isNumericChar checks if a character can be part of a numeric token: a digit, dot, exponent or sign.
+func isNumericChar(code rune) bool {
+	return isDigit(code) || code == codeDot || code == codeLowercaseE || code == codeUppercaseE ||
+		code == codeMinus || code == codePlus
+}
+
 // isValidStringCharacter checks if a code is a valid string character.
 func isValidStringCharacter(code rune) bool {
 	return code >= 0x20 && code <= 0x10FFFF
@@ -88,6 +336,16 @@ func isDelimiterExceptSlash(char rune) bool {
 	return isDelimiter(char) && char != '/'
 }
 
+// isCustomDelimiter checks if a rune is one of the delimiters added with WithDelimiters.
+func isCustomDelimiter(char rune, opts *options) bool {
+	return strings.ContainsRune(opts.delimiters, char)
+}
+
+// isCustomWhitespace checks if a rune is one of the whitespace characters added with WithWhitespace.
+func isCustomWhitespace(char rune, opts *options) bool {
+	return strings.ContainsRune(opts.whitespace, char)
+}
+
 // isStartOfValue checks if a rune is the start of a JSON value.
 func isStartOfValue(char rune) bool {
 	return regexStartOfValue.MatchString(string(char)) || isQuote(char)
@@ -96,6 +354,16 @@ func isStartOfValue(char rune) bool {
 // regexStartOfValue defines the regular expression for the start of a JSON value.
 var regexStartOfValue = regexp.MustCompile(`^[{[\w-]$`)
 
+// isSymbolStart checks if a rune can start a Ruby symbol name.
+func isSymbolStart(code rune) bool {
+	return unicode.IsLetter(code) || code == codeUnderscore
+}
+
+// isSymbolChar checks if a rune can be part of a Ruby symbol name.
+func isSymbolChar(code rune) bool {
+	return isSymbolStart(code) || isDigit(code)
+}
+
 // isControlCharacter checks if a rune is a control character.
 func isControlCharacter(code rune) bool {
 	return code == codeNewline ||
@@ -148,17 +416,232 @@ func isSingleQuoteLike(code rune) bool {
 		code == codeAcuteAccent
 }
 
+// isReplacementQuote checks if a rune is the Unicode replacement character and is to be treated as a quote.
+func isReplacementQuote(code rune, opts *options) bool {
+	return opts.replacementQuotes && code == codeReplacementCharacter
+}
+
 // isSingleQuote checks if a rune is a single quote.
 func isSingleQuote(code rune) bool {
 	return code == codeQuote
 }
 
+// regexURLStart matches the scheme of a URL at the start of a value, like "https://".
+var regexURLStart = regexp.MustCompile(`^(https?|ftp|mailto|file|data|irc|wss?)://`)
+
+// urlChars are the characters allowed in a URL: the unreserved and reserved characters of
+// RFC 3986 without the brackets, and the percent sign of percent-encoding.
+const urlChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~:/?#@!$&'()*+;=%"
+
+// skipURL moves the cursor to the end of a URL starting at the current position, and returns
+// whether there is one. A bracketed IPv6 host like http://[::1]:8080/ is part of the URL,
+// other characters are allowed next to urlChars when added with WithURLChars.
+func skipURL(c *cursor, opts *options) bool {
+	scheme := regexURLStart.FindString(string(c.slice(c.pos, c.pos+maxURLSchemeLength)))
+	if scheme == "" {
+		return false
+	}
+	c.skip(len(scheme))
+
+	skipIPv6Address(c)
+
+	// a closing parenthesis or bracket is only part of the URL when opened in the URL,
+	// like in https://en.wikipedia.org/wiki/Go_(programming_language)
+	var open []rune
+	for !c.done() && isURLChar(c.peek(0), opts) {
+		char := c.peek(0)
+		switch char {
+		case codeOpenParenthesis, codeOpeningBracket, codeOpeningBrace:
+			open = append(open, char)
+		case codeCloseParenthesis, codeClosingBracket, codeClosingBrace:
+			if len(open) == 0 || open[len(open)-1] != matchingOpening[char] {
+				return true
+			}
+			open = open[:len(open)-1]
+		}
+		c.next()
+	}
+	return true
+}
+
+// matchingOpening maps a closing parenthesis or bracket to its opening counterpart.
+var matchingOpening = map[rune]rune{
+	codeCloseParenthesis: codeOpenParenthesis,
+	codeClosingBracket:   codeOpeningBracket,
+	codeClosingBrace:     codeOpeningBrace,
+}
+
+// skipIPv6Address moves the cursor past a bracketed IPv6 address like [::1] or [fe80::1%eth0],
+// and returns whether there is one.
+func skipIPv6Address(c *cursor) bool {
+	if c.done() || c.peek(0) != codeOpeningBracket {
+		return false
+	}
+	j := c.pos + 1
+	colons, zone := 0, false
+	for ; j < c.len() && c.at(j) != codeClosingBracket; j++ {
+		char := c.at(j)
+		switch {
+		case char == codeColon && !zone:
+			colons++
+		case char == codePercent && !zone:
+			zone = true
+		case isHex(char) || char == codeDot && colons > 0:
+		case zone && (unicode.IsLetter(char) || unicode.IsDigit(char) || char == codeMinus || char == codeUnderscore):
+		default:
+			return false
+		}
+	}
+	// an address has eight groups, or less when zeros are compressed as "::"
+	if j >= c.len() || colons < 6 && !strings.Contains(string(c.slice(c.pos, j)), "::") {
+		return false
+	}
+	c.pos = j + 1
+	return true
+}
+
+// skipEndpoint moves the cursor past a network endpoint like 10.0.0.1:9200, localhost:8080,
+// db.example.com:5432, [::1] or [::1]:8080, and returns whether there is one.
+func skipEndpoint(c *cursor) bool {
+	j := *c
+	if skipIPv6Address(&j) {
+		skipPort(&j)
+	} else {
+		start := j.pos
+		dots := 0
+		for isASCIIAlphanumeric(j.peek(0)) || j.peek(0) == codeMinus && j.pos > start || j.peek(0) == codeDot && j.pos > start {
+			if j.peek(0) == codeDot {
+				dots++
+			}
+			j.next()
+		}
+		if dots == 0 && string(c.slice(start, j.pos)) != "localhost" || !skipPort(&j) {
+			return false
+		}
+	}
+	if j.peek(0) == codeColon || !atEndOfNumber(&j) {
+		return false
+	}
+	c.pos = j.pos
+	return true
+}
+
+// skipPort moves the cursor past a port like :8080, and returns whether there is one.
+func skipPort(c *cursor) bool {
+	if c.done() || c.peek(0) != codeColon {
+		return false
+	}
+	j := c.pos + 1
+	for j < c.len() && j <= c.pos+5 && isDigit(c.at(j)) {
+		j++
+	}
+	if j == c.pos+1 {
+		return false
+	}
+	c.pos = j
+	return true
+}
+
+// isASCIIAlphanumeric checks if a rune is an ASCII letter or digit.
+func isASCIIAlphanumeric(code rune) bool {
+	return code >= 'a' && code <= 'z' || code >= 'A' && code <= 'Z' || isDigit(code)
+}
+
+// maxURLSchemeLength is the maximum length of the scheme of a URL including "://".
+const maxURLSchemeLength = 10
+
+// isURLChar checks if a rune is allowed in a URL.
+func isURLChar(code rune, opts *options) bool {
+	return strings.ContainsRune(urlChars, code) || strings.ContainsRune(opts.urlChars, code)
+}
+
 // endsWithCommaOrNewline checks if the string ends with a comma or newline character and optional whitespace.
 func endsWithCommaOrNewline(text string) bool {
 	return regexp.MustCompile(`[,\n][ \t\r]*$`).MatchString(text)
 }
 
+// stripNewKeyword removes a leading "new" keyword from a symbol like "new Date".
+func stripNewKeyword(symbol string) string {
+	name, found := strings.CutPrefix(symbol, "new")
+	if !found || name == "" || !isWhitespace(rune(name[0])) {
+		return symbol
+	}
+	return strings.TrimSpace(name)
+}
+
 // isFunctionName checks if a string is a valid function name.
 func isFunctionName(text string) bool {
 	return regexp.MustCompile(`^\w+$`).MatchString(text)
 }
+
+// atWord checks if the current position is at the given word, not followed by other symbol characters.
+func atWord(c *cursor, word string) bool {
+	return c.hasPrefix(word) && !isSymbolChar(c.peek(len(word)))
+}
+
+// skipWhitespace skips whitespace without writing it to the output.
+func skipWhitespace(c *cursor) {
+	for !c.done() && isWhitespace(c.peek(0)) {
+		c.next()
+	}
+}
+
+// skipBlock skips a block enclosed in parentheses, brackets or braces, starting at the
+// opening character, including nested blocks, strings and comments. An unterminated
+// block is skipped up to the end of the text.
+func skipBlock(c *cursor, opts *options) {
+	depth := 0
+	for !c.done() {
+		char := c.peek(0)
+		switch {
+		case char == codeOpenParenthesis || char == codeOpeningBracket || char == codeOpeningBrace:
+			depth++
+		case char == codeCloseParenthesis || char == codeClosingBracket || char == codeClosingBrace:
+			depth--
+		case char == codeDoubleQuote || char == codeQuote || char == codeGraveAccent:
+			skipStringLiteral(c)
+			continue
+		case parseComment(c, opts):
+			continue
+		}
+		c.next()
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// skipArrowFunctionBody skips the body of an arrow function: either a block, or an
+// expression which ends at a delimiter outside of nested blocks.
+func skipArrowFunctionBody(c *cursor, opts *options) {
+	if !c.done() && c.peek(0) == codeOpeningBrace {
+		skipBlock(c, opts)
+		return
+	}
+	for !c.done() {
+		char := c.peek(0)
+		switch {
+		case char == codeComma || char == codeCloseParenthesis || char == codeClosingBracket || char == codeClosingBrace:
+			return
+		case char == codeOpenParenthesis || char == codeOpeningBracket || char == codeOpeningBrace:
+			skipBlock(c, opts)
+		case char == codeDoubleQuote || char == codeQuote || char == codeGraveAccent:
+			skipStringLiteral(c)
+		default:
+			c.next()
+		}
+	}
+}
+
+// skipStringLiteral skips a JavaScript string literal starting at the opening quote.
+func skipStringLiteral(c *cursor) {
+	quote := c.peek(0)
+	c.next()
+	for !c.done() && c.peek(0) != quote {
+		if c.peek(0) == codeBackslash {
+			c.next()
+		}
+		c.next()
+	}
+	c.next()
+}
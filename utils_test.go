@@ -38,3 +38,36 @@ func TestInsertBeforeLastWhitespace(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitShebang(t *testing.T) {
+	tests := []struct {
+		text    string
+		shebang string
+		rest    string
+	}{
+		{"#!/usr/bin/env node\n{}", "#!/usr/bin/env node\n", "{}"},
+		{"#!/bin/sh", "#!/bin/sh", ""},
+		{"{}", "", "{}"},
+		{"# comment\n{}", "", "# comment\n{}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			shebang, rest := splitShebang(test.text)
+			assert.Equal(t, test.shebang, shebang)
+			assert.Equal(t, test.rest, rest)
+		})
+	}
+}
+
+func TestProgressed(t *testing.T) {
+	opts := newOptions()
+	last := -1
+	assert.True(t, progressed(&last, 0, opts))
+	assert.True(t, progressed(&last, 3, opts))
+	assert.NoError(t, opts.err)
+
+	assert.False(t, progressed(&last, 3, opts))
+	assert.ErrorIs(t, opts.err, ErrNoProgress)
+	assert.EqualError(t, opts.err, "repair made no progress at position 3")
+}
@@ -0,0 +1,75 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+)
+
+// NeedsRepair checks if the text needs a repair, which is when it is not valid JSON. It is much
+// faster than a repair, so use it to skip the repair of input which is valid most of the time.
+// Valid JSON with a lone escaped surrogate, like "\ud83d", is reported as needing a repair,
+// since the repair replaces it. JSONRepair itself returns valid JSON as it is without
+// parsing it, unless an option like WithNestedStringRepair changes it.
+func NeedsRepair(text string) bool {
+	return !isValidJSON(text)
+}
+
+// isValidJSON checks if the text is valid JSON which a repair keeps as it is: valid UTF-8
+// without lone escaped surrogates.
+func isValidJSON(text string) bool {
+	return utf8.ValidString(text) && !hasLoneSurrogate(text) && json.Valid([]byte(text))
+}
+
+// hasLoneSurrogate checks if the text contains an escaped surrogate which is not part of a
+// pair, like \ud83d, which a repair replaces. An escaped backslash followed by such text, like
+// \\ud83d, is not an escape.
+func hasLoneSurrogate(text string) bool {
+	for k := 0; k < len(text); k++ {
+		if text[k] != '\\' {
+			continue
+		}
+		k++ // the escaped character, which may be a backslash
+		if k >= len(text) || text[k] != 'u' {
+			continue
+		}
+		r := unescapeHex(text[k+1:])
+		switch {
+		case r >= 0xdc00 && r <= 0xdfff:
+			return true
+		case r >= 0xd800 && r <= 0xdbff:
+			if k+5 >= len(text) || text[k+5] != '\\' || k+6 >= len(text) || text[k+6] != 'u' {
+				return true
+			}
+			if low := unescapeHex(text[k+7:]); low < 0xdc00 || low > 0xdfff {
+				return true
+			}
+			k += 10 // the pair
+		}
+	}
+	return false
+}
+
+// unescapeHex returns the rune of the four hex digits at the start of the text, or -1.
+func unescapeHex(text string) rune {
+	if len(text) < 4 {
+		return -1
+	}
+	code, err := strconv.ParseUint(text[:4], 16, 32)
+	if err != nil {
+		return -1
+	}
+	return rune(code)
+}
+
+// keepsValidJSON checks if the repair keeps valid JSON as it is with the options, so it can be
+// skipped. Nested strings and HTML entities in strings are repaired, a log prefix removes the
+// leading whitespace, a string scan limit splits long strings, and a depth limit below the one
+// of encoding/json must be checked, like big numbers which are preserved. Warnings about
+// repeated keys need the keys to be parsed, custom rules, a string transformer and a schema the
+// values, and a key transformer the keys.
+func (o *options) keepsValidJSON() bool {
+	return o.nestedKeys == nil && !o.htmlEntities && !o.skipLogPrefix && o.maxStringScan == 0 &&
+		(o.maxDepth == 0 || o.maxDepth >= defaultMaxDepth) && o.warnings == nil && len(o.customRules) == 0 &&
+		o.stringTransformer == nil && o.keyTransformer == nil && o.schema == nil && !o.preserveBigNumbers
+}
@@ -0,0 +1,64 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNeedsRepair tests checking if a text needs a repair.
+func TestNeedsRepair(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`{"a": [1, 2.5e3, true, null]}`, false},
+		{" \n\"text\" \t", false},
+		{`"😀"`, false},
+		{`"\ud83d"`, true},
+		{`"\ude00"`, true},
+		{`"\ud83dA"`, true},
+		{`"\ud83d\u0041"`, true},
+		{`"\\\ud83d"`, true},
+		{`"\ud83d\ude00"`, false},
+		{`{"a":"\uD83D\uDE00 \ud83d\ude00"}`, false},
+		{`{"a":"x\\ud83d"}`, false},
+		{`"\\\\ude00"`, false},
+		{`"é"`, false},
+		{`{a: 1}`, true},
+		{`[1, 2,]`, true},
+		{"\"\xff\"", true},
+		{"\ufeff[]", true},
+		{``, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, NeedsRepair(tt.input), tt.input)
+	}
+}
+
+// TestRepairKeepsValidJSON tests keeping valid JSON as it is, with the formatting options applied.
+func TestRepairKeepsValidJSON(t *testing.T) {
+	inputs := []string{
+		`{"a": {"b": [1, {"c": null}]}}`,
+		" \r\n[1,\r\n2]\t",
+		`"// not a comment"`,
+		`"&quot;"`,
+		`{"a": 1, "a": 2}`,
+		`123456789012345678901234567890`,
+		`{"a":"\ud83d\ude00"}`,
+		`{"a":"x\\ud83d"}`,
+	}
+	for _, input := range inputs {
+		var report Report
+		result, err := JSONRepair(input, WithReport(&report))
+		require.NoError(t, err)
+		assert.Equal(t, input, result)
+		assert.Empty(t, report.Repairs)
+	}
+
+	assertRepair(t, `{"a": [1, 2]}`, `{"a":[1,2]}`, WithWhitespacePolicy(WhitespaceCompact))
+	assertRepair(t, `"&quot;"`, `"\""`, WithHTMLEntities())
+	assertRepair(t, ` [1]`, `[1]`, WithSkipLogPrefix())
+	assertRepairFailure(t, `[[1]]`, `maximum nesting depth exceeded`, 1, WithMaxDepth(1))
+}
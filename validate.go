@@ -0,0 +1,166 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kaptinlin/jsonrepair/rule"
+)
+
+// Repairer repairs texts with a fixed set of options, which are validated once by NewRepairer.
+// A Repairer is safe for concurrent use, unless WithReport is one of its options.
+type Repairer struct {
+	opts []Option
+}
+
+// NewRepairer returns a Repairer with the given options. It returns an error wrapping
+// ErrInvalidOption, which describes the problem, when an option is invalid or options
+// conflict, like a character which is added both as delimiter and as whitespace.
+func NewRepairer(opts ...Option) (*Repairer, error) {
+	if err := newOptions(opts...).err; err != nil {
+		return nil, err
+	}
+	return &Repairer{opts: opts}, nil
+}
+
+// Repair repairs the given text like JSONRepair.
+func (r *Repairer) Repair(text string) (string, error) {
+	return JSONRepair(text, r.opts...)
+}
+
+// regexReservedChar matches the characters which have a meaning of their own in JSON or its
+// repairs, and thus can not be added as delimiter or whitespace.
+var regexReservedChar = regexp.MustCompile(`^[\w\s{}\[\]():,\\/.+=-]$`)
+
+// regexTagName matches the name of an XML or HTML tag.
+var regexTagName = regexp.MustCompile(`^[A-Za-z_][\w.:-]*$`)
+
+// validate checks the options for invalid values and conflicts, and returns an error wrapping
+// ErrInvalidOption which names the option. JSONRepair fails with this error too.
+func (o *options) validate() error {
+	if err := validateCharClass(o.delimiters, "WithDelimiters", "a delimiter"); err != nil {
+		return err
+	}
+	if err := validateCharClass(o.whitespace, "WithWhitespace", "whitespace"); err != nil {
+		return err
+	}
+	for _, char := range o.delimiters {
+		if strings.ContainsRune(o.whitespace, char) {
+			return fmt.Errorf("%w: %q is added both with WithDelimiters and WithWhitespace", ErrInvalidOption, char)
+		}
+		if strings.ContainsRune(o.urlChars, char) {
+			return fmt.Errorf("%w: %q is added both with WithDelimiters and WithURLChars", ErrInvalidOption, char)
+		}
+	}
+	for _, char := range o.whitespace {
+		if strings.ContainsRune(o.urlChars, char) {
+			return fmt.Errorf("%w: %q is added both with WithWhitespace and WithURLChars", ErrInvalidOption, char)
+		}
+	}
+	if o.hashComments && strings.ContainsRune(o.delimiters+o.whitespace, codeHash) {
+		return fmt.Errorf("%w: '#' can not start comments with WithHashComments and be added with WithDelimiters or WithWhitespace", ErrInvalidOption)
+	}
+
+	for _, name := range o.tagWrappers {
+		if !regexTagName.MatchString(name) {
+			return fmt.Errorf("%w: WithTagWrappers: %q is not a tag name", ErrInvalidOption, name)
+		}
+	}
+
+	for _, token := range o.tokens {
+		switch {
+		case token.Pattern == nil && token.Match == nil:
+			return fmt.Errorf("%w: WithTokens: token %q has neither a Pattern nor a Match function", ErrInvalidOption, token.Name)
+		case strings.ContainsAny(token.Name, `,"`):
+			return fmt.Errorf("%w: WithTokens: token name %q contains a comma or double quote", ErrInvalidOption, token.Name)
+		case token.Handling < TokenString || token.Handling > TokenRaw:
+			return fmt.Errorf("%w: WithTokens: token %q has an unknown Handling %d", ErrInvalidOption, token.Name, token.Handling)
+		}
+	}
+
+	if o.invalidNumberPolicy < InvalidNumberQuote || o.invalidNumberPolicy > InvalidNumberError {
+		return fmt.Errorf("%w: WithInvalidNumberPolicy: unknown policy %d", ErrInvalidOption, o.invalidNumberPolicy)
+	}
+	if o.ellipsisPolicy < EllipsisRemove || o.ellipsisPolicy > EllipsisError {
+		return fmt.Errorf("%w: WithEllipsisPolicy: unknown policy %d", ErrInvalidOption, o.ellipsisPolicy)
+	}
+	if !json.Valid([]byte(o.ellipsisPlaceholder)) {
+		return fmt.Errorf("%w: WithEllipsisPlaceholder: %q is not valid JSON", ErrInvalidOption, o.ellipsisPlaceholder)
+	}
+	if o.newlineStyle < NewlinePreserve || o.newlineStyle > NewlineStripCR {
+		return fmt.Errorf("%w: WithNewlineStyle: unknown style %d", ErrInvalidOption, o.newlineStyle)
+	}
+	if o.whitespacePolicy < WhitespacePreserve || o.whitespacePolicy > WhitespaceCompact {
+		return fmt.Errorf("%w: WithWhitespacePolicy: unknown policy %d", ErrInvalidOption, o.whitespacePolicy)
+	}
+	if o.whitespacePolicy != WhitespacePreserve && o.annotate {
+		return fmt.Errorf("%w: WithWhitespacePolicy: the output can not be reformatted with WithAnnotations", ErrInvalidOption)
+	}
+	if o.surrogatePolicy < SurrogateReplace || o.surrogatePolicy > SurrogateDrop {
+		return fmt.Errorf("%w: WithSurrogatePolicy: unknown policy %d", ErrInvalidOption, o.surrogatePolicy)
+	}
+	if o.encoding != "" && !slices.Contains(unicodeEncodings, o.encoding) {
+		return fmt.Errorf("%w: WithEncoding: unknown encoding %q", ErrInvalidOption, o.encoding)
+	}
+	if o.invalidUTF8Policy < InvalidUTF8Replace || o.invalidUTF8Policy > InvalidUTF8Error {
+		return fmt.Errorf("%w: WithInvalidUTF8Policy: unknown policy %d", ErrInvalidOption, o.invalidUTF8Policy)
+	}
+	if o.nulPolicy < NULKeep || o.nulPolicy > NULEscape {
+		return fmt.Errorf("%w: WithNULPolicy: unknown policy %d", ErrInvalidOption, o.nulPolicy)
+	}
+	if o.stringNewlinePolicy < StringNewlineAuto || o.stringNewlinePolicy > StringNewlineEnd {
+		return fmt.Errorf("%w: WithStringNewlinePolicy: unknown policy %d", ErrInvalidOption, o.stringNewlinePolicy)
+	}
+	if o.maxStringScan < 0 {
+		return fmt.Errorf("%w: WithMaxStringScan: negative limit %d", ErrInvalidOption, o.maxStringScan)
+	}
+	if o.maxDepth < 0 {
+		return fmt.Errorf("%w: WithMaxDepth: negative limit %d", ErrInvalidOption, o.maxDepth)
+	}
+	if o.maxInputSize < 0 {
+		return fmt.Errorf("%w: WithMaxInputSize: negative limit %d", ErrInvalidOption, o.maxInputSize)
+	}
+	if o.maxOutputSize < 0 {
+		return fmt.Errorf("%w: WithMaxOutputSize: negative limit %d", ErrInvalidOption, o.maxOutputSize)
+	}
+	if o.functionCallPolicy < FunctionCallStrip || o.functionCallPolicy > FunctionCallError {
+		return fmt.Errorf("%w: WithFunctionCallPolicy: unknown policy %d", ErrInvalidOption, o.functionCallPolicy)
+	}
+	for _, name := range o.functionNames {
+		if !isFunctionName(name) {
+			return fmt.Errorf("%w: WithFunctionNames: %q is not a function name", ErrInvalidOption, name)
+		}
+	}
+	for r := range o.rules {
+		if !slices.Contains(rule.All(), r) {
+			return fmt.Errorf("%w: WithRules or WithoutRules: unknown rule %q", ErrInvalidOption, r)
+		}
+	}
+	if o.schemaErr != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOption, o.schemaErr)
+	}
+	if o.aggressiveness < AggressivenessDefault || o.aggressiveness > AggressivenessAggressive {
+		return fmt.Errorf("%w: WithAggressiveness: unknown aggressiveness %d", ErrInvalidOption, o.aggressiveness)
+	}
+	if o.mergeStrategy < MergePatch || o.mergeStrategy > MergeDeep {
+		return fmt.Errorf("%w: WithMergeStrategy: unknown strategy %d", ErrInvalidOption, o.mergeStrategy)
+	}
+	return nil
+}
+
+// validateCharClass returns an error when a character of chars, added with the given option as
+// the given class of characters, is reserved, or is a quote or a control character.
+func validateCharClass(chars, option, class string) error {
+	for _, char := range chars {
+		if regexReservedChar.MatchString(string(char)) || isQuote(char) || unicode.IsControl(char) ||
+			char == utf8.RuneError {
+			return fmt.Errorf("%w: %s: %q has a meaning of its own and can not be %s", ErrInvalidOption, option, char, class)
+		}
+	}
+	return nil
+}
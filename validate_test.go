@@ -0,0 +1,73 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepairer(t *testing.T) {
+	repairer, err := NewRepairer(WithDelimiters("|"), WithWhitespace("~"))
+	require.NoError(t, err)
+	result, err := repairer.Repair(`[1|~2]`)
+	require.NoError(t, err)
+	assert.Equal(t, `[1, 2]`, result)
+
+	repairer, err = NewRepairer()
+	require.NoError(t, err)
+	result, err = repairer.Repair(`{a:1}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, result)
+}
+
+func TestNewRepairerValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{"reserved delimiter", []Option{WithDelimiters(":")}, `invalid option: WithDelimiters: ':' has a meaning of its own and can not be a delimiter`},
+		{"reserved whitespace", []Option{WithWhitespace("x")}, `invalid option: WithWhitespace: 'x' has a meaning of its own and can not be whitespace`},
+		{"delimiter and whitespace", []Option{WithDelimiters("|"), WithWhitespace("~|")}, `invalid option: '|' is added both with WithDelimiters and WithWhitespace`},
+		{"delimiter in urls", []Option{WithDelimiters("|"), WithURLChars("[]|")}, `invalid option: '|' is added both with WithDelimiters and WithURLChars`},
+		{"whitespace in urls", []Option{WithURLChars("~"), WithWhitespace("~")}, `invalid option: '~' is added both with WithWhitespace and WithURLChars`},
+		{"hash comments", []Option{WithHashComments(), WithDelimiters("#")}, `invalid option: '#' can not start comments with WithHashComments and be added with WithDelimiters or WithWhitespace`},
+		{"tag name", []Option{WithTagWrappers("json", "<tool>")}, `invalid option: WithTagWrappers: "<tool>" is not a tag name`},
+		{"token without pattern", []Option{WithTokens(Token{Name: "id"})}, `invalid option: WithTokens: token "id" has neither a Pattern nor a Match function`},
+		{"token name", []Option{WithTokens(Token{Name: `a,b`, Match: func([]rune) int { return 0 }})}, `invalid option: WithTokens: token name "a,b" contains a comma or double quote`},
+		{"token handling", []Option{WithTokens(Token{Name: "id", Match: func([]rune) int { return 0 }, Handling: 7})}, `invalid option: WithTokens: token "id" has an unknown Handling 7`},
+		{"invalid number policy", []Option{WithInvalidNumberPolicy(-1)}, `invalid option: WithInvalidNumberPolicy: unknown policy -1`},
+		{"ellipsis policy", []Option{WithEllipsisPolicy(-1)}, `invalid option: WithEllipsisPolicy: unknown policy -1`},
+		{"ellipsis placeholder", []Option{WithEllipsisPlaceholder("…")}, `invalid option: WithEllipsisPlaceholder: "…" is not valid JSON`},
+		{"newline style", []Option{WithNewlineStyle(9)}, `invalid option: WithNewlineStyle: unknown style 9`},
+		{"whitespace policy", []Option{WithWhitespacePolicy(5)}, `invalid option: WithWhitespacePolicy: unknown policy 5`},
+		{"reformat annotations", []Option{WithWhitespacePolicy(WhitespaceIndent), WithAnnotations()}, `invalid option: WithWhitespacePolicy: the output can not be reformatted with WithAnnotations`},
+		{"surrogate policy", []Option{WithSurrogatePolicy(2)}, `invalid option: WithSurrogatePolicy: unknown policy 2`},
+		{"invalid utf-8 policy", []Option{WithInvalidUTF8Policy(3)}, `invalid option: WithInvalidUTF8Policy: unknown policy 3`},
+		{"encoding", []Option{WithEncoding("latin-1")}, `invalid option: WithEncoding: unknown encoding "latin-1"`},
+		{"nul policy", []Option{WithNULPolicy(3)}, `invalid option: WithNULPolicy: unknown policy 3`},
+		{"string newline policy", []Option{WithStringNewlinePolicy(3)}, `invalid option: WithStringNewlinePolicy: unknown policy 3`},
+		{"max string scan", []Option{WithMaxStringScan(-1)}, `invalid option: WithMaxStringScan: negative limit -1`},
+		{"max depth", []Option{WithMaxDepth(-1)}, `invalid option: WithMaxDepth: negative limit -1`},
+		{"max input size", []Option{WithMaxInputSize(-1)}, `invalid option: WithMaxInputSize: negative limit -1`},
+		{"max output size", []Option{WithMaxOutputSize(-1)}, `invalid option: WithMaxOutputSize: negative limit -1`},
+		{"rule", []Option{WithoutRules("comments")}, `invalid option: WithRules or WithoutRules: unknown rule "comments"`},
+		{"aggressiveness", []Option{WithAggressiveness(3)}, `invalid option: WithAggressiveness: unknown aggressiveness 3`},
+		{"function call policy", []Option{WithFunctionCallPolicy(3)}, `invalid option: WithFunctionCallPolicy: unknown policy 3`},
+		{"function name", []Option{WithFunctionNames("my.callback")}, `invalid option: WithFunctionNames: "my.callback" is not a function name`},
+		{"merge strategy", []Option{WithMergeStrategy(5)}, `invalid option: WithMergeStrategy: unknown strategy 5`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repairer, err := NewRepairer(test.opts...)
+			assert.Nil(t, repairer)
+			require.ErrorIs(t, err, ErrInvalidOption)
+			assert.EqualError(t, err, test.expected)
+
+			_, err = JSONRepair(`[1]`, test.opts...)
+			assert.ErrorIs(t, err, ErrInvalidOption)
+		})
+	}
+}
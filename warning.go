@@ -0,0 +1,59 @@
+package jsonrepair
+
+// WithWarnings fills warnings with the decisions of the repair which may have lost data, so they
+// are not hidden by a successful repair: a removed ellipsis or a closed bracket at the end of the
+// text mean that the data may be truncated, a removed function call or an invalid number that
+// data was dropped, and a repeated key that a decoder will keep only one of its values. The Fix
+// of a warning is the repair which was made, or empty when nothing was repaired.
+func WithWarnings(warnings *[]Issue) Option {
+	return func(o *options) {
+		o.warnings = warnings
+	}
+}
+
+// Kinds of warnings.
+const (
+	warningTruncated    = "truncated data"
+	warningDropped      = "dropped data"
+	warningDuplicateKey = "duplicate key"
+)
+
+// lossyRepairs are the kinds of warnings of the repairs which may have lost data, by message.
+var lossyRepairs = map[string]string{
+	"removed ellipsis":                   warningTruncated,
+	"replaced ellipsis with placeholder": warningTruncated,
+	"added missing closing bracket":      warningTruncated,
+	"added missing closing brace":        warningTruncated,
+	"completed truncated number":         warningTruncated,
+	"truncated invalid number":           warningDropped,
+	"removed function call":              warningDropped,
+	"removed key without value":          warningDropped,
+	"removed empty array slot":           warningDropped,
+	"replaced lone surrogate":            warningDropped,
+	"removed lone surrogate":             warningDropped,
+}
+
+// warn records a warning of the given kind at the given position, with the repair which was made.
+func warn(position int, kind, fix string, opts *options) {
+	if opts.warnings != nil {
+		*opts.warnings = append(*opts.warnings, Issue{Kind: kind, Fix: fix, Position: opts.offset + position})
+	}
+}
+
+// warnLossyRepair records a warning for the repair with the given message when it may have lost data.
+func warnLossyRepair(position int, message string, opts *options) {
+	if kind, ok := lossyRepairs[message]; ok && opts.warnings != nil {
+		warn(position, kind, issueFix(message), opts)
+	}
+}
+
+// locateIssues sets the byte offsets, lines and columns of the issues from their positions in
+// the text, which are in the text after the byte order mark.
+func locateIssues(issues []Issue, text string) {
+	text, _ = splitByteOrderMark(text)
+	input := []rune(text)
+	for k := range issues {
+		issues[k].ByteOffset = byteOffset(input, issues[k].Position)
+		issues[k].Line, issues[k].Column = lineColumn(input, issues[k].Position)
+	}
+}
@@ -0,0 +1,43 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWarnings tests reporting the repairs which may have lost data.
+func TestWarnings(t *testing.T) {
+	var warnings []Issue
+	result, err := JSONRepair("{\"a\": [1, 2, ...],\n \"b\": f(), \"a\": 2, \"c\": [3", WithWarnings(&warnings))
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\": [1, 2 ],\n \"b\": null, \"a\": 2, \"c\": [3]}", result)
+	assert.Equal(t, []Issue{
+		{Kind: "truncated data", Fix: "remove ellipsis", Position: 13, ByteOffset: 13, Line: 1, Column: 14},
+		{Kind: "dropped data", Fix: "remove function call", Position: 25, ByteOffset: 25, Line: 2, Column: 7},
+		{Kind: "duplicate key", Position: 30, ByteOffset: 30, Line: 2, Column: 12},
+		{Kind: "truncated data", Fix: "add missing closing bracket", Position: 45, ByteOffset: 45, Line: 2, Column: 27},
+		{Kind: "truncated data", Fix: "add missing closing brace", Position: 45, ByteOffset: 45, Line: 2, Column: 27},
+	}, warnings)
+
+	// valid JSON is checked for repeated keys
+	_, err = JSONRepair(`{"a": 1, "b": {"a": 2}, "a": 3}`, WithWarnings(&warnings))
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{{Kind: "duplicate key", Position: 24, ByteOffset: 24, Line: 1, Column: 25}}, warnings)
+
+	_, err = JSONRepair(`{"a": 1}`, WithWarnings(&warnings))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+// TestWarningsDroppedData tests warning about the repairs which drop data.
+func TestWarningsDroppedData(t *testing.T) {
+	var warnings []Issue
+	_, err := JSONRepair(`[1,,2, "\ud800"]`, WithDropEmptyArraySlots(), WithWarnings(&warnings))
+	require.NoError(t, err)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, "dropped data", warnings[0].Kind)
+	assert.Equal(t, "remove empty array slot", warnings[0].Fix)
+	assert.Equal(t, "replace lone surrogate", warnings[1].Fix)
+}
@@ -0,0 +1,460 @@
+package jsonrepair
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// RepairYAMLFlow locates the flow-style mappings and sequences ({...} and [...])
+// in a YAML document and returns the repaired JSON for each of them, in order of
+// appearance. A leading front-matter block is skipped, so a JSON document placed
+// after front-matter is returned as the first result.
+func RepairYAMLFlow(text string) ([]string, error) {
+	_, body := splitFrontMatter(text)
+	runes := []rune(body)
+
+	var results []string
+	i := 0
+	for i < len(runes) {
+		lineStart := i
+		lineEnd := i
+		for lineEnd < len(runes) && runes[lineEnd] != codeNewline {
+			lineEnd++
+		}
+
+		start := flowCollectionStart(runes, lineStart, lineEnd)
+		if start == -1 {
+			i = lineEnd + 1
+			continue
+		}
+
+		end := flowCollectionEnd(runes, start, lineIndent(runes, lineStart, lineEnd))
+		repaired, err := JSONRepair(string(runes[start:end]))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, strings.TrimSpace(repaired))
+		i = end
+	}
+
+	return results, nil
+}
+
+// splitFrontMatter splits a leading "---" delimited front-matter block from the text.
+// It returns an empty front-matter when the text does not start with a complete block.
+func splitFrontMatter(text string) (string, string) {
+	rest, ok := strings.CutPrefix(text, "---")
+	if !ok {
+		return "", text
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, "\n") && !strings.HasPrefix(rest, "\r\n") {
+		return "", text
+	}
+
+	offset := len(text) - len(rest)
+	for offset < len(text) {
+		lineEnd := strings.IndexByte(text[offset:], '\n')
+		var line string
+		if lineEnd == -1 {
+			line = text[offset:]
+			lineEnd = len(text)
+		} else {
+			line = text[offset : offset+lineEnd]
+			lineEnd = offset + lineEnd + 1
+		}
+
+		trimmed := strings.TrimRight(line, " \t\r")
+		if offset > len(text)-len(rest) && (trimmed == "---" || trimmed == "...") {
+			return text[:lineEnd], text[lineEnd:]
+		}
+		offset = lineEnd
+	}
+
+	return "", text
+}
+
+// flowCollectionStart returns the index of a flow collection starting on the line, or -1.
+// A flow collection starts either at the beginning of the line content (after optional
+// sequence markers) or right after a mapping key.
+func flowCollectionStart(text []rune, start, end int) int {
+	i := start
+	for i < end && isWhitespace(text[i]) {
+		i++
+	}
+	for i+1 < end && text[i] == codeMinus && isWhitespace(text[i+1]) {
+		i += 2
+		for i < end && isWhitespace(text[i]) {
+			i++
+		}
+	}
+	if i >= end || text[i] == codeHash {
+		return -1
+	}
+	if text[i] == codeOpeningBrace || text[i] == codeOpeningBracket {
+		return i
+	}
+
+	// look for a value after a "key:" prefix
+	for j := i; j < end; j++ {
+		if text[j] == codeColon && (j+1 == end || isWhitespace(text[j+1])) {
+			k := j + 1
+			for k < end && isWhitespace(text[k]) {
+				k++
+			}
+			if k < end && (text[k] == codeOpeningBrace || text[k] == codeOpeningBracket) {
+				return k
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// flowCollectionEnd returns the index right after the flow collection starting at start.
+// When the collection is not closed, it ends before the first next line which is not
+// indented deeper than the line where the collection started.
+func flowCollectionEnd(text []rune, start, indent int) int {
+	depth := 0
+	var quote rune
+	for i := start; i < len(text); i++ {
+		char := text[i]
+		switch {
+		case quote != 0 && char != codeNewline:
+			if char == codeBackslash && quote == codeDoubleQuote {
+				i++
+			} else if char == quote {
+				quote = 0
+			}
+		case char == codeDoubleQuote || char == codeQuote:
+			quote = char
+		case char == codeOpeningBrace || char == codeOpeningBracket:
+			depth++
+		case char == codeClosingBrace || char == codeClosingBracket:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case char == codeNewline:
+			quote = 0 // strings do not span lines, the quote was a stray one
+			lineEnd := i + 1
+			for lineEnd < len(text) && text[lineEnd] != codeNewline {
+				lineEnd++
+			}
+			content := strings.TrimSpace(string(text[i+1 : lineEnd]))
+			if content == "" {
+				return i
+			}
+			if lineIndent(text, i+1, lineEnd) <= indent &&
+				!strings.HasPrefix(content, "}") && !strings.HasPrefix(content, "]") {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
+
+// lineIndent counts the leading whitespace characters of a line.
+func lineIndent(text []rune, start, end int) int {
+	i := start
+	for i < end && (text[i] == codeSpace || text[i] == codeTab) {
+		i++
+	}
+	return i - start
+}
+
+// RepairYAMLBlock converts a simple YAML document written in block style, like an answer in
+// YAML where JSON was requested, to JSON. Mappings of key: value lines and sequences of - item
+// lines are nested by indentation. Comments, document markers and literal (|) and folded (>)
+// block scalars are handled, and deeper indented lines continue a plain value. Values are typed
+// like the values of RepairQueryString, next to ~ for null: port: 8080 becomes a number, and
+// a flow value like [a, b] is repaired. Anchors, tags and multiple documents are not supported.
+func RepairYAMLBlock(text string, opts ...Option) (string, error) {
+	o := newOptions(opts...)
+	o.checkInputSize(len(text))
+	if o.err != nil {
+		return "", o.err
+	}
+	output, err := repairYAMLBlock(text, o)
+	if err != nil {
+		return "", locate(err, []rune(text))
+	}
+	return o.limitOutput(output)
+}
+
+// repairYAMLBlock converts a YAML block document with the given options.
+func repairYAMLBlock(text string, opts *options) (string, error) {
+	p := &yamlParser{lines: strings.Split(text, "\n"), opts: opts}
+	p.skipBlank()
+	if p.done() {
+		return "", newError(ErrUnexpectedEnd, "", utf8.RuneCountInString(text))
+	}
+	if content := p.content(); !isYAMLItem(content) && !isYAMLPair(content) {
+		return "", newError(ErrObjectKeyExpected, "", p.position())
+	}
+	return encodeValue(p.parseNode(0))
+}
+
+// atYAMLBlock checks if the text is a YAML block mapping or sequence: its first line which is
+// not blank, a comment or a document marker is a key: value pair or a - item.
+func atYAMLBlock(text string) bool {
+	p := &yamlParser{lines: strings.Split(text, "\n")}
+	p.skipBlank()
+	if p.done() {
+		return false
+	}
+	content := p.content()
+	return (isYAMLItem(content) || isYAMLPair(content)) && !strings.HasPrefix(content, "{") && !strings.HasPrefix(content, "[")
+}
+
+// yamlParser parses a YAML block document line by line.
+type yamlParser struct {
+	lines []string
+	// line is the index of the current line.
+	line int
+	// override replaces the current line after a sequence marker, like "name: a" of "- name: a",
+	// with the indentation in overrideIndent.
+	override       *string
+	overrideIndent int
+	opts           *options
+}
+
+// done checks if all lines have been parsed.
+func (p *yamlParser) done() bool {
+	return p.line >= len(p.lines)
+}
+
+// content returns the current line without indentation, line break and comment.
+func (p *yamlParser) content() string {
+	if p.override != nil {
+		return *p.override
+	}
+	return stripYAMLComment(strings.TrimSpace(p.lines[p.line]))
+}
+
+// indent returns the indentation of the current line.
+func (p *yamlParser) indent() int {
+	if p.override != nil {
+		return p.overrideIndent
+	}
+	line := p.lines[p.line]
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// position returns the position of the current line in runes.
+func (p *yamlParser) position() int {
+	position := 0
+	for _, line := range p.lines[:p.line] {
+		position += utf8.RuneCountInString(line) + 1
+	}
+	return position + p.indent()
+}
+
+// next moves to the next line which is not blank, a comment or a document marker.
+func (p *yamlParser) next() {
+	p.override = nil
+	p.line++
+	p.skipBlank()
+}
+
+// skipBlank skips blank lines, comments and document markers.
+func (p *yamlParser) skipBlank() {
+	for p.override == nil && !p.done() {
+		content := p.content()
+		if content != "" && content != "---" && content != "..." {
+			return
+		}
+		p.line++
+	}
+}
+
+// parseNode parses the mapping or sequence starting at the current line, when it is indented
+// at least minIndent, or returns nil.
+func (p *yamlParser) parseNode(minIndent int) any {
+	if p.done() || p.indent() < minIndent {
+		return nil
+	}
+	if isYAMLItem(p.content()) {
+		return p.parseSequence(minIndent)
+	}
+	return p.parseMapping(minIndent)
+}
+
+// parseSequence parses the - items which are indented at least minIndent. The value of an item
+// is indented deeper than its marker.
+func (p *yamlParser) parseSequence(minIndent int) []any {
+	items := []any{}
+	for !p.done() && p.indent() >= minIndent && isYAMLItem(p.content()) {
+		indent, content := p.indent(), p.content()
+		rest := strings.TrimLeft(content[1:], " \t")
+		itemIndent := indent + len(content) - len(rest)
+		switch {
+		case rest == "":
+			p.next()
+			items = append(items, p.parseNode(indent+1))
+		case isYAMLBlockScalar(rest):
+			items = append(items, p.parseBlockScalar(rest, indent))
+		case isYAMLItem(rest) || isYAMLPair(rest):
+			// a nested sequence or mapping starting on the line of the marker
+			p.override, p.overrideIndent = &rest, itemIndent
+			items = append(items, p.parseNode(itemIndent))
+		default:
+			p.next()
+			items = append(items, p.parseScalar(rest, indent))
+		}
+	}
+	return items
+}
+
+// parseMapping parses the key: value pairs which are indented at least minIndent. The value
+// of a key is indented deeper than the key, or is a sequence indented like the key. A key
+// which is indented less than the previous one, but still at least minIndent, repairs
+// inconsistent indentation.
+func (p *yamlParser) parseMapping(minIndent int) *orderedObject {
+	obj := &orderedObject{values: map[string]any{}}
+	for !p.done() && p.indent() >= minIndent && !isYAMLItem(p.content()) {
+		indent := p.indent()
+		key, value, _ := splitYAMLPair(p.content())
+		if isYAMLBlockScalar(value) {
+			obj.set(key, p.parseBlockScalar(value, indent))
+			continue
+		}
+		p.next()
+		switch {
+		case value == "" && !p.done() && p.indent() > indent:
+			obj.set(key, p.parseNode(indent+1))
+		case value == "" && !p.done() && p.indent() == indent && isYAMLItem(p.content()):
+			obj.set(key, p.parseSequence(indent))
+		case value == "":
+			obj.set(key, nil)
+		default:
+			obj.set(key, p.parseScalar(value, indent))
+		}
+	}
+	return obj
+}
+
+// parseScalar types a plain, quoted or flow value, which is continued by the next lines which
+// are indented deeper than the given indentation.
+func (p *yamlParser) parseScalar(value string, indent int) any {
+	for !p.done() && p.indent() > indent && p.override == nil {
+		value += " " + p.content()
+		p.next()
+	}
+	switch {
+	case value == "~":
+		return nil
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	switch lower := strings.ToLower(value); lower {
+	case "true", "false", "null":
+		return inferValue(lower, p.opts)
+	}
+	return inferValue(value, p.opts)
+}
+
+// parseBlockScalar parses the lines of a literal (|) or folded (>) block scalar, whose indicator
+// ends the current line, which follow and are indented deeper than the given indentation. A final line break is kept unless the indicator
+// is followed by a minus sign, like |-.
+func (p *yamlParser) parseBlockScalar(indicator string, indent int) string {
+	var lines []string
+	blockIndent := -1
+	p.override = nil
+	for p.line++; p.line < len(p.lines); p.line++ {
+		line := strings.TrimRight(p.lines[p.line], " \t\r")
+		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if line != "" && lineIndent <= indent {
+			break
+		}
+		if line != "" && blockIndent == -1 {
+			blockIndent = lineIndent
+		}
+		lines = append(lines, line[min(max(blockIndent, 0), len(line)):])
+	}
+	p.skipBlank()
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	separator := "\n"
+	if indicator[0] == '>' {
+		separator = " "
+	}
+	value := strings.Join(lines, separator)
+	if value != "" && !strings.HasSuffix(indicator, "-") {
+		value += "\n"
+	}
+	return value
+}
+
+// isYAMLItem checks if the content of a line is a sequence item, like "- a" or "-".
+func isYAMLItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ") || strings.HasPrefix(content, "-\t")
+}
+
+// isYAMLPair checks if the content of a line is a key: value pair.
+func isYAMLPair(content string) bool {
+	_, _, ok := splitYAMLPair(content)
+	return ok
+}
+
+// isYAMLBlockScalar checks if a value is the indicator of a block scalar, like | or >-.
+func isYAMLBlockScalar(value string) bool {
+	switch value {
+	case "|", "|-", "|+", ">", ">-", ">+":
+		return true
+	}
+	return false
+}
+
+// splitYAMLPair splits the content of a line at the colon separating the key from the value,
+// which is followed by whitespace or ends the line, outside of a quoted key. A line without
+// such a colon is a key without value.
+func splitYAMLPair(content string) (string, string, bool) {
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		char := content[i]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case i == 0 && (char == '"' || char == '\''):
+			quote = char
+		case char == ':' && (i+1 == len(content) || content[i+1] == ' ' || content[i+1] == '\t'):
+			return unquoteYAMLKey(strings.TrimSpace(content[:i])), strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return unquoteYAMLKey(content), "", false
+}
+
+// unquoteYAMLKey removes the quotes of a quoted key.
+func unquoteYAMLKey(key string) string {
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// stripYAMLComment removes a comment starting with a # at the start or after whitespace,
+// outside of quotes.
+func stripYAMLComment(content string) string {
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		char := content[i]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '"' || char == '\'':
+			if i == 0 || content[i-1] == ' ' || content[i-1] == ':' || content[i-1] == '[' || content[i-1] == ',' {
+				quote = char
+			}
+		case char == '#' && (i == 0 || content[i-1] == ' ' || content[i-1] == '\t'):
+			return strings.TrimSpace(content[:i])
+		}
+	}
+	return content
+}
@@ -0,0 +1,130 @@
+package jsonrepair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairYAMLFlow(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "json after front-matter",
+			text:     "---\ntitle: Example\n---\n{name: 'John', age: 30}\n",
+			expected: []string{`{"name": "John", "age": 30}`},
+		},
+		{
+			name:     "flow mapping and sequence values",
+			text:     "name: test\nlabels: {app: web, tier: 'frontend'}\nports: [80, 443,]\n",
+			expected: []string{`{"app": "web", "tier": "frontend"}`, `[80, 443]`},
+		},
+		{
+			name:     "sequence items",
+			text:     "items:\n  - {id: 1}\n  - {id: 2\n  - plain\n",
+			expected: []string{`{"id": 1}`, `{"id": 2}`},
+		},
+		{
+			name:     "json in block scalar",
+			text:     "config: |\n  {\n    \"debug\": true,\n    \"level\": 3,\n  }\nother: value\n",
+			expected: []string{"{\n    \"debug\": true,\n    \"level\": 3\n  }"},
+		},
+		{
+			name:     "brackets inside strings",
+			text:     "a: {\"text\": \"}{\", b: '[x]'}\n",
+			expected: []string{`{"text": "}{", "b": "[x]"}`},
+		},
+		{
+			name:     "no flow collections",
+			text:     "---\na: 1\n---\nb: 2\n# {comment}\n",
+			expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := RepairYAMLFlow(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		text        string
+		frontMatter string
+		body        string
+	}{
+		{"---\na: 1\n---\n{}", "---\na: 1\n---\n", "{}"},
+		{"---\r\na: 1\r\n---\r\n{}", "---\r\na: 1\r\n---\r\n", "{}"},
+		{"---\na: 1\n...\n[]", "---\na: 1\n...\n", "[]"},
+		{"---\na: 1\n", "", "---\na: 1\n"},
+		{"{\"a\": 1}", "", "{\"a\": 1}"},
+		{"---text\n---\n{}", "", "---text\n---\n{}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			frontMatter, body := splitFrontMatter(test.text)
+			assert.Equal(t, test.frontMatter, frontMatter)
+			assert.Equal(t, test.body, body)
+		})
+	}
+}
+
+func TestRepairYAMLBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"mapping", "name: John\nage: 30\nactive: true", `{"name":"John","age":30,"active":true}`},
+		{"nested mapping", "address:\n  city: Paris # capital\n  zip: '75001'\nnext: 1", `{"address":{"city":"Paris","zip":"75001"},"next":1}`},
+		{"sequence", "tags:\n  - a\n  - 2", `{"tags":["a",2]}`},
+		{"sequence indented like its key", "tags:\n- a\n- b\nnext: 1", `{"tags":["a","b"],"next":1}`},
+		{"top-level sequence", "---\n# people\n- name: a\n  age: 3\n- name: b\n", `[{"name":"a","age":3},{"name":"b"}]`},
+		{"nested sequence", "- - 1\n  - 2\n- -\n    - 3", `[[1,2],[[3]]]`},
+		{"null values", "empty:\ntilde: ~\nnull: Null", `{"empty":null,"tilde":null,"null":null}`},
+		{"quotes", "\"quoted key\": 'it''s'\nb: \"a\\tb\"\nzip: '007'", `{"quoted key":"it's","b":"a\tb","zip":"007"}`},
+		{"plain values", "url: http://x.com:80/a#b\ntime: 12:30\ntext: a # comment", `{"url":"http://x.com:80/a#b","time":"12:30","text":"a"}`},
+		{"continued value", "description: a long\n  text continues\nnext: 1", `{"description":"a long text continues","next":1}`},
+		{"flow values", "flow: [1, 2, {a: b}]\nbroken: {a: 1", `{"flow":[1,2,{"a":"b"}],"broken":{"a":1}}`},
+		{"literal block", "text: |\n  line 1\n\n  # not a comment\n  line 3\nafter: 1", `{"text":"line 1\n\n# not a comment\nline 3\n","after":1}`},
+		{"folded block", "text: >-\n  a\n  b\nlist:\n- |\n  x", `{"text":"a b","list":["x\n"]}`},
+		{"inconsistent indentation", "a:\n    b: 1\n  c: 2\nd: 3", `{"a":{"b":1,"c":2},"d":3}`},
+		{"crlf", "a: 1\r\nb:\r\n  - x\r\n", `{"a":1,"b":["x"]}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := RepairYAMLBlock(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+
+	_, err := RepairYAMLBlock("just a sentence")
+	require.ErrorIs(t, err, ErrObjectKeyExpected)
+	_, err = RepairYAMLBlock("# only a comment\n")
+	require.ErrorIs(t, err, ErrUnexpectedEnd)
+}
+
+func TestYAMLBlocks(t *testing.T) {
+	var report Report
+	result, err := JSONRepair("# answer\nname: John\ntags:\n  - a", WithYAMLBlocks(), WithReport(&report))
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"John","tags":["a"]}`, result)
+	assert.Equal(t, []Repair{{Position: 0, Kind: RepairConversion, Message: "converted yaml block"}}, report.Repairs)
+
+	result, err = JSONRepair("[1, 2]", WithYAMLBlocks())
+	require.NoError(t, err)
+	assert.Equal(t, `[1, 2]`, result)
+
+	_, err = JSONRepair("name: John")
+	require.ErrorIs(t, err, ErrUnexpectedCharacter)
+}